@@ -0,0 +1,420 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"sms-parser/internal/actual"
+	"sms-parser/internal/firefly"
+	"sms-parser/internal/lunchmoney"
+	"sms-parser/internal/models"
+	"sms-parser/internal/sheets"
+	"sms-parser/internal/ynab"
+
+	"github.com/spf13/cobra"
+)
+
+// ynabTokenEnv, fireflyTokenEnv, actualKeyEnv, and lunchMoneyTokenEnv name
+// the environment variables holding the access tokens, so they never appear
+// in shell history or config files; actualPasswordEnv holds the password of
+// an end-to-end encrypted Actual budget
+const (
+	ynabTokenEnv       = "YNAB_TOKEN"
+	fireflyTokenEnv    = "FIREFLY_TOKEN"
+	actualKeyEnv       = "ACTUAL_API_KEY"
+	actualPasswordEnv  = "ACTUAL_BUDGET_PASSWORD"
+	lunchMoneyTokenEnv = "LUNCHMONEY_TOKEN"
+)
+
+// googleCredentialsEnv names the environment variable holding the path of
+// the Google service account key, as for Google's own client libraries
+const googleCredentialsEnv = "GOOGLE_APPLICATION_CREDENTIALS"
+
+var (
+	ynabConfigFile    string
+	fireflyConfigFile string
+	actualConfigFile  string
+	sheetsConfigFile  string
+	lunchMoneyConfig  string
+)
+
+// pushParseFlags are the root command's flags that control parsing; the push
+// subcommands accept them too (added in root.go's init, once they exist), so
+// backups are parsed exactly as for files
+var pushParseFlags = []string{
+	"sender", "from", "to", "timezone", "rename", "note-mode", "redact",
+	"detect-transfers", "dedup-window", "verbose", "skip-installments",
+	"cib-debit", "cib-account", "cib-cards", "include-pending",
+	"keep-instapay-duplicates", "skip-unknown-senders", "report-unparsed",
+	"allow-empty", "dry-run", "categories", "exclude-category", "aliases",
+	"rules", "plugin",
+}
+
+// pushCmd groups the subcommands that send transactions to online services
+var pushCmd = &cobra.Command{
+	Use:   "push",
+	Short: "Send parsed transactions to a budgeting service",
+}
+
+// pushYNABCmd creates the parsed transactions in a YNAB budget
+var pushYNABCmd = &cobra.Command{
+	Use:   "ynab [xml-file | -]...",
+	Short: "Create the parsed transactions in a YNAB budget",
+	Long: `Parses SMS backups and creates their transactions in YNAB through its API.
+The budget and the YNAB account of each group come from --config; the personal
+access token is read from the ` + ynabTokenEnv + ` environment variable.
+Each transaction's import ID is derived from its stable ID, so running again on
+overlapping backups does not create duplicates.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runPushYNAB,
+}
+
+// pushFireflyCmd creates the parsed transactions in a Firefly III instance
+var pushFireflyCmd = &cobra.Command{
+	Use:   "firefly [xml-file | -]...",
+	Short: "Create the parsed transactions in Firefly III",
+	Long: `Parses SMS backups and creates their transactions in Firefly III through its API.
+The instance URL, the asset account of each group, and optional category names
+come from --config; the personal access token is read from the ` + fireflyTokenEnv + `
+environment variable. Each transaction carries its stable ID as external_id, and
+transactions whose external_id already exists are skipped, so running again on
+overlapping backups does not create duplicates.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runPushFirefly,
+}
+
+// pushActualCmd imports the parsed transactions into an Actual Budget
+var pushActualCmd = &cobra.Command{
+	Use:   "actual [xml-file | -]...",
+	Short: "Import the parsed transactions into Actual Budget",
+	Long: `Parses SMS backups and imports their transactions into an Actual Budget server.
+Actual has no HTTP API of its own, so this goes through an actual-http-api server
+(https://github.com/jhonderson/actual-http-api) connected to it. The server URL,
+the budget's Sync ID, and the Actual account of each group come from --config; the
+API key is read from the ` + actualKeyEnv + ` environment variable, and the password
+of an end-to-end encrypted budget from ` + actualPasswordEnv + `.
+Each transaction's imported ID is derived from its stable ID, so running again on
+overlapping backups does not create duplicates.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runPushActual,
+}
+
+// pushSheetsCmd appends the parsed transactions to a Google Sheet
+var pushSheetsCmd = &cobra.Command{
+	Use:   "sheets [xml-file | -]...",
+	Short: "Append the parsed transactions to a Google Sheet",
+	Long: `Parses SMS backups and appends their transactions to a Google Sheet, one tab
+per group, through the Google Sheets API. The spreadsheet and optional tab names
+come from --config; the service account key file is read from the path in the
+` + googleCredentialsEnv + ` environment variable, and the spreadsheet must be
+shared with the service account's email address. Each row carries the
+transaction's stable ID, and transactions whose ID is already in their tab are
+skipped, so running again on overlapping backups does not create duplicates.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runPushSheets,
+}
+
+// pushLunchMoneyCmd creates the parsed transactions in Lunch Money
+var pushLunchMoneyCmd = &cobra.Command{
+	Use:   "lunchmoney [xml-file | -]...",
+	Short: "Create the parsed transactions in Lunch Money",
+	Long: `Parses SMS backups and creates their transactions in Lunch Money through its API.
+The asset of each group and optional category names come from --config; the access
+token is read from the ` + lunchMoneyTokenEnv + ` environment variable. Categories are
+matched to Lunch Money categories by name. Each transaction carries its stable ID as
+external_id, and transactions whose external_id the asset already has are skipped,
+so running again on overlapping backups does not create duplicates.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runPushLunchMoney,
+}
+
+// pushSubcommands are the push subcommands, which share pushParseFlags
+var pushSubcommands = []*cobra.Command{pushYNABCmd, pushFireflyCmd, pushActualCmd, pushSheetsCmd, pushLunchMoneyCmd}
+
+func init() {
+	pushYNABCmd.Flags().StringVar(&ynabConfigFile, "config", "", "YAML/JSON file with the YNAB budget_id and the account ID for each group (required)")
+	pushFireflyCmd.Flags().StringVar(&fireflyConfigFile, "config", "", "YAML/JSON file with the Firefly III url, the asset account ID for each group, and optional category names (required)")
+	pushActualCmd.Flags().StringVar(&actualConfigFile, "config", "", "YAML/JSON file with the actual-http-api url, the budget_sync_id, and the Actual account ID for each group (required)")
+	pushSheetsCmd.Flags().StringVar(&sheetsConfigFile, "config", "", "YAML/JSON file with the spreadsheet_id and optional tab names for groups (required)")
+	pushLunchMoneyCmd.Flags().StringVar(&lunchMoneyConfig, "config", "", "YAML/JSON file with the Lunch Money asset ID for each group and optional category names (required)")
+
+	pushCmd.AddCommand(pushSubcommands...)
+	RootCmd.AddCommand(pushCmd)
+}
+
+func runPushYNAB(cmd *cobra.Command, args []string) error {
+	if ynabConfigFile == "" {
+		return fmt.Errorf("--config is required")
+	}
+	cfg, err := ynab.LoadConfig(ynabConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to load YNAB config: %w", err)
+	}
+
+	token := os.Getenv(ynabTokenEnv)
+	if token == "" && !dryRun {
+		return fmt.Errorf("%s is not set; create a personal access token in YNAB under Account Settings > Developer Settings", ynabTokenEnv)
+	}
+
+	accounts, _, err := parseBackups(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	var transactions []ynab.Transaction
+	for _, account := range mappedAccounts(accounts, cfg.Accounts, ynabConfigFile) {
+		for _, tx := range account.Transactions {
+			transactions = append(transactions, ynab.NewTransaction(tx, cfg.Accounts[account.Name]))
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("Would push %d transactions to YNAB budget %s.\n", len(transactions), cfg.BudgetID)
+		return nil
+	}
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	result, err := ynab.New(token, "").CreateTransactions(cmd.Context(), cfg.BudgetID, transactions)
+	if err != nil {
+		return fmt.Errorf("failed to push transactions: %w", err)
+	}
+
+	fmt.Printf("Pushed %d transactions to YNAB (%d already imported).\n", result.Created, result.Duplicates)
+	return nil
+}
+
+func runPushFirefly(cmd *cobra.Command, args []string) error {
+	if fireflyConfigFile == "" {
+		return fmt.Errorf("--config is required")
+	}
+	cfg, err := firefly.LoadConfig(fireflyConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to load Firefly III config: %w", err)
+	}
+
+	token := os.Getenv(fireflyTokenEnv)
+	if token == "" && !dryRun {
+		return fmt.Errorf("%s is not set; create a personal access token in Firefly III under Options > Profile > OAuth", fireflyTokenEnv)
+	}
+
+	accounts, _, err := parseBackups(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	var splits []firefly.Split
+	for _, account := range mappedAccounts(accounts, cfg.Accounts, fireflyConfigFile) {
+		for _, tx := range account.Transactions {
+			splits = append(splits, firefly.NewSplit(tx, cfg.Accounts[account.Name], cfg.Category(tx.Category)))
+		}
+	}
+
+	if dryRun {
+		fmt.Printf("Would push %d transactions to Firefly III at %s.\n", len(splits), cfg.URL)
+		return nil
+	}
+
+	result, err := firefly.New(token, cfg.URL).CreateTransactions(cmd.Context(), splits)
+	if result.Created > 0 || result.Duplicates > 0 {
+		fmt.Printf("Pushed %d transactions to Firefly III (%d already imported).\n", result.Created, result.Duplicates)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to push transactions: %w", err)
+	}
+	return nil
+}
+
+func runPushActual(cmd *cobra.Command, args []string) error {
+	if actualConfigFile == "" {
+		return fmt.Errorf("--config is required")
+	}
+	cfg, err := actual.LoadConfig(actualConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to load Actual config: %w", err)
+	}
+
+	apiKey := os.Getenv(actualKeyEnv)
+	if apiKey == "" && !dryRun {
+		return fmt.Errorf("%s is not set; use the API key the actual-http-api server was started with", actualKeyEnv)
+	}
+
+	accounts, _, err := parseBackups(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	mapped := mappedAccounts(accounts, cfg.Accounts, actualConfigFile)
+	if dryRun {
+		fmt.Printf("Would push %d transactions to Actual budget %s.\n", countTransactions(mapped), cfg.BudgetSyncID)
+		return nil
+	}
+
+	// Actual imports into one account at a time
+	client := actual.New(apiKey, os.Getenv(actualPasswordEnv), cfg.URL)
+	var total actual.Result
+	for _, account := range mapped {
+		if len(account.Transactions) == 0 {
+			continue
+		}
+		transactions := make([]actual.Transaction, 0, len(account.Transactions))
+		for _, tx := range account.Transactions {
+			transactions = append(transactions, actual.NewTransaction(tx))
+		}
+
+		result, err := client.ImportTransactions(cmd.Context(), cfg.BudgetSyncID, cfg.Accounts[account.Name], transactions)
+		if err != nil {
+			return fmt.Errorf("failed to push %s: %w", account.Name, err)
+		}
+		total.Created += result.Created
+		total.Duplicates += result.Duplicates
+	}
+
+	fmt.Printf("Pushed %d transactions to Actual (%d already imported).\n", total.Created, total.Duplicates)
+	return nil
+}
+
+func runPushSheets(cmd *cobra.Command, args []string) error {
+	if sheetsConfigFile == "" {
+		return fmt.Errorf("--config is required")
+	}
+	cfg, err := sheets.LoadConfig(sheetsConfigFile)
+	if err != nil {
+		return fmt.Errorf("failed to load Google Sheets config: %w", err)
+	}
+
+	var account *sheets.ServiceAccount
+	if !dryRun {
+		keyFile := os.Getenv(googleCredentialsEnv)
+		if keyFile == "" {
+			return fmt.Errorf("%s is not set; point it to the JSON key of a Google Cloud service account with the Sheets API enabled", googleCredentialsEnv)
+		}
+		if account, err = sheets.LoadServiceAccount(keyFile); err != nil {
+			return fmt.Errorf("failed to load service account: %w", err)
+		}
+	}
+
+	accounts, _, err := parseBackups(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		for _, acc := range accounts {
+			if len(acc.Transactions) > 0 {
+				fmt.Printf("Would append up to %d transactions to tab %q.\n", len(acc.Transactions), cfg.Tab(acc.Name))
+			}
+		}
+		return nil
+	}
+
+	client := sheets.New(account, "")
+	var total sheets.Result
+	for _, acc := range accounts {
+		if len(acc.Transactions) == 0 {
+			continue
+		}
+		result, err := client.AppendTransactions(cmd.Context(), cfg.SpreadsheetID, cfg.Tab(acc.Name), acc.Transactions)
+		total.Created += result.Created
+		total.Duplicates += result.Duplicates
+		if err != nil {
+			return fmt.Errorf("failed to push %s: %w", acc.Name, err)
+		}
+	}
+
+	fmt.Printf("Appended %d transactions to Google Sheets (%d already present).\n", total.Created, total.Duplicates)
+	return nil
+}
+
+func runPushLunchMoney(cmd *cobra.Command, args []string) error {
+	if lunchMoneyConfig == "" {
+		return fmt.Errorf("--config is required")
+	}
+	cfg, err := lunchmoney.LoadConfig(lunchMoneyConfig)
+	if err != nil {
+		return fmt.Errorf("failed to load Lunch Money config: %w", err)
+	}
+
+	token := os.Getenv(lunchMoneyTokenEnv)
+	if token == "" && !dryRun {
+		return fmt.Errorf("%s is not set; create an access token in Lunch Money under Settings > Developers", lunchMoneyTokenEnv)
+	}
+
+	accounts, _, err := parseBackups(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	// mappedAccounts works on string IDs
+	assets := make(map[string]string, len(cfg.Accounts))
+	for group, assetID := range cfg.Accounts {
+		assets[group] = fmt.Sprint(assetID)
+	}
+	mapped := mappedAccounts(accounts, assets, lunchMoneyConfig)
+
+	if dryRun {
+		fmt.Printf("Would push %d transactions to Lunch Money.\n", countTransactions(mapped))
+		return nil
+	}
+
+	client := lunchmoney.New(token, "")
+	categoryIDs, err := client.Categories(cmd.Context())
+	if err != nil {
+		return fmt.Errorf("failed to push transactions: %w", err)
+	}
+
+	// Transactions in categories Lunch Money doesn't have stay uncategorized
+	var missing []string
+	var total lunchmoney.Result
+	for _, account := range mapped {
+		assetID := cfg.Accounts[account.Name]
+		var transactions []lunchmoney.Transaction
+		for _, tx := range account.Transactions {
+			name := cfg.Category(tx.Category)
+			categoryID, ok := categoryIDs[name]
+			if !ok && name != "" && !slices.Contains(missing, name) {
+				missing = append(missing, name)
+			}
+			transactions = append(transactions, lunchmoney.NewTransaction(tx, assetID, categoryID))
+		}
+
+		result, err := client.CreateTransactions(cmd.Context(), assetID, transactions)
+		total.Created += result.Created
+		total.Duplicates += result.Duplicates
+		if err != nil {
+			return fmt.Errorf("failed to push %s: %w", account.Name, err)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		fmt.Fprintf(os.Stderr, "Warning: left uncategorized, no Lunch Money category named: %s\n", strings.Join(missing, ", "))
+	}
+	fmt.Printf("Pushed %d transactions to Lunch Money (%d already imported).\n", total.Created, total.Duplicates)
+	return nil
+}
+
+// mappedAccounts returns the accounts whose group has an entry in ids. Groups
+// without one are reported rather than failing the push.
+func mappedAccounts(accounts []models.Account, ids map[string]string, configFile string) []models.Account {
+	var mapped []models.Account
+	var unmapped []string
+	for _, account := range accounts {
+		switch {
+		case ids[account.Name] != "":
+			mapped = append(mapped, account)
+		case len(account.Transactions) > 0:
+			unmapped = append(unmapped, account.Name)
+		}
+	}
+
+	if len(unmapped) > 0 {
+		sort.Strings(unmapped)
+		fmt.Fprintf(os.Stderr, "Warning: skipping groups without an account in %s: %s\n", configFile, strings.Join(unmapped, ", "))
+	}
+	return mapped
+}
@@ -1,28 +1,106 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 
+	"sms-parser/internal/categorizer"
+	"sms-parser/internal/models"
 	"sms-parser/internal/parser"
+	"sms-parser/internal/report"
+	"sms-parser/internal/rules"
+	"sms-parser/internal/utils"
 	"sms-parser/internal/writer"
+	"sms-parser/pkg/bankparser"
 
 	"github.com/spf13/cobra"
 )
 
 var (
-	outputDir  string
-	senderName string
-	startDate  string
+	outputDir   string
+	senderNames []string
+	startDate   string
+	endDate     string
+	rulesFile   string
+	pluginPaths []string
+	format      string
+	delimiter   string
+	ofxVersion  string
+	noBOM       bool
+	categories  string
+	aliasesFile string
+	fieldsFile  string
+
+	excludeCategories []string
+	timezone          string
+	skipInstallments  bool
+	dbPath            string
+	cibDebitCards     []string
+	cibAccounts       []string
+	cibCardsFile      string
+	includePending    bool
+	keepInstaPay      bool
+	skipUnknown       bool
+	combinedFile      string
+	combinedOnly      bool
+	merged            bool
+	toStdout          bool
+	workbookFile      string
+	journalFile       string
+	accountsFile      string
+	appendMode        bool
+	noteMode          string
+	redact            bool
+	sortSpec          string
+	allowEmpty        bool
+	renames           []string
+
+	unparsedReport string
+	reportFile     string
+
+	detectTransfers bool
+	summary         bool
+	dryRun          bool
+	dedupWindow     time.Duration
+	verbose         bool
 )
 
+// postgresDSNEnv names the environment variable holding the PostgreSQL
+// connection string for --format postgres, so the password never appears in
+// shell history
+const postgresDSNEnv = "DATABASE_URL"
+
+// stdoutName is the --output value that streams to standard output
+const stdoutName = "-"
+
+// status receives progress messages such as "Parsed ..."; it is standard
+// output, switched to standard error by newWriters when the transactions
+// themselves stream to standard output (-o - or --stdout)
+var status io.Writer = os.Stdout
+
+// mergedName is the default name of the --merged file, without extension
+const mergedName = "transactions"
+
+// ErrNoTransactions is returned when a run finds no transactions and
+// --allow-empty is not set; main exits with code 2 for it
+var ErrNoTransactions = errors.New("no transactions found; check the backup file and the --sender, --from, and --to filters (use --allow-empty to accept empty output)")
+
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
-	Use:   "sms-parser [xml-file]",
+	Use:   "sms-parser [xml-file | -]...",
 	Short: "Parse SMS backup and extract bank transactions",
-	Long:  `A CLI tool to parse SMS backup XML files and extract bank transactions into CSV files.`,
-	Args:  cobra.ExactArgs(1),
-	RunE:  run,
+	Long: `A CLI tool to parse SMS backup XML files and extract bank transactions into CSV files.
+Several backups can be given at once; their transactions are merged and deduplicated.
+Use "-" as the file name to read the backup from standard input.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: run,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -31,31 +109,630 @@ func Execute() error {
 }
 
 func init() {
-	RootCmd.Flags().StringVarP(&outputDir, "output", "o", ".", "Output directory for CSV files (created if not exists)")
-	RootCmd.Flags().StringVarP(&senderName, "sender", "s", "", "Filter by sender name (e.g., 'CIB', 'Banque Misr')")
+	RootCmd.Flags().StringVarP(&outputDir, "output", "o", ".", "Output directory for CSV files (created if not exists), or - to write to standard output")
+	RootCmd.Flags().BoolVar(&toStdout, "stdout", false, "Write all accounts to standard output as one stream with an account column, like --combined (same as -o -)")
+	RootCmd.Flags().StringSliceVarP(&senderNames, "sender", "s", nil, "Filter by sender name (e.g., 'CIB', 'Banque Misr'); repeat or comma-separate for several")
 	RootCmd.Flags().StringVarP(&startDate, "from", "f", "", "Filter messages from this date onwards (format: YYYY-MM-DD)")
+	RootCmd.Flags().StringVarP(&endDate, "to", "t", "", "Filter messages up to and including this date (format: YYYY-MM-DD)")
+	RootCmd.Flags().StringVar(&timezone, "timezone", "", "IANA timezone for transaction dates and --from/--to (e.g. 'Africa/Cairo'; default: local)")
+	RootCmd.Flags().StringVar(&format, "format", "csv", "Output format: csv, json, jsonl, wallet, ynab, homebank, mmex, qif, ofx, pdf, xlsx, beancount, ledger, gnucash, sqlite, or postgres (connection string from $"+postgresDSNEnv+")")
+	RootCmd.Flags().StringArrayVar(&renames, "rename", nil, "Rename an account in the output, as old=new (e.g. 'CIB_Current_Debit=Checking'; repeatable)")
+	RootCmd.Flags().StringVar(&combinedFile, "combined", "", "Also write all accounts into this one file, sorted by date with an account column (placed in --output unless absolute)")
+	RootCmd.Flags().BoolVar(&combinedOnly, "combined-only", false, "Write only the --combined file, not the per-account files")
+	RootCmd.Flags().BoolVar(&merged, "merged", false, "Write only one file with every account and an account column, instead of per-account files (shorthand for --combined transactions.<format extension> --combined-only)")
+	RootCmd.Flags().StringVar(&workbookFile, "workbook", "transactions.xlsx", "Excel workbook for --format xlsx, with one sheet per account (placed in --output unless absolute)")
+	RootCmd.Flags().StringVar(&journalFile, "journal", "", "Journal file for --format beancount, ledger, or gnucash (default: transactions.<format extension>; placed in --output unless absolute)")
+	RootCmd.Flags().StringVar(&accountsFile, "accounts", "", "YAML/JSON file mapping groups and categories to journal accounts (e.g. 'groups: {CIB_Current_Debit: Assets:CIB:Current}')")
+	RootCmd.Flags().StringVar(&dbPath, "db", "", "SQLite database file for --format sqlite (created if not exists)")
+	RootCmd.Flags().StringVar(&sortSpec, "sort", "date", "Order of transactions in each file: date, amount, payee, or category, optionally followed by :asc or :desc (e.g. 'amount:desc')")
+	RootCmd.Flags().StringVar(&ofxVersion, "ofx-version", "2", "OFX version for --format ofx: 2 (OFX 2.2 XML) or 1 (OFX 1.02 SGML, for older apps)")
+	RootCmd.Flags().StringVar(&fieldsFile, "fields", "", `YAML/JSON file mapping CSV column names to Go templates (e.g. 'note: "{{.Category}} | {{.Payee}}"'); standard columns are replaced, others added`)
+	RootCmd.Flags().StringVar(&delimiter, "delimiter", ";", "CSV and wallet field delimiter (a single character)")
+	RootCmd.Flags().BoolVar(&appendMode, "append", false, "Merge new transactions into existing CSV files instead of overwriting them (rows already in a file are kept as they are)")
+	RootCmd.Flags().StringVar(&noteMode, "note-mode", parser.NoteFull, "How much of the SMS to keep in the note: full, category-only (category tag without the message), or none")
+	RootCmd.Flags().BoolVar(&redact, "redact", false, "Mask card, account, phone, and reference numbers in notes (amounts and dates are kept)")
+	RootCmd.Flags().BoolVar(&noBOM, "no-bom", false, "Do not write a UTF-8 byte order mark at the start of CSV and wallet files")
+	RootCmd.Flags().BoolVar(&detectTransfers, "detect-transfers", false, "Mark matching outgoing/incoming pairs between your own accounts as transfers")
+	RootCmd.Flags().DurationVar(&dedupWindow, "dedup-window", time.Minute, "Treat identical messages from the same sender within this window as duplicates (0 = exact timestamp only)")
+	RootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Log to stderr which parser and pattern handled each message, and the result")
+	RootCmd.Flags().BoolVar(&skipInstallments, "skip-installments", false, "Leave credit card installment conversions out of the output (the original charge is already counted)")
+	RootCmd.Flags().StringSliceVar(&cibDebitCards, "cib-debit", nil, "Last 4 digits of your CIB debit card(s); other CIB cards are treated as credit cards (comma-separated or repeated)")
+	RootCmd.Flags().StringSliceVar(&cibAccounts, "cib-account", nil, "Last 4 digits of your CIB current account(s) (comma-separated or repeated)")
+	RootCmd.Flags().StringVar(&cibCardsFile, "cib-cards", "", "YAML/JSON file mapping the last 4 digits of CIB cards and accounts to a name and type (debit, credit, or current)")
+	RootCmd.Flags().BoolVar(&includePending, "include-pending", false, "Keep pending card authorizations unless a settled charge of the same amount follows within 72 hours")
+	RootCmd.Flags().BoolVar(&keepInstaPay, "keep-instapay-duplicates", false, "Keep InstaPay confirmations even when the bank also reported the same transfer within 15 minutes")
+	RootCmd.Flags().BoolVar(&skipUnknown, "skip-unknown-senders", false, "Ignore messages from senders without a parser instead of extracting transaction-like ones into Unknown_<sender> files")
+	RootCmd.Flags().StringVar(&unparsedReport, "report-unparsed", "", "Write messages from known senders that produced no transaction to this CSV file (placed in --output unless absolute)")
+	RootCmd.Flags().BoolVar(&allowEmpty, "allow-empty", false, "Exit successfully even when no transactions are found (otherwise the exit code is 2)")
+	RootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Parse and print what would be written without creating any files")
+	RootCmd.Flags().StringVar(&reportFile, "report", "", "Also write a monthly report with totals per category and account and the largest expenses, as HTML (.html) or Markdown (.md) by extension (placed in --output unless absolute)")
+	RootCmd.Flags().BoolVar(&summary, "summary", false, "Print income/expense totals per account and category after writing")
+	RootCmd.Flags().StringVarP(&categories, "categories", "c", "", "YAML/JSON file mapping categories to keywords; these are checked before the built-in keywords (set 'replace: true' to drop the built-ins)")
+	RootCmd.Flags().StringArrayVar(&excludeCategories, "exclude-category", nil, "Leave transactions in this category out of the output (repeatable)")
+	RootCmd.Flags().StringVar(&aliasesFile, "aliases", "", "YAML/JSON file mapping payee substrings to canonical names (e.g. 'UBER: Uber'), matched case-insensitively")
+	RootCmd.Flags().StringVarP(&rulesFile, "rules", "r", "", "YAML/JSON file with declarative bank rules (overrides built-in parsers per sender)")
+	RootCmd.Flags().StringArrayVar(&pluginPaths, "plugin", nil, "Go plugin (.so) exporting a bankparser.BankParser named Parser (repeatable; overrides built-in parsers per sender)")
+
+	// Share the parsing flags with the push subcommands
+	for _, name := range pushParseFlags {
+		for _, sub := range pushSubcommands {
+			sub.Flags().AddFlag(RootCmd.Flags().Lookup(name))
+		}
+	}
 }
 
 func run(cmd *cobra.Command, args []string) error {
-	filePath := args[0]
+	// Set up the writer first so an invalid format fails before any work is done
+	w, fileSink, err := newWriters(cmd)
+	if err != nil {
+		return err
+	}
+	if reportFile != "" {
+		if _, err := report.DocumentFormat(reportFile); err != nil {
+			return err
+		}
+	}
 
-	// Create output directory if it doesn't exist
-	if err := os.MkdirAll(outputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	accounts, unparsed, err := parseBackups(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		printDryRun(w, fileSink, accounts, unparsed)
+		return nil
+	}
+
+	if streaming() {
+		if err := w.WriteStream(os.Stdout, accounts); err != nil {
+			return fmt.Errorf("failed to write transactions: %w", err)
+		}
+	} else if err := writeOutputs(w, fileSink, accounts); err != nil {
+		return err
+	}
+
+	if reportFile != "" {
+		// The output directory is not created for the database formats
+		path := reportPath(reportFile)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return fmt.Errorf("failed to create report directory: %w", err)
+		}
+		if err := report.WriteDocument(path, accounts); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		fmt.Fprintf(status, "Created report %s.\n", path)
+	}
+
+	if summary {
+		fmt.Fprint(status, "\n"+report.Summarize(accounts, unparsed).String())
+	}
+
+	return nil
+}
+
+// writeOutputs writes the transactions to the files or database of
+// --format, and the --combined file
+func writeOutputs(w *writer.Writer, fileSink writer.FileSink, accounts []models.Account) error {
+	sink, closeSink, err := openSink(w, fileSink)
+	if err != nil {
+		return err
+	}
+	defer closeSink()
+
+	if !combinedOnly {
+		if err := sink.Write(accounts); err != nil {
+			return fmt.Errorf("failed to write transactions: %w", err)
+		}
+	}
+
+	if combinedFile != "" {
+		if err := w.WriteCombined(combinedFile, accounts); err != nil {
+			return fmt.Errorf("failed to write combined file: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// streaming reports whether the transactions go to standard output
+func streaming() bool {
+	return toStdout || outputDir == stdoutName
+}
+
+// reportPath returns a report file such as --report or --report-unparsed,
+// placed in the output directory unless absolute (or in the working directory
+// when streaming)
+func reportPath(name string) string {
+	if filepath.IsAbs(name) || streaming() {
+		return name
+	}
+	return filepath.Join(outputDir, name)
+}
+
+// newWriters sets up the destination for --format: the per-account file
+// writer, or a single-file sink; both are nil for --format sqlite and
+// postgres, whose database is only opened once there is something to write
+func newWriters(cmd *cobra.Command) (*writer.Writer, writer.FileSink, error) {
+	if streaming() {
+		if err := checkStreaming(); err != nil {
+			return nil, nil, err
+		}
+		// Keep progress messages out of the piped output
+		status = os.Stderr
+	}
+	// Errors about the combined file name the flag that asked for it
+	combinedFlag := "--combined"
+	// --merged names its file once the format's extension is known, below
+	if merged {
+		combinedFlag = "--merged"
+		combinedOnly = true
+		if combinedFile == "" {
+			combinedFile = mergedName
+		}
+	}
+	if combinedOnly && combinedFile == "" {
+		return nil, nil, fmt.Errorf("--combined-only requires --combined")
+	}
+	if fieldsFile != "" && format != "" && format != "csv" {
+		return nil, nil, fmt.Errorf("--fields is only supported with --format csv")
+	}
+	switch format {
+	case "xlsx", "beancount", "ledger", "gnucash":
+		if combinedFile != "" {
+			return nil, nil, fmt.Errorf("%s is not supported with --format %s (its single file already holds every account)", combinedFlag, format)
+		}
+		if appendMode {
+			return nil, nil, fmt.Errorf("--append is not supported with --format %s", format)
+		}
+		if format != "xlsx" && cmd.Flags().Changed("sort") {
+			return nil, nil, fmt.Errorf("--sort is not supported with --format %s (journals are always in date order)", format)
+		}
+		fileSink, err := newFileSink()
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, fileSink, nil
+	case "sqlite", "postgres":
+		if format == "sqlite" && dbPath == "" {
+			return nil, nil, fmt.Errorf("--format sqlite requires --db")
+		}
+		if combinedFile != "" {
+			return nil, nil, fmt.Errorf("%s is not supported with --format %s", combinedFlag, format)
+		}
+		if cmd.Flags().Changed("sort") {
+			return nil, nil, fmt.Errorf("--sort is not supported with --format %s", format)
+		}
+		return nil, nil, nil
+	default:
+		if slices.Contains([]string{"wallet", "ynab", "homebank", "mmex"}, format) && combinedFile != "" {
+			return nil, nil, fmt.Errorf("%s is not supported with --format %s (it is imported one account at a time)", combinedFlag, format)
+		}
+		if format == "pdf" && combinedFile != "" {
+			return nil, nil, fmt.Errorf("%s is not supported with --format pdf (statements are per account)", combinedFlag)
+		}
+		var fields writer.Fields
+		if fieldsFile != "" {
+			var err error
+			if fields, err = writer.LoadFields(fieldsFile); err != nil {
+				return nil, nil, fmt.Errorf("failed to load fields: %w", err)
+			}
+		}
+		loc, err := loadLocation()
+		if err != nil {
+			return nil, nil, err
+		}
+		w, err := writer.New(outputDir, writer.Options{
+			Format:    format,
+			Delimiter: delimiter,
+			// A BOM would end up in the first field of piped output
+			NoBOM:      noBOM || streaming(),
+			Append:     appendMode,
+			Sort:       sortSpec,
+			OFXVersion: ofxVersion,
+			Fields:     fields,
+			Location:   loc,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if combinedFile == mergedName {
+			combinedFile += "." + w.Extension()
+		}
+		return w, nil, nil
+	}
+}
+
+// checkStreaming rejects flags that need files when writing to standard
+// output
+func checkStreaming() error {
+	switch {
+	case slices.Contains([]string{"xlsx", "beancount", "ledger", "gnucash", "sqlite", "postgres"}, format):
+		return fmt.Errorf("--format %s cannot be written to standard output", format)
+	case slices.Contains([]string{"wallet", "ynab", "homebank", "mmex", "pdf"}, format):
+		return fmt.Errorf("--format %s has one file per account and cannot be written to standard output", format)
+	case combinedFile != "" || merged:
+		return fmt.Errorf("--combined and --merged cannot be used when writing to standard output, which already gets every account")
+	case appendMode:
+		return fmt.Errorf("--append cannot be used when writing to standard output")
+	}
+	return nil
+}
+
+// parseBackups loads the parsing configuration from the flags, parses the
+// backups in args, and applies --rename. It reports unparsed messages,
+// returning them for --summary, and returns ErrNoTransactions for an empty
+// run unless --allow-empty is set.
+func parseBackups(cmd *cobra.Command, args []string) ([]models.Account, []models.UnparsedMessage, error) {
+	// Load declarative bank rules if provided
+	var ruleSet *rules.RuleSet
+	var err error
+	if rulesFile != "" {
+		ruleSet, err = rules.Load(rulesFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load rules: %w", err)
+		}
+	}
+
+	// Load third-party bank parsers
+	var plugins []bankparser.BankParser
+	for _, path := range pluginPaths {
+		plugin, err := bankparser.Open(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load plugin: %w", err)
+		}
+		plugins = append(plugins, plugin)
+	}
+
+	// Load user categorization keywords if provided
+	cat, err := categorizer.New(categories)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	loc, err := loadLocation()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Reject misspelled categories instead of silently excluding nothing;
+	// categories defined in --categories count too
+	known := cat.Categories()
+	for _, category := range excludeCategories {
+		if !slices.Contains(known, category) {
+			return nil, nil, fmt.Errorf("unknown category %q for --exclude-category (valid: %q)", category, known)
+		}
+	}
+
+	if !slices.Contains(parser.NoteModes, noteMode) {
+		return nil, nil, fmt.Errorf("unknown --note-mode %q (valid: %q)", noteMode, parser.NoteModes)
+	}
+
+	renameMap, err := parseRenames(renames)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Load payee aliases if provided
+	var aliases map[string]string
+	if aliasesFile != "" {
+		if err := utils.DecodeFile(aliasesFile, &aliases); err != nil {
+			return nil, nil, fmt.Errorf("failed to load aliases: %w", err)
+		}
+	}
+
+	cibCards, err := loadCIBCards()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Log parsing decisions to stderr in verbose mode
+	var logger *log.Logger
+	if verbose {
+		logger = log.New(os.Stderr, "", 0)
 	}
 
-	// Parse the SMS backup file
-	p := parser.New()
-	transactions, err := p.ParseFile(filePath, senderName, startDate)
+	// Parse the SMS backup files
+	p := parser.New(parser.Config{
+		Rules:                  ruleSet,
+		Plugins:                plugins,
+		Categorizer:            cat,
+		DetectTransfers:        detectTransfers,
+		Aliases:                aliases,
+		ExcludeCategories:      excludeCategories,
+		Location:               loc,
+		SkipInstallments:       skipInstallments,
+		CIBCards:               cibCards,
+		IncludePending:         includePending,
+		KeepInstaPayDuplicates: keepInstaPay,
+		SkipUnknownSenders:     skipUnknown,
+		NoteMode:               noteMode,
+		Redact:                 redact,
+		DedupWindow:            dedupWindow,
+		Logger:                 logger,
+	})
+	accounts, unparsed, err := parseFiles(p, args)
 	if err != nil {
-		return fmt.Errorf("failed to parse SMS backup: %w", err)
+		return nil, nil, err
+	}
+
+	if len(renameMap) > 0 {
+		accounts, err = models.RenameAccounts(accounts, renameMap)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid --rename: %w", err)
+		}
+	}
+
+	// Amounts that fail to parse would otherwise vanish silently
+	invalidAmounts := 0
+	for _, msg := range unparsed {
+		if errors.Is(msg.Err, parser.ErrInvalidAmount) {
+			invalidAmounts++
+		}
+	}
+	if invalidAmounts > 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %d messages had an amount that could not be parsed (list them with --report-unparsed).\n", invalidAmounts)
+	}
+
+	// Written before the output, so it is there even for an empty run
+	if unparsedReport != "" {
+		path := reportPath(unparsedReport)
+		if dryRun {
+			fmt.Printf("Would write %s (%d unparsed messages).\n", path, len(unparsed))
+		} else if err := writeUnparsedReport(path, unparsed, loc); err != nil {
+			return nil, nil, err
+		}
 	}
 
-	// Write transactions to CSV files
-	w := writer.New(outputDir)
-	if err := w.Write(transactions); err != nil {
-		return fmt.Errorf("failed to write transactions: %w", err)
+	// An empty run usually means the wrong file or filters; with exit code 2
+	// it is not mistaken for success
+	if !allowEmpty && countTransactions(accounts) == 0 {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true
+		return nil, nil, ErrNoTransactions
 	}
 
+	return accounts, unparsed, nil
+}
+
+// writeUnparsedReport writes the --report-unparsed file, creating its
+// directory
+func writeUnparsedReport(path string, unparsed []models.UnparsedMessage, loc *time.Location) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create report directory: %w", err)
+	}
+	if err := writer.WriteUnparsed(path, unparsed, loc); err != nil {
+		return fmt.Errorf("failed to write unparsed report: %w", err)
+	}
+	fmt.Fprintf(status, "Reported %d unparsed/skipped messages in %s.\n", len(unparsed), path)
 	return nil
 }
+
+// loadLocation resolves --timezone, the timezone dates are shown in
+func loadLocation() (*time.Location, error) {
+	if timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q: %w", timezone, err)
+	}
+	return loc, nil
+}
+
+// countTransactions returns the number of transactions across accounts
+func countTransactions(accounts []models.Account) int {
+	total := 0
+	for _, account := range accounts {
+		total += len(account.Transactions)
+	}
+	return total
+}
+
+// newFileSink creates the single-file destination of --format xlsx and the
+// journal formats, placing relative paths in the output directory
+func newFileSink() (writer.FileSink, error) {
+	outputPath := func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(outputDir, path)
+	}
+
+	if format == "xlsx" {
+		workbook, err := writer.NewXLSXWriter(outputPath(workbookFile), sortSpec)
+		if err != nil {
+			return nil, err
+		}
+		return workbook, nil
+	}
+
+	var accounts writer.AccountMap
+	if accountsFile != "" {
+		var err error
+		if accounts, err = writer.LoadAccountMap(accountsFile); err != nil {
+			return nil, err
+		}
+	}
+
+	path := journalFile
+	if path == "" {
+		var err error
+		if path, err = writer.DefaultJournalName(format); err != nil {
+			return nil, err
+		}
+	}
+	journal, err := writer.NewJournalWriter(outputPath(path), format, accounts)
+	if err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// openSink returns the destination for the parsed transactions: the single
+// file for --format xlsx and the journal formats, the SQLite or PostgreSQL
+// database for --format sqlite and postgres, otherwise the per-account file
+// writer
+func openSink(w *writer.Writer, fileSink writer.FileSink) (writer.Sink, func() error, error) {
+	switch format {
+	case "sqlite":
+		db, err := writer.OpenSQLite(dbPath)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, db.Close, nil
+	case "postgres":
+		db, err := writer.OpenPostgres(os.Getenv(postgresDSNEnv))
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, db.Close, nil
+	}
+
+	// Create output directory if it doesn't exist
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if fileSink != nil {
+		return fileSink, func() error { return nil }, nil
+	}
+	return w, func() error { return nil }, nil
+}
+
+// parseFiles parses each backup in turn and groups their transactions into
+// accounts together. The parser's deduplication spans all files, so
+// overlapping backups are safe, and pending authorizations, InstaPay
+// duplicates, and transfers are matched across files.
+func parseFiles(p *parser.Parser, filePaths []string) ([]models.Account, []models.UnparsedMessage, error) {
+	opts := parser.Options{
+		Senders:   trimAll(senderNames),
+		StartDate: startDate,
+		EndDate:   endDate,
+	}
+
+	var transactions []models.Transaction
+	var unparsed []models.UnparsedMessage
+
+	for _, filePath := range filePaths {
+		var fileTransactions []models.Transaction
+		var fileUnparsed []models.UnparsedMessage
+		var err error
+
+		// Read standard input for "-"
+		if filePath == "-" {
+			fileTransactions, fileUnparsed, err = p.Collect(os.Stdin, opts)
+		} else {
+			fileTransactions, fileUnparsed, err = p.CollectFile(filePath, opts)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse SMS backup %s: %w", filePath, err)
+		}
+
+		transactions = append(transactions, fileTransactions...)
+		unparsed = append(unparsed, fileUnparsed...)
+
+		if len(filePaths) > 1 {
+			fmt.Fprintf(status, "Parsed %s: %d transactions.\n", filePath, len(fileTransactions))
+		}
+	}
+
+	accounts := p.Finish(transactions)
+
+	if len(filePaths) > 1 {
+		fmt.Fprintf(status, "Parsed %d files: %d transactions in total.\n", len(filePaths), countTransactions(accounts))
+	}
+
+	return accounts, unparsed, nil
+}
+
+// parseRenames parses --rename values ("old=new") into a map. New names
+// become file names, so they may not contain path separators.
+func parseRenames(values []string) (map[string]string, error) {
+	renameMap := make(map[string]string, len(values))
+	for _, value := range values {
+		oldName, newName, ok := strings.Cut(value, "=")
+		oldName, newName = strings.TrimSpace(oldName), strings.TrimSpace(newName)
+		if !ok || oldName == "" || newName == "" {
+			return nil, fmt.Errorf("invalid --rename %q: expected old=new", value)
+		}
+		if strings.ContainsAny(newName, `/\`) {
+			return nil, fmt.Errorf("invalid --rename %q: the new name may not contain path separators", value)
+		}
+		renameMap[oldName] = newName
+	}
+	return renameMap, nil
+}
+
+// loadCIBCards combines the --cib-cards file with the --cib-debit and
+// --cib-account digits; digits given both ways must agree on the type
+func loadCIBCards() (map[string]parser.CIBCard, error) {
+	cards := make(map[string]parser.CIBCard)
+	if cibCardsFile != "" {
+		loaded, err := parser.LoadCIBCards(cibCardsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load CIB cards: %w", err)
+		}
+		cards = loaded
+	}
+
+	add := func(flag string, digits []string, cardType string) error {
+		for _, d := range trimAll(digits) {
+			if card, ok := cards[d]; ok && card.Type != cardType {
+				return fmt.Errorf("--%s %s conflicts with type %q in %s", flag, d, card.Type, cibCardsFile)
+			}
+			if _, ok := cards[d]; !ok {
+				cards[d] = parser.CIBCard{Type: cardType}
+			}
+		}
+		return nil
+	}
+	if err := add("cib-debit", cibDebitCards, parser.CIBDebit); err != nil {
+		return nil, err
+	}
+	if err := add("cib-account", cibAccounts, parser.CIBCurrent); err != nil {
+		return nil, err
+	}
+	return cards, nil
+}
+
+// trimAll trims whitespace around each value, so "CIB, Banque Misr" works
+func trimAll(values []string) []string {
+	trimmed := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			trimmed = append(trimmed, value)
+		}
+	}
+	return trimmed
+}
+
+// printDryRun lists the files (or database rows) a real run would write,
+// followed by the summary
+func printDryRun(w *writer.Writer, fileSink writer.FileSink, accounts []models.Account, unparsed []models.UnparsedMessage) {
+	total := 0
+	for _, account := range accounts {
+		count := len(account.Transactions)
+		total += count
+		switch {
+		case count == 0, combinedOnly:
+		case streaming():
+			fmt.Printf("Would write %d %s transactions to standard output.\n", count, account.Name)
+		case fileSink != nil:
+			fmt.Printf("Would add %d %s transactions to %s.\n", count, account.Name, fileSink.Path())
+		case format == "postgres":
+			fmt.Printf("Would upsert %d %s transactions into PostgreSQL.\n", count, account.Name)
+		case w == nil:
+			fmt.Printf("Would upsert %d %s transactions into %s.\n", count, account.Name, dbPath)
+		default:
+			fmt.Printf("Would create %s with %d transactions.\n", w.Filename(account.Name), count)
+		}
+	}
+
+	if combinedFile != "" && total > 0 {
+		fmt.Printf("Would create %s with %d transactions.\n", w.CombinedFilename(combinedFile), total)
+	}
+	if reportFile != "" {
+		fmt.Printf("Would create report %s.\n", reportPath(reportFile))
+	}
+
+	fmt.Print("\n" + report.Summarize(accounts, unparsed).String())
+}
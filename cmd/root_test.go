@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/parser"
+)
+
+func TestDryRunWritesNoFiles(t *testing.T) {
+	dir := t.TempDir()
+	backup := filepath.Join(dir, "backup.xml")
+	output := filepath.Join(dir, "out")
+
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<smses>
+  <sms address="CIB" date="1704103200000" body="Your credit card ending with 4321 was charged for EGP 250.00 at CARREFOUR on 01/01 at 12:00" />
+  <sms address="CIB" date="1704106800000" body="Your CIB statement is now available" />
+</smses>
+`
+	if err := os.WriteFile(backup, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	RootCmd.SetArgs([]string{backup, "-o", output, "--dry-run", "--report-unparsed", "unparsed.csv", "--report", "report.md"})
+	if err := RootCmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("--dry-run created %s (stat error = %v)", output, err)
+	}
+}
+
+func TestParseFilesMatchesAcrossFiles(t *testing.T) {
+	dir := t.TempDir()
+	sent := filepath.Join(dir, "phone.xml")
+	received := filepath.Join(dir, "old-phone.xml")
+
+	// The transfer leaves NBE in one backup and arrives at QNB in the other,
+	// whose InstaPay confirmation repeats the NBE side
+	files := map[string]string{
+		sent: `<?xml version="1.0" encoding="UTF-8"?>
+<smses>
+  <sms address="NBE" date="1736935200000" body="تم تحويل مبلغ 1,000 جنيه من حسابكم رقم ****5678 الى أحمد محمد في 15/01" />
+</smses>
+`,
+		received: `<?xml version="1.0" encoding="UTF-8"?>
+<smses>
+  <sms address="InstaPay" date="1736935230000" body="You have sent EGP 1,000.00 to Ahmed Mohamed via InstaPay on 15/01. Ref 771204" />
+  <sms address="QNB" date="1736935320000" body="Your account xx4321 was credited with EGP 1,000.00 on 15/01." />
+</smses>
+`,
+	}
+	for path, data := range files {
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	p := parser.New(parser.Config{DetectTransfers: true})
+	accounts, _, err := parseFiles(p, []string{sent, received})
+	if err != nil {
+		t.Fatalf("parseFiles() error = %v", err)
+	}
+
+	var names []string
+	for _, account := range accounts {
+		names = append(names, account.Name)
+		for _, tx := range account.Transactions {
+			if tx.Type != models.TypeTransfer {
+				t.Errorf("%s: %s %.2f has type %s, want %s", account.Name, tx.Payee, tx.Amount, tx.Type, models.TypeTransfer)
+			}
+		}
+	}
+	if len(names) != 2 || names[0] != "NBE_Card_5678" || names[1] != "QNB_Card_4321" {
+		t.Errorf("accounts = %v, want the NBE and QNB accounts without the InstaPay confirmation", names)
+	}
+}
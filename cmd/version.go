@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+)
+
+// Build information, set at build time with
+// -ldflags "-X sms-parser/cmd.Version=... -X sms-parser/cmd.Commit=... -X sms-parser/cmd.Date=..."
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// versionCmd prints the build information
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version, git commit, and build date",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		fmt.Println("sms-parser " + versionString())
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(versionCmd)
+	RootCmd.Version = versionString()
+	RootCmd.SetVersionTemplate("sms-parser {{.Version}}\n")
+}
+
+// versionString formats the build information, falling back to the VCS
+// details Go embeds in the binary when no ldflags were given
+func versionString() string {
+	commit, date := Commit, Date
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && commit == "":
+				commit = setting.Value
+			case setting.Key == "vcs.time" && date == "":
+				date = setting.Value
+			}
+		}
+	}
+
+	if commit == "" {
+		commit = "unknown"
+	}
+	if date == "" {
+		date = "unknown"
+	}
+	return fmt.Sprintf("%s (commit %s, built %s)", Version, commit, date)
+}
@@ -0,0 +1,130 @@
+package actual
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"sms-parser/internal/models"
+)
+
+// importIDPrefix starts every imported ID, keeping them apart from the IDs
+// of Actual's own file and bank imports
+const importIDPrefix = "SMS:"
+
+// Transaction is a transaction in the shape of Actual's importTransactions
+type Transaction struct {
+	Date string `json:"date"`
+	// Amount is in cents: 100 for 1.00
+	Amount     int64  `json:"amount"`
+	PayeeName  string `json:"payee_name,omitempty"`
+	Notes      string `json:"notes,omitempty"`
+	ImportedID string `json:"imported_id"`
+	Cleared    bool   `json:"cleared"`
+}
+
+// Result summarizes a push: Actual matches transactions whose imported ID it
+// already has in the account instead of adding them again
+type Result struct {
+	Created    int
+	Duplicates int
+}
+
+// Client calls an actual-http-api server, which wraps Actual's Node.js API
+// in a REST API, with its API key
+type Client struct {
+	apiKey         string
+	budgetPassword string
+	baseURL        string
+	httpClient     *http.Client
+}
+
+// New creates a Client for the actual-http-api server at baseURL. The budget
+// password is only needed for end-to-end encrypted budgets.
+func New(apiKey, budgetPassword, baseURL string) *Client {
+	return &Client{
+		apiKey:         apiKey,
+		budgetPassword: budgetPassword,
+		baseURL:        strings.TrimSuffix(baseURL, "/"),
+		httpClient:     &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// NewTransaction converts a parsed transaction. The imported ID is derived
+// from the transaction's stable ID, so pushing the same messages again
+// creates no duplicates.
+func NewTransaction(tx models.Transaction) Transaction {
+	return Transaction{
+		Date:       tx.Timestamp.Format("2006-01-02"),
+		Amount:     int64(math.Round(tx.Amount * 100)),
+		PayeeName:  tx.Payee,
+		Notes:      strings.Join(strings.Fields(tx.Note), " "),
+		ImportedID: importIDPrefix + tx.ID,
+		Cleared:    true,
+	}
+}
+
+// ImportTransactions imports transactions into one account of the budget in
+// one request; Actual runs its rules on them and reconciles them against the
+// transactions already in the account
+func (c *Client) ImportTransactions(ctx context.Context, budgetSyncID, accountID string, transactions []Transaction) (Result, error) {
+	body, err := json.Marshal(map[string]any{"transactions": transactions})
+	if err != nil {
+		return Result{}, fmt.Errorf("error encoding transactions: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/budgets/%s/accounts/%s/transactions/import",
+		c.baseURL, url.PathEscape(budgetSyncID), url.PathEscape(accountID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+	if c.budgetPassword != "" {
+		req.Header.Set("budget-encryption-password", c.budgetPassword)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("error calling actual-http-api: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, fmt.Errorf("error reading actual-http-api response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return Result{}, fmt.Errorf("Actual rejected the transactions (%s): %s", resp.Status, apiErr.Error)
+		}
+		return Result{}, fmt.Errorf("Actual rejected the transactions: %s", resp.Status)
+	}
+
+	var imported struct {
+		Data struct {
+			Added   []string `json:"added"`
+			Updated []string `json:"updated"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &imported); err != nil {
+		return Result{}, fmt.Errorf("error decoding actual-http-api response: %w", err)
+	}
+
+	return Result{
+		Created:    len(imported.Data.Added),
+		Duplicates: len(transactions) - len(imported.Data.Added),
+	}, nil
+}
@@ -0,0 +1,46 @@
+package actual
+
+import (
+	"fmt"
+	"strings"
+
+	"sms-parser/internal/utils"
+)
+
+// Config names the actual-http-api server, the budget transactions are
+// pushed to, and the Actual account each group goes into
+type Config struct {
+	// URL is the address of the actual-http-api server, e.g. http://localhost:5007
+	URL string `json:"url" yaml:"url"`
+	// BudgetSyncID is the budget's Sync ID, shown in Actual under Settings >
+	// Show advanced settings
+	BudgetSyncID string `json:"budget_sync_id" yaml:"budget_sync_id"`
+	// Accounts maps a group (e.g. "CIB_Current_Debit") to an Actual account
+	// ID; groups without an entry are not pushed
+	Accounts map[string]string `json:"accounts" yaml:"accounts"`
+}
+
+// LoadConfig reads a Config from a YAML or JSON file
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	if err := utils.DecodeFile(path, &cfg); err != nil {
+		return Config{}, err
+	}
+
+	if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+		return Config{}, fmt.Errorf("url in %s must be the http:// or https:// address of the actual-http-api server", path)
+	}
+	if cfg.BudgetSyncID == "" {
+		return Config{}, fmt.Errorf("missing budget_sync_id in %s", path)
+	}
+	if len(cfg.Accounts) == 0 {
+		return Config{}, fmt.Errorf("no accounts in %s; map each group to push to an Actual account ID", path)
+	}
+	for group, accountID := range cfg.Accounts {
+		if accountID == "" {
+			return Config{}, fmt.Errorf("empty account ID for %q in %s", group, path)
+		}
+	}
+
+	return cfg, nil
+}
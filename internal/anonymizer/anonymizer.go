@@ -0,0 +1,176 @@
+package anonymizer
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/parser"
+	"sms-parser/internal/utils"
+)
+
+const (
+	// AmountPlaceholder replaces every amount with decimals in a message body
+	AmountPlaceholder = "100.00"
+	// GroupedAmountPlaceholder replaces comma-grouped amounts without decimals
+	GroupedAmountPlaceholder = "1,000"
+	// WholeAmountPlaceholder replaces other whole amounts
+	WholeAmountPlaceholder = "100"
+	// NamePlaceholder replaces counterparty names in transfer messages
+	NamePlaceholder = "REDACTED"
+	// IPAPlaceholder replaces InstaPay addresses
+	IPAPlaceholder = "redacted@instapay"
+)
+
+// currencyExpr matches the currencies amounts are written with
+const currencyExpr = `(?:EGP|USD|EUR|GBP|SAR|AED|KWD|QAR|BHD|OMR|JOD|INR|KES|SR|L\.E\.?|ج\.م|جنيه|جم|ر\.س|ريال|درهم|د\.إ|د\.ك|د\.أ)`
+
+// amountExpr matches a number written as an amount
+const amountExpr = `\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+(?:\.\d+)?`
+
+var (
+	// numberPattern finds, in order of preference, dates and times (kept),
+	// amounts after a currency or amount keyword, amounts before a currency,
+	// amounts with separators, and runs of four or more digits, which are
+	// card, account, phone, or reference numbers
+	numberPattern = regexp.MustCompile(
+		`(\d{1,4}[-/]\d{1,2}(?:[-/]\d{1,4})?|\d{1,2}:\d{2}(?::\d{2})?)` +
+			`|((?:` + currencyExpr + `|(?i:amount(?:\s+of)?|charged for)|مبلغ)\s*)(` + amountExpr + `)` +
+			`|(` + amountExpr + `)(\s*` + currencyExpr + `)` +
+			`|(\d{1,3}(?:,\d{3})+(?:\.\d+)?|\d+\.\d+)` +
+			`|(\d{4,})`)
+	// ipaPattern finds InstaPay addresses such as "ahmed.ali@instapay"
+	ipaPattern = regexp.MustCompile(`(?i)[\w.-]+@instapay\b`)
+	// phoneSenderPattern matches sender addresses that are phone numbers
+	// rather than business sender IDs
+	phoneSenderPattern = regexp.MustCompile(`^\+?[\d\s-]+$`)
+)
+
+// Anonymizer masks sensitive data in SMS backups while keeping the wording
+// and senders parsers rely on
+type Anonymizer struct {
+	numbers map[string]string
+	parser  *parser.Parser
+}
+
+// New creates a new Anonymizer instance
+func New() *Anonymizer {
+	return &Anonymizer{
+		numbers: make(map[string]string),
+		parser:  parser.New(parser.Config{}),
+	}
+}
+
+// Anonymize returns a sanitized copy of the body of a message from sender.
+// Counterparty names are found with the parsers' own patterns, so they are
+// redacted wherever a parser would capture them.
+func (a *Anonymizer) Anonymize(sender, body string) string {
+	clean := body
+	for _, name := range a.parser.Counterparties(sender, body) {
+		name = strings.TrimSpace(ipaPattern.ReplaceAllString(name, ""))
+		if name == "" || name == NamePlaceholder {
+			continue
+		}
+		clean = regexp.MustCompile(`(?i)`+regexp.QuoteMeta(name)).ReplaceAllString(clean, NamePlaceholder)
+	}
+	clean = ipaPattern.ReplaceAllString(clean, IPAPlaceholder)
+
+	// Eastern Arabic digits would otherwise slip past the patterns
+	clean = utils.NormalizeDigits(clean)
+
+	return numberPattern.ReplaceAllStringFunc(clean, func(match string) string {
+		parts := numberPattern.FindStringSubmatch(match)
+		switch {
+		case parts[1] != "":
+			return match
+		case parts[3] != "":
+			return parts[2] + amountPlaceholder(parts[3])
+		case parts[4] != "":
+			return amountPlaceholder(parts[4]) + parts[5]
+		case parts[6] != "":
+			return amountPlaceholder(parts[6])
+		default:
+			return a.maskNumber(parts[7])
+		}
+	})
+}
+
+// amountPlaceholder returns the placeholder with the same shape as amount, so
+// patterns that require decimals or grouping still match
+func amountPlaceholder(amount string) string {
+	switch {
+	case strings.Contains(amount, "."):
+		return AmountPlaceholder
+	case strings.Contains(amount, ","):
+		return GroupedAmountPlaceholder
+	default:
+		return WholeAmountPlaceholder
+	}
+}
+
+// maskNumber replaces a card, account, or phone number with a stable pseudonym
+// of the same length, so repeated numbers still map to the same account
+func (a *Anonymizer) maskNumber(number string) string {
+	if masked, ok := a.numbers[number]; ok {
+		return masked
+	}
+
+	masked := fmt.Sprintf("%0*d", len(number), len(a.numbers)+1)
+	a.numbers[number] = masked
+	return masked
+}
+
+// AnonymizeSender masks a sender that is a phone number, keeping business
+// sender IDs such as "CIB", which select the parser
+func (a *Anonymizer) AnonymizeSender(address string) string {
+	if !phoneSenderPattern.MatchString(address) {
+		return address
+	}
+
+	digits := strings.TrimLeft(address, "+")
+	return strings.TrimSuffix(address, digits) + a.maskNumber(digits)
+}
+
+// AnonymizeFile reads an SMS backup XML file and writes a sanitized copy to
+// outputPath. Messages that no parser reads, such as personal messages, are
+// left out. It returns the number of messages written and left out.
+func (a *Anonymizer) AnonymizeFile(inputPath, outputPath string) (int, int, error) {
+	xmlFile, err := os.ReadFile(inputPath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("error reading file: %w", err)
+	}
+
+	var backup models.SMSBackup
+	if err := xml.Unmarshal(xmlFile, &backup); err != nil {
+		return 0, 0, fmt.Errorf("error parsing XML: %w", err)
+	}
+
+	kept := backup.SMS[:0]
+	for _, sms := range backup.SMS {
+		if !a.parser.Handles(sms.Address, sms.Body) {
+			continue
+		}
+		sms.Body = a.Anonymize(sms.Address, sms.Body)
+		sms.Address = a.AnonymizeSender(sms.Address)
+		// Contact names may be people from the phone's address book
+		sms.ContactName = ""
+		kept = append(kept, sms)
+	}
+	dropped := len(backup.SMS) - len(kept)
+	backup.SMS = kept
+
+	output, err := xml.MarshalIndent(backup, "", "  ")
+	if err != nil {
+		return 0, 0, fmt.Errorf("error encoding XML: %w", err)
+	}
+
+	content := append([]byte(xml.Header), output...)
+	if err := os.WriteFile(outputPath, append(content, '\n'), 0644); err != nil {
+		return 0, 0, fmt.Errorf("error writing %s: %w", outputPath, err)
+	}
+
+	return len(kept), dropped, nil
+}
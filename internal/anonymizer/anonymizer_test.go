@@ -0,0 +1,169 @@
+package anonymizer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sms-parser/internal/parser"
+)
+
+func TestAnonymize(t *testing.T) {
+	tests := []struct {
+		name   string
+		sender string
+		body   string
+		// masked must not appear in the output
+		masked []string
+		// kept must still appear in the output
+		kept []string
+	}{
+		{
+			name:   "CIB transfer out",
+			sender: "CIB",
+			body:   "Your account 2373 was debited with amount EGP 1,250.50 to Ahmed Mohamed with reference 123456789 on 01/01/2024",
+			masked: []string{"2373", "1,250.50", "Ahmed", "Mohamed", "123456789"},
+			kept:   []string{"Your account ", "was debited with amount EGP 100.00 to " + NamePlaceholder + " with reference", "01/01/2024"},
+		},
+		{
+			name:   "CIB IPN transfer in",
+			sender: "CIB",
+			body:   "Your account 2373 was credited with IPN Inward for EGP 3,000.00 from Sara Ali with reference 5566778899",
+			masked: []string{"2373", "3,000.00", "Sara", "5566778899"},
+			kept:   []string{"credited with IPN Inward for EGP 100.00 from " + NamePlaceholder + " with reference"},
+		},
+		{
+			name:   "whole amounts",
+			sender: "CIB",
+			body:   "Your credit card ending with 4321 was charged for EGP 500 at CARREFOUR on 01/01 at 12:30",
+			masked: []string{"4321", "500"},
+			kept:   []string{"credit card ending with ", "charged for EGP 100 at CARREFOUR on 01/01 at 12:30"},
+		},
+		{
+			name:   "four-digit amounts are amounts",
+			sender: "CIB",
+			body:   "Your credit card ending with 4321 was charged for EGP 1500 at CARREFOUR on 01/01",
+			masked: []string{"1500"},
+			kept:   []string{"charged for EGP 100 at CARREFOUR"},
+		},
+		{
+			name:   "amount before currency",
+			sender: "Banque Misr",
+			body:   "تم تحويل مبلغ 2500 جنيه من حساب ****4567",
+			masked: []string{"2500", "4567"},
+			kept:   []string{"تم تحويل مبلغ 100 جنيه من حساب ****"},
+		},
+		{
+			name:   "Eastern Arabic digits",
+			sender: "Banque Misr",
+			body:   "تم الخصم مبلغ ١٬٢٥٠٫٥٠ جنيه من بطاقة بنك مصر ****٤٥٦٧ BM CARREFOUR يوم ٠١/٠١",
+			masked: []string{"١", "٤٥٦٧", "4567", "1,250.50"},
+			kept:   []string{"تم الخصم مبلغ 100.00 جنيه من بطاقة بنك مصر ****", "BM CARREFOUR يوم 01/01"},
+		},
+		{
+			name:   "InstaPay name and address",
+			sender: "InstaPay",
+			body:   "You have received EGP 500.00 from Ahmed Mohamed Ali (ahmed.ali@instapay) on 01/01",
+			masked: []string{"Ahmed", "ahmed.ali", "500.00"},
+			kept:   []string{"You have received EGP 100.00 from " + NamePlaceholder + " (" + IPAPlaceholder + ") on 01/01"},
+		},
+		{
+			name:   "wallet transfer via InstaPay",
+			sender: "InstaPay",
+			body:   "You have sent EGP 500.00 to Mona Samir via InstaPay on 01/01. Ref 987654321",
+			masked: []string{"Mona", "Samir", "987654321"},
+			kept:   []string{"You have sent EGP 100.00 to " + NamePlaceholder + " via InstaPay on 01/01. Ref "},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New().Anonymize(tt.sender, tt.body)
+			for _, masked := range tt.masked {
+				if strings.Contains(got, masked) {
+					t.Errorf("Anonymize() = %q, still contains %q", got, masked)
+				}
+			}
+			for _, kept := range tt.kept {
+				if !strings.Contains(got, kept) {
+					t.Errorf("Anonymize() = %q, want it to contain %q", got, kept)
+				}
+			}
+		})
+	}
+}
+
+func TestAnonymizeStableNumbers(t *testing.T) {
+	a := New()
+	first := a.Anonymize("CIB", "Your credit card ending with 4321 was charged for EGP 50.00 at KFC on 01/01")
+	second := a.Anonymize("CIB", "Your credit card ending with 4321 was charged for EGP 80.00 at KFC on 02/01")
+	other := a.Anonymize("CIB", "Your credit card ending with 8765 was charged for EGP 80.00 at KFC on 02/01")
+
+	card := func(body string) string {
+		_, after, _ := strings.Cut(body, "ending with ")
+		return after[:4]
+	}
+	if card(first) != card(second) {
+		t.Errorf("the same card was masked as %q and %q", card(first), card(second))
+	}
+	if card(first) == card(other) {
+		t.Errorf("different cards were both masked as %q", card(first))
+	}
+}
+
+func TestAnonymizeSender(t *testing.T) {
+	a := New()
+	if got := a.AnonymizeSender("CIB"); got != "CIB" {
+		t.Errorf("AnonymizeSender(CIB) = %q, want the sender ID kept", got)
+	}
+	if got := a.AnonymizeSender("+201001234567"); got == "+201001234567" || !strings.HasPrefix(got, "+") || len(got) != len("+201001234567") {
+		t.Errorf("AnonymizeSender(+201001234567) = %q, want a masked number of the same shape", got)
+	}
+}
+
+func TestAnonymizeFile(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "backup.xml")
+	output := filepath.Join(dir, "anonymized.xml")
+
+	backup := `<?xml version="1.0" encoding="UTF-8"?>
+<smses>
+  <sms address="+201001234567" date="1704103200000" body="Hey Ahmed, dinner at 8?" contact_name="Ahmed Mohamed" />
+  <sms address="InstaPay" date="1704106800000" body="You have received EGP 750.00 from Ahmed Mohamed Ali on 01/01" contact_name="InstaPay" />
+</smses>
+`
+	if err := os.WriteFile(input, []byte(backup), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	kept, dropped, err := New().AnonymizeFile(input, output)
+	if err != nil {
+		t.Fatalf("AnonymizeFile() error = %v", err)
+	}
+	if kept != 1 || dropped != 1 {
+		t.Errorf("AnonymizeFile() = %d kept, %d dropped, want 1 and 1", kept, dropped)
+	}
+
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"201001234567", "Hey Ahmed", "Ahmed Mohamed", "750.00"} {
+		if strings.Contains(string(data), leaked) {
+			t.Errorf("anonymized backup still contains %q", leaked)
+		}
+	}
+
+	// The sample must still reproduce what the parsers do with the original
+	accounts, err := parser.ParseBytes(data, parser.Options{})
+	if err != nil {
+		t.Fatalf("ParseBytes() error = %v", err)
+	}
+	if len(accounts) != 1 || len(accounts[0].Transactions) != 1 {
+		t.Fatalf("ParseBytes() = %+v, want one InstaPay transaction", accounts)
+	}
+	if tx := accounts[0].Transactions[0]; tx.Amount != 100 || tx.Payee != NamePlaceholder {
+		t.Errorf("parsed transaction = %v %q, want 100 from %q", tx.Amount, tx.Payee, NamePlaceholder)
+	}
+}
@@ -1,131 +1,115 @@
 package categorizer
 
 import (
+	"fmt"
+	"slices"
+	"sort"
 	"strings"
 
 	"sms-parser/internal/models"
 	"sms-parser/internal/utils"
 )
 
-// Categorizer handles transaction categorization
-type Categorizer struct{}
+// KeywordsFile is the format of a user-supplied categorization file
+type KeywordsFile struct {
+	// Replace drops the built-in keyword lists instead of merging with them
+	Replace bool `json:"replace" yaml:"replace"`
+	// Categories maps a category name to the keywords that select it
+	Categories map[string][]string `json:"categories" yaml:"categories"`
+}
 
-// New creates a new Categorizer instance
-func New() *Categorizer {
-	return &Categorizer{}
+// categoryRule maps a category to the keywords that select it
+type categoryRule struct {
+	category string
+	keywords []string
 }
 
-// Categorize assigns a category to a transaction based on payee and note
-func (c *Categorizer) Categorize(payee, note string, amount float64) string {
-	cleanPayee := utils.CleanPayeeName(payee)
-	text := strings.ToLower(cleanPayee + " " + note)
+// Categorizer handles transaction categorization
+type Categorizer struct {
+	rules []categoryRule
+}
 
-	// Income
-	if amount > 0 {
-		return models.CatIncome
+// New creates a new Categorizer instance. When keywordsFile is not empty, its
+// keywords are checked before the built-in lists, so they can override a
+// built-in match; with replace: true the built-in lists are dropped entirely.
+func New(keywordsFile string) (*Categorizer, error) {
+	if keywordsFile == "" {
+		return &Categorizer{rules: builtinRules}, nil
 	}
 
-	// Financial / Transfers
-	if utils.Contains(text, "credit card payment", "sadaad", "cib repayment") {
-		return models.CatFinancial
+	var file KeywordsFile
+	if err := utils.DecodeFile(keywordsFile, &file); err != nil {
+		return nil, fmt.Errorf("failed to load categories: %w", err)
 	}
 
-	// Shopping
-	shoppingKeywords := []string{
-		"amazon", "noon", "jumia", "souq", "shopping", "zara", "h&m",
-		"lc waikiki", "defacto", "american eagle", "lachica", "ravin",
-		"el salama", "stitch", "clothes", "fashion", "shoes", "concrete",
-		"town team", "activ", "naga", "rich for cloth", "pronto",
-		"scarpe", "scarape", "tie house", "rose paris", "b tech", "b.tech",
-		"trade line", "2b", "best buy", "dubai phone", "mobile shop",
-		"el araby", "fresh electric", "tornado",
-	}
-	if utils.Contains(text, shoppingKeywords...) {
-		return models.CatShopping
+	// Sort category names so user rules are applied in a stable order
+	categories := make([]string, 0, len(file.Categories))
+	for category := range file.Categories {
+		categories = append(categories, category)
 	}
+	sort.Strings(categories)
 
-	// Housing (furniture)
-	if utils.Contains(text, "ikea", "homzmart", "furniture", "jotun", "ahfad") {
-		return models.CatHousing
+	var rules []categoryRule
+	for _, category := range categories {
+		keywords := make([]string, 0, len(file.Categories[category]))
+		for _, keyword := range file.Categories[category] {
+			if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+				keywords = append(keywords, keyword)
+			}
+		}
+		rules = append(rules, categoryRule{category: category, keywords: keywords})
 	}
 
-	// Food & Drink
-	foodKeywords := []string{
-		"mcdonalds", "kfc", "pizza", "burger", "buffalo", "primos",
-		"spectra", "desoky", "sandwich", "elmenus", "talabat", "breadfast",
-		"roosters", "hardees", "manchow", "willys", "dhad", "el dahan",
-		"sanabel", "fookotcharia", "krispy", "cafe", "costa", "starbucks",
-		"cilantro", "tbsp", "espresso", "beano", "cinnabon", "dunkin",
-		"caribou", "house of cocoa", "sale sucre", "dar el bon", "karak",
-		"potasta", "b labn", "b.labn", "carrefour", "fathalla", "market",
-		"seoudi", "gomla", "bim", "kazyon", "hyper", "ramadan hamada",
-		"saood", "metro", "kheir zaman", "ragab", "abu auf", "kashier",
-		"elkhalil", "aswak", "fresh food", "sun mall", "grapes",
-	}
-	if utils.Contains(text, foodKeywords...) {
-		return models.CatFood
+	if !file.Replace {
+		rules = append(rules, builtinRules...)
 	}
 
-	// Transportation
-	transportKeywords := []string{
-		"uber", "didi", "careem", "indriver", "transport", "super jet",
-		"railways", "go bus", "swvl", "pegasus", "fly", "airline",
-		"booking", "flight",
-	}
-	if utils.Contains(text, transportKeywords...) {
-		return models.CatTransport
-	}
+	return &Categorizer{rules: rules}, nil
+}
 
-	// Vehicle
-	vehicleKeywords := []string{
-		"mobil", "chillout", "gas station", "total", "ola", "master gas",
-		"adnoc", "wataniya", "fuel", "car service", "tire", "fit & fix",
-	}
-	if utils.Contains(text, vehicleKeywords...) {
-		return models.CatVehicle
-	}
+// Match is the outcome of categorizing a transaction
+type Match struct {
+	Category string
+	// Keyword is the keyword that selected Category; it is empty when no
+	// keyword matched, including for income, which needs none
+	Keyword string
+}
 
-	// Housing & Utilities
-	housingKeywords := []string{
-		"sahl", "electricity", "water", "bill", "national gas", "natgas",
-		"town gas", "petrotrade", "taqa", "north cairo",
-	}
-	if utils.Contains(text, housingKeywords...) {
-		return models.CatHousing
-	}
+// Classify assigns a category to a transaction based on payee and note and
+// reports which keyword decided it
+func (c *Categorizer) Classify(payee, note string, amount float64) Match {
+	cleanPayee := utils.CleanPayeeName(payee)
+	text := strings.ToLower(cleanPayee + " " + note)
 
-	// Communication & PC
-	commsKeywords := []string{
-		"vodafone", "orange", "etisalat", "we ", "telecom", "top up",
-		"landline", "we-fv", "internet", "fbb", "adsl", "google",
-		"microsoft", "adobe", "apple", "icloud", "storage", "host",
-		"domain", "xbox", "playstation", "steam", "games", "mullvad",
-		"linkedin",
-	}
-	if utils.Contains(text, commsKeywords...) {
-		return models.CatComms
+	// Income
+	if amount > 0 {
+		return Match{Category: models.CatIncome}
 	}
 
-	// Life & Entertainment
-	lifeKeywords := []string{
-		"netflix", "spotify", "osn", "shahid", "youtube", "watch it",
-		"yango", "vox", "cinema", "renessance", "ticket", "tazkarti",
-		"kindle", "audible", "books", "diwan", "pharmacy", "dr.",
-		"hospital", "medical", "ezaby", "elezzaby", "seif", "rushdy",
-		"andalusia", "yosra", "hany", "tay",
-	}
-	if utils.Contains(text, lifeKeywords...) {
-		return models.CatLife
+	for _, rule := range c.rules {
+		for _, keyword := range rule.keywords {
+			if strings.Contains(text, keyword) {
+				return Match{Category: rule.category, Keyword: keyword}
+			}
+		}
 	}
 
-	// Financial / Cash
-	financialKeywords := []string{
-		"atm", "withdrawal", "s7b", "سحب", "cash", "fawry",
-		"my fawry", "fawrypay",
-	}
-	if utils.Contains(text, financialKeywords...) {
-		return models.CatFinancial
+	return Match{Category: models.CatGeneral}
+}
+
+// Categories lists every category a transaction can end up in: the built-in
+// categories followed by those only the keywords file defines
+func (c *Categorizer) Categories() []string {
+	categories := slices.Clone(models.Categories)
+	for _, rule := range c.rules {
+		if !slices.Contains(categories, rule.category) {
+			categories = append(categories, rule.category)
+		}
 	}
+	return categories
+}
 
-	return models.CatGeneral
+// Categorize assigns a category to a transaction based on payee and note
+func (c *Categorizer) Categorize(payee, note string, amount float64) string {
+	return c.Classify(payee, note, amount).Category
 }
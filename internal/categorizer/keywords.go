@@ -0,0 +1,101 @@
+package categorizer
+
+import "sms-parser/internal/models"
+
+// builtinRules lists the default keywords per category, in the order they are checked
+var builtinRules = []categoryRule{
+	// Financial / Transfers
+	{
+		category: models.CatFinancial,
+		keywords: []string{"credit card payment", "sadaad", "cib repayment"},
+	},
+	// Shopping
+	{
+		category: models.CatShopping,
+		keywords: []string{
+			"amazon", "noon", "jumia", "souq", "shopping", "zara", "h&m",
+			"lc waikiki", "defacto", "american eagle", "lachica", "ravin",
+			"el salama", "stitch", "clothes", "fashion", "shoes", "concrete",
+			"town team", "activ", "naga", "rich for cloth", "pronto",
+			"scarpe", "scarape", "tie house", "rose paris", "b tech", "b.tech",
+			"trade line", "2b", "best buy", "dubai phone", "mobile shop",
+			"el araby", "fresh electric", "tornado",
+		},
+	},
+	// Housing (furniture)
+	{
+		category: models.CatHousing,
+		keywords: []string{"ikea", "homzmart", "furniture", "jotun", "ahfad"},
+	},
+	// Food & Drink
+	{
+		category: models.CatFood,
+		keywords: []string{
+			"mcdonalds", "kfc", "pizza", "burger", "buffalo", "primos",
+			"spectra", "desoky", "sandwich", "elmenus", "talabat", "breadfast",
+			"roosters", "hardees", "manchow", "willys", "dhad", "el dahan",
+			"sanabel", "fookotcharia", "krispy", "cafe", "costa", "starbucks",
+			"cilantro", "tbsp", "espresso", "beano", "cinnabon", "dunkin",
+			"caribou", "house of cocoa", "sale sucre", "dar el bon", "karak",
+			"potasta", "b labn", "b.labn", "carrefour", "fathalla", "market",
+			"seoudi", "gomla", "bim", "kazyon", "hyper", "ramadan hamada",
+			"saood", "metro", "kheir zaman", "ragab", "abu auf", "kashier",
+			"elkhalil", "aswak", "fresh food", "sun mall", "grapes",
+		},
+	},
+	// Transportation
+	{
+		category: models.CatTransport,
+		keywords: []string{
+			"uber", "didi", "careem", "indriver", "transport", "super jet",
+			"railways", "go bus", "swvl", "pegasus", "fly", "airline",
+			"booking", "flight",
+		},
+	},
+	// Vehicle
+	{
+		category: models.CatVehicle,
+		keywords: []string{
+			"mobil", "chillout", "gas station", "total", "ola", "master gas",
+			"adnoc", "wataniya", "fuel", "car service", "tire", "fit & fix",
+		},
+	},
+	// Housing & Utilities
+	{
+		category: models.CatHousing,
+		keywords: []string{
+			"sahl", "electricity", "water", "bill", "national gas", "natgas",
+			"town gas", "petrotrade", "taqa", "north cairo",
+		},
+	},
+	// Communication & PC
+	{
+		category: models.CatComms,
+		keywords: []string{
+			"vodafone", "orange", "etisalat", "we ", "telecom", "top up",
+			"landline", "we-fv", "internet", "fbb", "adsl", "google",
+			"microsoft", "adobe", "apple", "icloud", "storage", "host",
+			"domain", "xbox", "playstation", "steam", "games", "mullvad",
+			"linkedin",
+		},
+	},
+	// Life & Entertainment
+	{
+		category: models.CatLife,
+		keywords: []string{
+			"netflix", "spotify", "osn", "shahid", "youtube", "watch it",
+			"yango", "vox", "cinema", "renessance", "ticket", "tazkarti",
+			"kindle", "audible", "books", "diwan", "pharmacy", "dr.",
+			"hospital", "medical", "ezaby", "elezzaby", "seif", "rushdy",
+			"andalusia", "yosra", "hany", "tay",
+		},
+	},
+	// Financial / Cash
+	{
+		category: models.CatFinancial,
+		keywords: []string{
+			"atm", "withdrawal", "s7b", "سحب", "cash", "fawry",
+			"my fawry", "fawrypay",
+		},
+	},
+}
@@ -0,0 +1,179 @@
+package firefly
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// Split is a transaction in the shape of the Firefly III API's transaction
+// split; each SMS becomes a transaction group with a single split
+type Split struct {
+	Type         string `json:"type"`
+	Date         string `json:"date"`
+	Amount       string `json:"amount"`
+	Description  string `json:"description"`
+	CurrencyCode string `json:"currency_code"`
+	CategoryName string `json:"category_name,omitempty"`
+	// SourceID/SourceName and DestinationID/DestinationName are the asset
+	// account and the payee, in the direction the money moved
+	SourceID        string `json:"source_id,omitempty"`
+	SourceName      string `json:"source_name,omitempty"`
+	DestinationID   string `json:"destination_id,omitempty"`
+	DestinationName string `json:"destination_name,omitempty"`
+	Notes           string `json:"notes,omitempty"`
+	ExternalID      string `json:"external_id"`
+}
+
+// Result summarizes a push: transactions whose external ID already exists in
+// Firefly III are skipped as duplicates
+type Result struct {
+	Created    int
+	Duplicates int
+}
+
+// Client calls the Firefly III API with a personal access token
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// New creates a Client for the Firefly III instance at baseURL
+func New(token, baseURL string) *Client {
+	return &Client{
+		token:      token,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewSplit converts a parsed transaction for the asset account accountID:
+// money out is a withdrawal to the payee, money in a deposit from it. The
+// external ID is the transaction's stable ID.
+func NewSplit(tx models.Transaction, accountID, category string) Split {
+	payee := tx.Payee
+	if payee == "" {
+		payee = "(unknown)"
+	}
+
+	split := Split{
+		Date:         tx.Timestamp.Format(time.RFC3339),
+		Amount:       utils.FormatAmount(math.Abs(tx.Amount), tx.Currency),
+		Description:  payee,
+		CurrencyCode: tx.Currency,
+		CategoryName: category,
+		Notes:        tx.Note,
+		ExternalID:   tx.ID,
+	}
+	if tx.Amount < 0 {
+		split.Type = "withdrawal"
+		split.SourceID = accountID
+		split.DestinationName = payee
+	} else {
+		split.Type = "deposit"
+		split.SourceName = payee
+		split.DestinationID = accountID
+	}
+	return split
+}
+
+// CreateTransactions creates one transaction group per split, skipping those
+// whose external ID Firefly III already has
+func (c *Client) CreateTransactions(ctx context.Context, splits []Split) (Result, error) {
+	var result Result
+	for _, split := range splits {
+		exists, err := c.hasExternalID(ctx, split.ExternalID)
+		if err != nil {
+			return result, err
+		}
+		if exists {
+			result.Duplicates++
+			continue
+		}
+
+		body := map[string]any{
+			"apply_rules":  true,
+			"transactions": []Split{split},
+		}
+		if err := c.do(ctx, http.MethodPost, "/api/v1/transactions", body, nil); err != nil {
+			return result, fmt.Errorf("error creating %q on %s: %w", split.Description, split.Date, err)
+		}
+		result.Created++
+	}
+
+	return result, nil
+}
+
+// hasExternalID reports whether a transaction with the external ID exists
+func (c *Client) hasExternalID(ctx context.Context, externalID string) (bool, error) {
+	query := url.Values{"query": {fmt.Sprintf("external_id_is:%q", externalID)}, "limit": {"1"}}
+
+	var found struct {
+		Data []json.RawMessage `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/api/v1/search/transactions?"+query.Encode(), nil, &found); err != nil {
+		return false, fmt.Errorf("error searching for external ID %s: %w", externalID, err)
+	}
+	return len(found.Data) > 0, nil
+}
+
+// do sends a JSON request and decodes the JSON response into out, if given
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("error encoding request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling Firefly III: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading Firefly III response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("Firefly III returned %s: %s", resp.Status, apiErr.Message)
+		}
+		return fmt.Errorf("Firefly III returned %s", resp.Status)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("error decoding Firefly III response: %w", err)
+		}
+	}
+	return nil
+}
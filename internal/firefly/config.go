@@ -0,0 +1,51 @@
+package firefly
+
+import (
+	"fmt"
+	"strings"
+
+	"sms-parser/internal/utils"
+)
+
+// Config names the Firefly III instance and the asset account each group is
+// pushed to
+type Config struct {
+	// URL is the address of the Firefly III instance, e.g. https://firefly.example.com
+	URL string `json:"url" yaml:"url"`
+	// Accounts maps a group (e.g. "CIB_Current_Debit") to the ID of a Firefly
+	// III asset account; groups without an entry are not pushed
+	Accounts map[string]string `json:"accounts" yaml:"accounts"`
+	// Categories renames categories for Firefly III (e.g. "Food & Drink" to
+	// "Groceries"); unmapped categories keep their name
+	Categories map[string]string `json:"categories" yaml:"categories"`
+}
+
+// LoadConfig reads a Config from a YAML or JSON file
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	if err := utils.DecodeFile(path, &cfg); err != nil {
+		return Config{}, err
+	}
+
+	if !strings.HasPrefix(cfg.URL, "http://") && !strings.HasPrefix(cfg.URL, "https://") {
+		return Config{}, fmt.Errorf("url in %s must be the http:// or https:// address of the Firefly III instance", path)
+	}
+	if len(cfg.Accounts) == 0 {
+		return Config{}, fmt.Errorf("no accounts in %s; map each group to push to a Firefly III asset account ID", path)
+	}
+	for group, accountID := range cfg.Accounts {
+		if accountID == "" {
+			return Config{}, fmt.Errorf("empty account ID for %q in %s", group, path)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Category returns the Firefly III category name for a category
+func (c Config) Category(category string) string {
+	if name, ok := c.Categories[category]; ok {
+		return name
+	}
+	return category
+}
@@ -0,0 +1,261 @@
+package lunchmoney
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// DefaultBaseURL is the Lunch Money API endpoint
+const DefaultBaseURL = "https://dev.lunchmoney.app/v1"
+
+// pageSize is the number of transactions requested per page when looking
+// up existing external IDs
+const pageSize = 500
+
+// Transaction is a transaction in the shape of the Lunch Money API's insert
+// object
+type Transaction struct {
+	Date  string `json:"date"`
+	Payee string `json:"payee"`
+	// Amount is negative for money out, as requested with debit_as_negative
+	Amount     string `json:"amount"`
+	Currency   string `json:"currency"`
+	AssetID    int64  `json:"asset_id"`
+	CategoryID int64  `json:"category_id,omitempty"`
+	Notes      string `json:"notes,omitempty"`
+	Status     string `json:"status"`
+	ExternalID string `json:"external_id"`
+}
+
+// Result summarizes a push: transactions whose external ID the asset
+// already has are skipped as duplicates
+type Result struct {
+	Created    int
+	Duplicates int
+}
+
+// Client calls the Lunch Money API with an access token
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// New creates a Client for the Lunch Money API at baseURL (DefaultBaseURL
+// when empty)
+func New(token, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Client{
+		token:      token,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewTransaction converts a parsed transaction for the asset assetID, in the
+// category categoryID (0 for none). The external ID is the transaction's
+// stable ID.
+func NewTransaction(tx models.Transaction, assetID, categoryID int64) Transaction {
+	payee := tx.Payee
+	if payee == "" {
+		payee = "(unknown)"
+	}
+
+	return Transaction{
+		Date:       tx.Timestamp.Format("2006-01-02"),
+		Payee:      payee,
+		Amount:     utils.FormatAmount(tx.Amount, tx.Currency),
+		Currency:   strings.ToLower(tx.Currency),
+		AssetID:    assetID,
+		CategoryID: categoryID,
+		Notes:      strings.Join(strings.Fields(tx.Note), " "),
+		Status:     "cleared",
+		ExternalID: tx.ID,
+	}
+}
+
+// Categories returns the IDs of the account's categories by name
+func (c *Client) Categories(ctx context.Context) (map[string]int64, error) {
+	var found struct {
+		Categories []struct {
+			ID      int64  `json:"id"`
+			Name    string `json:"name"`
+			IsGroup bool   `json:"is_group"`
+		} `json:"categories"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/categories", nil, &found); err != nil {
+		return nil, fmt.Errorf("error listing categories: %w", err)
+	}
+
+	ids := make(map[string]int64, len(found.Categories))
+	for _, category := range found.Categories {
+		// Category groups cannot be assigned to transactions
+		if !category.IsGroup {
+			ids[category.Name] = category.ID
+		}
+	}
+	return ids, nil
+}
+
+// CreateTransactions inserts the transactions of one asset whose external
+// ID it does not have yet, in one request
+func (c *Client) CreateTransactions(ctx context.Context, assetID int64, transactions []Transaction) (Result, error) {
+	var result Result
+	if len(transactions) == 0 {
+		return result, nil
+	}
+
+	start, end := transactions[0].Date, transactions[0].Date
+	for _, tx := range transactions {
+		start, end = min(start, tx.Date), max(end, tx.Date)
+	}
+	existing, err := c.externalIDs(ctx, assetID, start, end)
+	if err != nil {
+		return result, err
+	}
+
+	var inserts []Transaction
+	for _, tx := range transactions {
+		if existing[tx.ExternalID] {
+			result.Duplicates++
+			continue
+		}
+		existing[tx.ExternalID] = true
+		inserts = append(inserts, tx)
+	}
+	if len(inserts) == 0 {
+		return result, nil
+	}
+
+	body := map[string]any{
+		"transactions":      inserts,
+		"apply_rules":       true,
+		"debit_as_negative": true,
+	}
+	var created struct {
+		IDs []int64 `json:"ids"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/transactions", body, &created); err != nil {
+		return result, fmt.Errorf("error creating transactions: %w", err)
+	}
+
+	result.Created = len(created.IDs)
+	return result, nil
+}
+
+// externalIDs returns the external IDs of the asset's transactions between
+// start and end (YYYY-MM-DD, inclusive)
+func (c *Client) externalIDs(ctx context.Context, assetID int64, start, end string) (map[string]bool, error) {
+	ids := make(map[string]bool)
+	for offset := 0; ; offset += pageSize {
+		query := url.Values{
+			"asset_id":   {strconv.FormatInt(assetID, 10)},
+			"start_date": {start},
+			"end_date":   {end},
+			"limit":      {strconv.Itoa(pageSize)},
+			"offset":     {strconv.Itoa(offset)},
+		}
+
+		var page struct {
+			Transactions []struct {
+				ExternalID string `json:"external_id"`
+			} `json:"transactions"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/transactions?"+query.Encode(), nil, &page); err != nil {
+			return nil, fmt.Errorf("error listing transactions of asset %d: %w", assetID, err)
+		}
+
+		for _, tx := range page.Transactions {
+			if tx.ExternalID != "" {
+				ids[tx.ExternalID] = true
+			}
+		}
+		if len(page.Transactions) < pageSize {
+			return ids, nil
+		}
+	}
+}
+
+// do sends a JSON request and decodes the JSON response into out, if given
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("error encoding request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling Lunch Money: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading Lunch Money response: %w", err)
+	}
+
+	// Lunch Money reports some errors with a 200 status, as a string or a
+	// list of strings in "error"
+	var apiErr struct {
+		Error   json.RawMessage `json:"error"`
+		Message string          `json:"message"`
+	}
+	_ = json.Unmarshal(data, &apiErr)
+	if resp.StatusCode < 300 {
+		apiErr.Message = ""
+	}
+	if message := errorMessage(apiErr.Error, apiErr.Message); message != "" {
+		return fmt.Errorf("Lunch Money returned %s: %s", resp.Status, message)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("Lunch Money returned %s", resp.Status)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("error decoding Lunch Money response: %w", err)
+		}
+	}
+	return nil
+}
+
+// errorMessage flattens Lunch Money's "error" field, which is a string or a
+// list of strings, falling back to "message"
+func errorMessage(raw json.RawMessage, message string) string {
+	var single string
+	if json.Unmarshal(raw, &single) == nil && single != "" {
+		return single
+	}
+	var list []string
+	if json.Unmarshal(raw, &list) == nil && len(list) > 0 {
+		return strings.Join(list, "; ")
+	}
+	return message
+}
@@ -0,0 +1,44 @@
+package lunchmoney
+
+import (
+	"fmt"
+
+	"sms-parser/internal/utils"
+)
+
+// Config names the Lunch Money asset each group is pushed to
+type Config struct {
+	// Accounts maps a group (e.g. "CIB_Current_Debit") to the ID of a Lunch
+	// Money manually-managed asset; groups without an entry are not pushed
+	Accounts map[string]int64 `json:"accounts" yaml:"accounts"`
+	// Categories renames categories to Lunch Money category names (e.g.
+	// "Food & Drink" to "Groceries"); unmapped categories keep their name
+	Categories map[string]string `json:"categories" yaml:"categories"`
+}
+
+// LoadConfig reads a Config from a YAML or JSON file
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	if err := utils.DecodeFile(path, &cfg); err != nil {
+		return Config{}, err
+	}
+
+	if len(cfg.Accounts) == 0 {
+		return Config{}, fmt.Errorf("no accounts in %s; map each group to push to a Lunch Money asset ID", path)
+	}
+	for group, assetID := range cfg.Accounts {
+		if assetID <= 0 {
+			return Config{}, fmt.Errorf("invalid asset ID for %q in %s", group, path)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Category returns the Lunch Money category name for a category
+func (c Config) Category(category string) string {
+	if name, ok := c.Categories[category]; ok {
+		return name
+	}
+	return category
+}
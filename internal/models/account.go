@@ -0,0 +1,68 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Account is a named group of transactions, written to its own output file
+type Account struct {
+	Name         string
+	Transactions []Transaction
+}
+
+// GroupAccounts groups transactions into accounts by their TargetGroup.
+// Accounts are sorted by name and keep their transactions in input order.
+func GroupAccounts(transactions []Transaction) []Account {
+	index := make(map[string]int)
+	var accounts []Account
+
+	for _, tx := range transactions {
+		i, ok := index[tx.TargetGroup]
+		if !ok {
+			i = len(accounts)
+			index[tx.TargetGroup] = i
+			accounts = append(accounts, Account{Name: tx.TargetGroup})
+		}
+		accounts[i].Transactions = append(accounts[i].Transactions, tx)
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Name < accounts[j].Name
+	})
+
+	return accounts
+}
+
+// RenameAccounts returns the accounts with names replaced according to
+// renames (old name to new name), sorted by the new names. Accounts without
+// an entry keep their name. It fails if two accounts would end up with the
+// same name.
+func RenameAccounts(accounts []Account, renames map[string]string) ([]Account, error) {
+	renamed := make([]Account, 0, len(accounts))
+	origins := make(map[string]string, len(accounts))
+
+	for _, account := range accounts {
+		name := account.Name
+		if newName, ok := renames[name]; ok {
+			name = newName
+		}
+		if origin, ok := origins[name]; ok {
+			return nil, fmt.Errorf("renaming %s and %s would give both the name %q", origin, account.Name, name)
+		}
+		origins[name] = account.Name
+
+		transactions := make([]Transaction, len(account.Transactions))
+		for i, tx := range account.Transactions {
+			tx.TargetGroup = name
+			transactions[i] = tx
+		}
+		renamed = append(renamed, Account{Name: name, Transactions: transactions})
+	}
+
+	sort.Slice(renamed, func(i, j int) bool {
+		return renamed[i].Name < renamed[j].Name
+	})
+
+	return renamed, nil
+}
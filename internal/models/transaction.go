@@ -1,6 +1,12 @@
 package models
 
-import "encoding/xml"
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 // Category constants
 const (
@@ -16,9 +22,19 @@ const (
 	CatGeneral   = "General"
 )
 
+// Categories lists every category constant
+var Categories = []string{
+	CatFood, CatShopping, CatHousing, CatTransport, CatVehicle,
+	CatLife, CatComms, CatFinancial, CatIncome, CatGeneral,
+}
+
 // Transaction represents a parsed bank transaction
 type Transaction struct {
+	// ID is a stable hash of the source message and amount, identical
+	// across runs over the same message
+	ID          string
 	Date        string
+	Timestamp   time.Time
 	Payee       string
 	Amount      float64
 	Currency    string
@@ -26,12 +42,53 @@ type Transaction struct {
 	Category    string
 	Note        string
 	TargetGroup string
+	// CardLast4 holds the last four digits of the card or account that made
+	// the transaction, when the message names one
+	CardLast4 string
+	// Source names the parser function and pattern that produced the
+	// transaction, as "function:pattern"
+	Source    string
+	Reference string
+	// Body is the text of the SMS the transaction was parsed from
+	Body       string
+	Balance    float64
+	HasBalance bool
+	// OriginalAmount and OriginalCurrency hold the foreign-currency amount of
+	// FX transactions; for single-currency ones they mirror Amount/Currency
+	OriginalAmount   float64
+	OriginalCurrency string
+	// Pending marks a card authorization that has not settled yet
+	Pending bool
+	// Tags flags special kinds of transactions, such as TagInstallment
+	Tags []string
+}
+
+// HasTag reports whether the transaction carries tag
+func (t Transaction) HasTag(tag string) bool {
+	for _, existing := range t.Tags {
+		if existing == tag {
+			return true
+		}
+	}
+	return false
 }
 
+// Tag constants
+const (
+	// TagInstallment marks a purchase converted to installments, which
+	// repeats the amount of the original charge
+	TagInstallment = "installment"
+	// TagRecurring marks a recurring payment, such as a monthly installment
+	TagRecurring = "recurring"
+	// TagNeedsReview marks an expense that no category keyword matched
+	TagNeedsReview = "needs-review"
+)
+
 // TransactionType constants
 const (
-	TypeExpense = "Expense"
-	TypeIncome  = "Income"
+	TypeExpense  = "Expense"
+	TypeIncome   = "Income"
+	TypeTransfer = "Transfer"
 )
 
 // SMS represents a single SMS message from the XML backup
@@ -39,6 +96,38 @@ type SMS struct {
 	Address string `xml:"address,attr"`
 	Body    string `xml:"body,attr"`
 	Date    string `xml:"date,attr"`
+	// ReadableDate and ContactName are optional attributes written by SMS
+	// Backup & Restore: the phone's local time and the sender's contact name
+	ReadableDate string `xml:"readable_date,attr,omitempty"`
+	ContactName  string `xml:"contact_name,attr,omitempty"`
+}
+
+// readableDateLayouts are the readable_date formats SMS Backup & Restore
+// writes, depending on the phone's locale
+var readableDateLayouts = []string{
+	"Jan 2, 2006 3:04:05 PM",
+	"Jan 2, 2006 15:04:05",
+	"2 Jan 2006 15:04:05",
+	"2006-01-02 15:04:05",
+	"02/01/2006 15:04:05",
+}
+
+// Time returns when the message was received. The epoch-milliseconds date
+// attribute is used when valid; otherwise readable_date is interpreted in loc.
+func (s SMS) Time(loc *time.Location) (time.Time, error) {
+	if dateMs, err := strconv.ParseInt(s.Date, 10, 64); err == nil {
+		return time.UnixMilli(dateMs).In(loc), nil
+	}
+
+	// Newer app versions put a narrow no-break space before AM/PM
+	readable := strings.NewReplacer("\u202f", " ", "\u00a0", " ").Replace(s.ReadableDate)
+	for _, layout := range readableDateLayouts {
+		if t, err := time.ParseInLocation(layout, readable, loc); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid message date %q", s.Date)
 }
 
 // SMSBackup represents the root of the XML document
@@ -46,3 +135,13 @@ type SMSBackup struct {
 	XMLName xml.Name `xml:"smses"`
 	SMS     []SMS    `xml:"sms"`
 }
+
+// UnparsedMessage is an SMS from a known sender that produced no transaction
+type UnparsedMessage struct {
+	SMS    SMS
+	Reason string
+	// Skipped is true for messages that are intentionally ignored (OTP, login)
+	Skipped bool
+	// Err is the parser error behind Reason, if any
+	Err error
+}
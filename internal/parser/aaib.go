@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// aaibPatterns are the Arab African International Bank message shapes, most
+// specific first. Purchase notifications span several "Field: value" lines,
+// in any order.
+var aaibPatterns = []bankPattern{
+	{
+		// "Purchase Transaction\nCard: ****1234\nAmount: EGP 450.00\nMerchant: AMAZON EG\nDate: 01/01/2026"
+		name:  "purchase_multiline",
+		regex: regexp.MustCompile(`(?is)(?:amount|المبلغ)\s*:\s*` + amountExpr + `.*?(?:merchant|التاجر)\s*:\s*(?P<payee>[^\n]+)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "Purchase Transaction\nMerchant: AMAZON EG\nAmount: EGP 450.00"
+		name:  "purchase_multiline_merchant_first",
+		regex: regexp.MustCompile(`(?is)(?:merchant|التاجر)\s*:\s*(?P<payee>[^\n]+).*?(?:amount|المبلغ)\s*:\s*` + amountExpr),
+		payee: "Card Purchase",
+	},
+	{
+		// "Your online transfer of EGP 2,000.00 to Ahmed Ali has been completed successfully. Ref 12345";
+		// transfers to a bare account number keep the default payee
+		name:  "transfer_out",
+		regex: regexp.MustCompile(`(?i)(?:transfer(?:\s+of)?|تحويل(?:\s*مبلغ)?)\s*` + amountExpr + `(?:\s+(?:to|الى|إلى)\s+(?:(?:account|حساب)\s*\S+|(?P<payee>.*?)(?:\s+(?:has been|was|تم)\s|` + payeeEnd + `)))?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "Purchase of EGP 450.00 at AMAZON EG on card ****1234"
+		name:  "purchase",
+		regex: regexp.MustCompile(`(?i)(?:purchase|transaction)(?:\s+of|\s+with|\s+for)?\s*` + amountExpr + `.*?\bat\s+(?P<payee>.*?)(?:\s+(?:using|on card|with card)\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "Your account ****5678 has been credited with EGP 5,000.00"
+		name:   "credit",
+		regex:  regexp.MustCompile(`(?i)(?:credited(?:\s+with|\s+by)?|تم (?:ايداع|إيداع|اضافة|إضافة)(?:\s*مبلغ)?)\s*` + amountExpr),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your account ****5678 has been debited with EGP 1,000.00"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)(?:debited(?:\s+with|\s+by)?|تم خصم(?:\s*مبلغ)?)\s*` + amountExpr),
+		payee: "Transfer Out",
+	},
+}
+
+// aaibCardPattern finds the card line of a multi-line purchase notification
+var aaibCardPattern = regexp.MustCompile(`(?im)^\s*(?:card|البطاقة)\s*:.*?(\d{4})\s*$`)
+
+// parseAAIBMessage parses Arab African International Bank SMS messages. All
+// of them go to the AAIB group; the card used for a purchase is kept in
+// Transaction.CardLast4.
+func parseAAIBMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	tx.TargetGroup = "AAIB"
+	if match := aaibCardPattern.FindStringSubmatch(body); match != nil {
+		tx.CardLast4 = match[1]
+	}
+
+	if err := matchBankPatterns(&tx, body, "parseAAIBMessage", aaibPatterns); err != nil {
+		return nil, err
+	}
+
+	return appendFee(tx, body)
+}
@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseAAIBMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "aaib.yaml"), func(goldenCase) messageParser {
+		return parseAAIBMessage
+	})
+}
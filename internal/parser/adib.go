@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// adibPatterns are the ADIB Egypt message shapes, most specific first. Alerts
+// are English first, with an Arabic translation that is not matched.
+var adibPatterns = []bankPattern{
+	{
+		// "Your card ending 1234 was used for ATM withdrawal of EGP 2,000.00"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)(?:ATM|cash) withdrawal(?:\s+of)?\s*` + amountExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "Your card ending 1234 was used for EGP 250.00 at CARREFOUR on 01/01/2026"
+		name:  "purchase",
+		regex: regexp.MustCompile(`(?i)(?:used for|purchase(?:\s+of)?)\s*` + amountExpr + `.*?\bat\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Card Purchase",
+	},
+	{
+		// "A refund of EGP 250.00 from CARREFOUR was credited to your card ending 1234"
+		name:   "refund",
+		regex:  regexp.MustCompile(`(?i)refund(?:\s+of)?\s*` + amountExpr + `(?:\s+from\s+(?P<payee>.*?)(?:\s+(?:was|has)\s|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Refund",
+	},
+	{
+		// "Your account ending 5678 was credited with EGP 5,000.00"
+		name:   "credit",
+		regex:  regexp.MustCompile(`(?i)credited(?:\s+with|\s+by)?\s*` + amountExpr),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your account ending 5678 was debited with EGP 1,000.00"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)debited(?:\s+with|\s+by)?\s*` + amountExpr),
+		payee: "Transfer Out",
+	},
+}
+
+// parseADIBMessage parses Abu Dhabi Islamic Bank Egypt SMS messages
+func parseADIBMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	setCardGroup(&tx, body, "ADIB")
+
+	if err := matchBankPatterns(&tx, body, "parseADIBMessage", adibPatterns); err != nil {
+		return nil, err
+	}
+
+	return appendFee(tx, body)
+}
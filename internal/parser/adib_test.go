@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseADIBMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "adib.yaml"), func(goldenCase) messageParser {
+		return parseADIBMessage
+	})
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// alexBankPatterns are the Bank of Alexandria message shapes, most specific first
+var alexBankPatterns = []bankPattern{
+	{
+		// "تم ايداع راتبكم بمبلغ 12,000.00 جنيه مصري في حسابكم رقم ****5678" /
+		// "Your salary of EGP 12,000.00 has been credited to account ****5678"
+		name:   "salary",
+		regex:  regexp.MustCompile(`(?i)(?:راتب\S*\s*(?:بمبلغ|مبلغ)?|salary(?:\s+of)?)\s*` + amountExpr),
+		income: true,
+		payee:  "Salary",
+	},
+	{
+		// "تم خصم مبلغ 150.00 جنيه مصري من بطاقتكم رقم ****1234 لدى ZARA بتاريخ 01/01"
+		name:  "purchase_ar",
+		regex: regexp.MustCompile(`(?:تم خصم|عملية شراء)\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr + `.*?(?:لدى|عند)\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Card Purchase",
+	},
+	{
+		// "Purchase of EGP 150.00 on card ****1234 at ZARA on 01/01"
+		name:  "purchase_en",
+		regex: regexp.MustCompile(`(?i)(?:purchase|transaction)(?:\s+of|\s+with|\s+for)?\s*` + amountExpr + `.*?\bat\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Card Purchase",
+	},
+	{
+		// "تم تحويل مبلغ 2,000 جنيه من حسابكم رقم ****5678 الى محمد علي"
+		name:  "transfer_out",
+		regex: regexp.MustCompile(`(?i)(?:تم تحويل(?:\s*مبلغ)?|transfer(?:\s+of)?)\s*` + amountExpr + `\s*(?:من|from)\s*(?:حساب|your account)\S*(?:.*?(?:الى|إلى|to)\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "تم استلام تحويل بمبلغ 3,000 جنيه الى حسابكم رقم ****5678 من أحمد"
+		name:   "transfer_in",
+		regex:  regexp.MustCompile(`(?i)(?:تم (?:استلام تحويل|ايداع|إيداع|اضافة|إضافة)|credited(?:\s+with)?)\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr + `(?:.*?(?:من|from)\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		income: true,
+		payee:  "Transfer In",
+	},
+}
+
+// parseAlexBankMessage parses Bank of Alexandria SMS messages
+func parseAlexBankMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	setCardGroup(&tx, body, "AlexBank")
+
+	if err := matchBankPatterns(&tx, body, "parseAlexBankMessage", alexBankPatterns); err != nil {
+		return nil, err
+	}
+
+	return appendFee(tx, body)
+}
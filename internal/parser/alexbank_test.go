@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseAlexBankMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "alexbank.yaml"), func(goldenCase) messageParser {
+		return parseAlexBankMessage
+	})
+}
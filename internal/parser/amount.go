@@ -0,0 +1,22 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+
+	"sms-parser/internal/utils"
+)
+
+// ErrInvalidAmount marks messages that matched a pattern but whose amount
+// could not be parsed
+var ErrInvalidAmount = errors.New("amount could not be parsed")
+
+// parseAmount parses a captured amount with utils.ParseAmount, wrapping
+// failures in ErrInvalidAmount
+func parseAmount(raw string) (float64, error) {
+	amount, err := utils.ParseAmount(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+	}
+	return amount, nil
+}
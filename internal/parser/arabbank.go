@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// arabBankPatterns are the Arab Bank (Jordan) message shapes, most specific
+// first. Amounts have three decimals ("JOD 25.750"), and references are
+// alphanumeric, such as "FT2601512345".
+var arabBankPatterns = []bankPattern{
+	{
+		// "ATM withdrawal of JOD 100.000 from card ending 1234 at ARAB BANK ATM SHMEISANI"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)(?:(?:ATM|cash) withdrawal(?:\s+of)?|سحب(?:\s*نقدي)?(?:\s*(?:بمبلغ|مبلغ))?)\s*` + amountExpr + billedExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "Your card ending 1234 was used for JOD 25.750 at CARREFOUR AMMAN on 15/01/2026. Trx Ref: 601234567890" /
+		// "Purchase of USD 20.00 (JOD 14.180) at NETFLIX.COM with card ending 1234"
+		name:  "purchase",
+		regex: regexp.MustCompile(`(?i)(?:used for|purchase(?:\s+of)?|transaction(?:\s+of)?)\s*` + amountExpr + billedExpr + `.*?\bat\s+(?P<payee>.*?)(?:\s+(?:with|using)\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "عملية شراء بمبلغ 25.750 د.أ لدى CARREFOUR AMMAN بالبطاقة المنتهية بـ 1234"
+		name:  "purchase_ar",
+		regex: regexp.MustCompile(`شراء\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr + billedExpr + `.*?(?:لدى|عند|من)\s+(?P<payee>.*?)(?:\s+بالبطاق\S*\s|\s+ببطاق\S*\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "A refund of JOD 25.750 from CARREFOUR AMMAN was credited to your card ending 1234"
+		name:   "refund",
+		regex:  regexp.MustCompile(`(?i)refund(?:\s+of)?\s*` + amountExpr + billedExpr + `(?:\s+from\s+(?P<payee>.*?)(?:\s+(?:has been|was)\s|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Refund",
+	},
+	{
+		// "Your account ending 5678 has been credited with JOD 850.000 Salary. Ref: FT2601512345"
+		name:   "credit",
+		regex:  regexp.MustCompile(`(?i)(?:credited(?:\s+with|\s+by)?|تم (?:ايداع|إيداع|اضافة|إضافة)(?:\s*مبلغ)?)\s*` + amountExpr),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your account ending 5678 has been debited with JOD 200.000 transfer to AHMED ALI. Ref: FT2601512346"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)(?:debited(?:\s+with|\s+by)?|تم (?:خصم|تحويل)(?:\s*مبلغ)?)\s*` + amountExpr + `(?:.*?\btransfer\s+to\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		payee: "Transfer Out",
+	},
+}
+
+// parseArabBankMessage parses Arab Bank SMS messages. Amounts without a
+// currency are in JOD.
+func parseArabBankMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	setCardGroup(&tx, body, "ArabBank")
+
+	if err := matchBankPatterns(&tx, body, "parseArabBankMessage", arabBankPatterns); err != nil {
+		return nil, err
+	}
+	setLocalCurrency(&tx, body, "JOD")
+	extractReference(&tx, body)
+
+	return appendFee(tx, body)
+}
@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseArabBankMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "arabbank.yaml"), func(goldenCase) messageParser {
+		return parseArabBankMessage
+	})
+}
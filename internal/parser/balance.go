@@ -0,0 +1,28 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// balancePattern matches the running balance in English and Arabic messages,
+// e.g. "Available balance is EGP 12,345.67" or "رصيدك الحالي 1,234.50 جنيه"
+var balancePattern = regexp.MustCompile(`(?i)(?:available balance|current balance|avl\.? bal(?:ance)?|avail(?:able)?\.? bal\.?|الرصيد المتاح|رصيدك المتاح|رصيدكم المتاح|الرصيد الحالي|رصيدك الحالي|رصيدكم الحالي|your (?:telda )?balance|new balance|m-pesa balance|wallet balance|رصيد المحفظة|رصيدك)\s*(?:is|هو)?\s*:?\s*(?:[A-Za-z]{3}|SR|L\.E\.?|ج\.م|جنيه|جم|ر\.س|ريال|د\.إ|درهم|د\.ك|د\.أ|د\.ا)?\s*(-?[\d,]+(?:\.\d{1,3})?)`)
+
+// extractBalance records the available balance reported in a message, if any
+func extractBalance(tx *models.Transaction, body string) {
+	match := balancePattern.FindStringSubmatch(body)
+	if len(match) < 2 {
+		return
+	}
+
+	balance, err := utils.ParseAmount(match[1])
+	if err != nil {
+		return
+	}
+
+	tx.Balance = balance
+	tx.HasBalance = true
+}
@@ -0,0 +1,121 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// amountExpr matches an amount with an optional currency before or after it,
+// in the named groups amount, currency, and currency2
+const amountExpr = `(?:(?P<currency>(?-i:[A-Z]{3}|SR\b)|L\.E\.?|ج\.م|جنيه|جم|ر\.س|ريال|د\.إ|درهم|د\.ك|د\.أ|د\.ا)\s*)?(?P<amount>[\d,]+(?:\.\d{1,3})?)(?:\s*(?P<currency2>(?-i:[A-Z]{3}|SR)\b|L\.E\.?|ج\.م|جنيه|جم|ر\.س|ريال(?:\s*سعودي)?|د\.إ|درهم|د\.ك|د\.أ|د\.ا))?`
+
+// billedExpr follows amountExpr in foreign-currency messages, with the amount
+// billed in the account's currency in brackets or after "equivalent to":
+// "AED 120.00 (EGP 1,650.00)". It has the named groups billed_currency and billed.
+const billedExpr = `(?:\s*(?:\(|equivalent to|بما يعادل|ما يعادل)\s*(?:(?P<billed_currency>(?-i:[A-Z]{3}|SR\b)|L\.E\.?|ج\.م|جنيه|جم|ر\.س|ريال|د\.إ|درهم|د\.ك|د\.أ|د\.ا)\s*)?(?P<billed>[\d,]+(?:\.\d{1,3})?)(?:\s*(?:جنيه|ج\.م|جم|ر\.س|ريال|د\.إ|درهم|د\.ك|د\.أ|د\.ا))?\s*\)?)?`
+
+// payeeEnd ends a lazily captured payee: a date or time marker, or the end of
+// a sentence
+const payeeEnd = `(?:\s+(?:on|at|في|بتاريخ|يوم|Ref|ref|رقم العملية|رصيد\S*)\s|[.،]\s|[.،]?$)`
+
+// cardPattern finds the last four digits of a masked card or account number
+var cardPattern = regexp.MustCompile(`(?i)(?:\*+|x{2,}|#|ending(?:\s+with|\s+in)?|المنتهية\s*(?:بـ|ب)?|رقم)\s*(\d{4})\b`)
+
+// bankPattern is one message shape of a bank. The regex has a named amount
+// group (see amountExpr) and an optional payee group.
+type bankPattern struct {
+	name  string
+	regex *regexp.Regexp
+	// income marks credits; every other pattern is an expense
+	income bool
+	// payee is used when the regex captures no payee
+	payee string
+}
+
+// matchBankPatterns fills tx from the first of patterns that matches body,
+// recording "function:name" as its source. When the pattern captured a billed
+// amount (see billedExpr), that amount is booked and the matched one is kept as
+// the original. Without a match tx is unchanged.
+func matchBankPatterns(tx *models.Transaction, body, function string, patterns []bankPattern) error {
+	for _, pattern := range patterns {
+		match := pattern.regex.FindStringSubmatch(body)
+		if match == nil {
+			continue
+		}
+		group := func(name string) string {
+			if i := pattern.regex.SubexpIndex(name); i >= 0 {
+				return match[i]
+			}
+			return ""
+		}
+
+		amount, err := parseAmount(group("amount"))
+		if err != nil {
+			return err
+		}
+
+		tx.Source = function + ":" + pattern.name
+		tx.Currency = utils.NormalizeCurrency(firstNonEmpty(group("currency"), group("currency2")))
+		tx.Payee = pattern.payee
+		if payee := utils.CleanPayeeName(strings.TrimSpace(group("payee"))); payee != "" {
+			tx.Payee = payee
+		}
+
+		sign := -1.0
+		if pattern.income {
+			tx.Type = models.TypeIncome
+			sign = 1
+		}
+
+		if billed := group("billed"); billed != "" {
+			billedAmount, err := parseAmount(billed)
+			if err != nil {
+				return err
+			}
+			tx.OriginalCurrency = tx.Currency
+			tx.OriginalAmount = sign * amount
+			tx.Currency = utils.NormalizeCurrency(group("billed_currency"))
+			amount = billedAmount
+		}
+
+		tx.Amount = sign * amount
+		return nil
+	}
+
+	return nil
+}
+
+// cardDigits returns the last four digits of the masked card or account
+// number a message names, or ""
+func cardDigits(body string) string {
+	if match := cardPattern.FindStringSubmatch(body); len(match) > 1 {
+		return match[1]
+	}
+	return ""
+}
+
+// setCardGroup groups a message by the card or account it names, as
+// "<prefix>_Card_XXXX", falling back to prefix alone
+func setCardGroup(tx *models.Transaction, body, prefix string) {
+	if digits := cardDigits(body); digits != "" {
+		tx.TargetGroup = fmt.Sprintf("%s_Card_%s", prefix, digits)
+		tx.CardLast4 = digits
+		return
+	}
+	tx.TargetGroup = prefix
+}
+
+// egpPattern finds an explicit mention of Egyptian pounds
+var egpPattern = regexp.MustCompile(`(?i)\bEGP\b|L\.E|جنيه|ج\.م`)
+
+// setLocalCurrency books messages that name no currency in the bank's own
+// currency rather than the EGP default, for banks outside Egypt
+func setLocalCurrency(tx *models.Transaction, body, currency string) {
+	if tx.Currency == "EGP" && !egpPattern.MatchString(body) {
+		tx.Currency = currency
+	}
+}
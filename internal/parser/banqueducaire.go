@@ -0,0 +1,69 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// bdcPatterns are the Banque du Caire message shapes, most specific first
+var bdcPatterns = []bankPattern{
+	{
+		// "تم سحب مبلغ 1,500.00 جنيه من بطاقتكم رقم ****1234 من ماكينة ATM" /
+		// "ATM withdrawal of EGP 1,500.00 from card ****1234"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)(?:(?:تم\s*)?سحب(?:\s*نقدي)?(?:\s*(?:بمبلغ|مبلغ))?|(?:ATM|cash) withdrawal(?:\s+of)?)\s*` + amountExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "تم خصم مبلغ 320.00 جنيه من بطاقتكم رقم ****1234 لدى METRO في 01/01"
+		name:  "purchase_ar",
+		regex: regexp.MustCompile(`(?:تم خصم|عملية شراء)\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr + `.*?(?:لدى|عند)\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Card Purchase",
+	},
+	{
+		// "Purchase of EGP 320.00 with card ****1234 at METRO on 01/01"
+		name:  "purchase_en",
+		regex: regexp.MustCompile(`(?i)(?:purchase|transaction)(?:\s+of|\s+with|\s+for)?\s*` + amountExpr + `.*?\bat\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Card Purchase",
+	},
+	{
+		// "تم تحويل مبلغ 1,000 جنيه من حسابكم رقم ****5678 الى أحمد"
+		name:  "transfer_out",
+		regex: regexp.MustCompile(`(?i)(?:تم تحويل(?:\s*مبلغ)?|transfer(?:\s+of)?)\s*` + amountExpr + `\s*(?:من|from)\s*(?:حساب|your account)\S*(?:.*?(?:الى|إلى|to)\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "تم اضافة مبلغ 5,000 جنيه الى حسابكم رقم ****5678 من محمد" /
+		// "Your account ****5678 has been credited with EGP 5,000.00"
+		name:   "transfer_in",
+		regex:  regexp.MustCompile(`(?i)(?:تم (?:اضافة|إضافة|ايداع|إيداع|استلام تحويل)|credited(?:\s+with|\s+by)?)\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr + `(?:.*?(?:من|from)\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your account ****5678 has been debited with EGP 1,000.00"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)debited(?:\s+with|\s+by)?\s*` + amountExpr),
+		payee: "Transfer Out",
+	},
+}
+
+// parseBDCMessage parses Banque du Caire SMS messages
+func parseBDCMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	setCardGroup(&tx, body, "BDC")
+
+	if err := matchBankPatterns(&tx, body, "parseBDCMessage", bdcPatterns); err != nil {
+		return nil, err
+	}
+
+	return appendFee(tx, body)
+}
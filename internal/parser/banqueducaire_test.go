@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseBDCMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "banqueducaire.yaml"), func(goldenCase) messageParser {
+		return parseBDCMessage
+	})
+}
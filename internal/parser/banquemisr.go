@@ -3,7 +3,6 @@ package parser
 import (
 	"fmt"
 	"regexp"
-	"strconv"
 	"strings"
 
 	"sms-parser/internal/models"
@@ -11,14 +10,28 @@ import (
 )
 
 // parseBanqueMisrMessage parses Banque Misr bank SMS messages
-func parseBanqueMisrMessage(tx *models.Transaction, body string) {
+func parseBanqueMisrMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	// Amounts may be written in Eastern Arabic digits
+	body = utils.NormalizeDigits(body)
+
 	// Skip OTP and login messages
-	skipWords := []string{"OTP", "password", "تسجيل الدخول", "code"}
-	for _, word := range skipWords {
-		if strings.Contains(body, word) {
-			tx.TargetGroup = ""
-			return
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	// Payroll Meeza cards issued by the bank get their own group
+	if meezaMention.MatchString(body) {
+		return parseMeezaMessage(tx, body)
+	}
+
+	extractBalance(&tx, body)
+
+	// Credit cards get a group per card, like CIB's
+	if banqueMisrCreditMention.MatchString(body) {
+		if err := parseBanqueMisrCreditCard(&tx, body); err != nil {
+			return nil, err
 		}
+		return appendFee(tx, body)
 	}
 
 	// Extract card number from the message
@@ -29,25 +42,36 @@ func parseBanqueMisrMessage(tx *models.Transaction, body string) {
 	if len(cardMatch) > 1 {
 		cardDigits := cardMatch[1]
 		tx.TargetGroup = fmt.Sprintf("Banque_Misr_Card_%s", cardDigits)
+		tx.CardLast4 = cardDigits
 	} else {
 		// Fallback for messages without card number
 		tx.TargetGroup = "Banque_Misr"
 	}
 
+	var err error
 	if strings.Contains(body, "تم تحويل مبلغ") || strings.Contains(body, "تم اضافة مبلغ") {
-		parseTransfer(tx, body)
+		err = parseTransfer(&tx, body)
 	} else if strings.Contains(body, "تم الخصم") || strings.Contains(body, "transaction") {
-		parsePurchase(tx, body)
+		err = parsePurchase(&tx, body)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	return appendFee(tx, body)
 }
 
 // parseTransfer handles Banque Misr transfer transactions
-func parseTransfer(tx *models.Transaction, body string) {
-	pattern := regexp.MustCompile(`مبلغ\s*(?:([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)\s*)?([\d,]+)(?:\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم))?`)
+func parseTransfer(tx *models.Transaction, body string) error {
+	pattern := regexp.MustCompile(`مبلغ\s*(?:([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)\s*)?([\d,]+(?:\.\d{1,2})?)(?:\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم))?`)
 	match := pattern.FindStringSubmatch(body)
 
 	if len(match) > 2 {
-		val, _ := strconv.ParseFloat(strings.ReplaceAll(match[2], ",", ""), 64)
+		tx.Source = "parseTransfer:transfer"
+		val, err := parseAmount(match[2])
+		if err != nil {
+			return err
+		}
 		detectedCurr := match[1]
 		if detectedCurr == "" {
 			detectedCurr = match[3]
@@ -63,16 +87,22 @@ func parseTransfer(tx *models.Transaction, body string) {
 			tx.Payee = "Transfer In"
 		}
 	}
+
+	return nil
 }
 
 // parsePurchase handles Banque Misr purchase transactions
-func parsePurchase(tx *models.Transaction, body string) {
-	pattern := regexp.MustCompile(`(?:مبلغ|amount)\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*([\d,]+\.\d{2})`)
+func parsePurchase(tx *models.Transaction, body string) error {
+	pattern := regexp.MustCompile(`(?:مبلغ|amount)\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*([\d,]+(?:\.\d{1,2})?)`)
 	match := pattern.FindStringSubmatch(body)
 
 	if len(match) > 2 {
+		tx.Source = "parsePurchase:purchase"
 		tx.Currency = utils.NormalizeCurrency(match[1])
-		amount, _ := strconv.ParseFloat(strings.ReplaceAll(match[2], ",", ""), 64)
+		amount, err := parseAmount(match[2])
+		if err != nil {
+			return err
+		}
 		tx.Amount = -amount
 		tx.Payee = "Card Purchase"
 
@@ -82,4 +112,6 @@ func parsePurchase(tx *models.Transaction, body string) {
 			tx.Payee = strings.TrimSpace(tailMatch[1])
 		}
 	}
+
+	return nil
 }
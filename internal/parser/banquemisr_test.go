@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseBanqueMisrMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "banquemisr.yaml"), func(goldenCase) messageParser {
+		return parseBanqueMisrMessage
+	})
+}
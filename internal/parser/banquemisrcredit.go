@@ -0,0 +1,66 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// banqueMisrCreditMention finds Banque Misr credit card alerts
+var banqueMisrCreditMention = regexp.MustCompile(`(?i)credit card|بطاقة\s*(?:ال)?ائتمان|الائتمانية`)
+
+// banqueMisrCreditPatterns are the Banque Misr credit card message shapes,
+// most specific first. Charges abroad may add the amount billed in EGP (see
+// billedExpr).
+var banqueMisrCreditPatterns = []bankPattern{
+	{
+		// "A payment of EGP 5,000.00 has been received for your credit card ****1234" /
+		// "تم استلام سداد مبلغ 5,000.00 جنيه لبطاقة الائتمان ****1234"
+		name:   "repayment",
+		regex:  regexp.MustCompile(`(?i)(?:payment(?:\s+of)?|(?:تم\s*)?(?:استلام\s*)?سداد(?:\s*مبلغ)?)\s*` + amountExpr),
+		income: true,
+		payee:  "Banque Misr Repayment",
+	},
+	{
+		// "A refund of EGP 250.00 from AMAZON has been credited to your credit card ****1234" /
+		// "تم رد مبلغ 250 جنيه لبطاقة الائتمان ****1234 من AMAZON"
+		name:   "refund",
+		regex:  regexp.MustCompile(`(?i)(?:refund(?:\s+of)?|تم رد(?:\s*مبلغ)?)\s*` + amountExpr + `(?:.*?(?:\bfrom|\sمن)\s+(?P<payee>.*?)(?:\s+(?:has been|was)\s|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Refund",
+	},
+	{
+		// "Your Banque Misr credit card ****1234 was charged EGP 1,250.00 at AMAZON on 15/01/2026" /
+		// "Purchase of USD 20.00 (EGP 985.00) at NETFLIX.COM with credit card ****1234"
+		name:  "charge",
+		regex: regexp.MustCompile(`(?i)(?:charged(?:\s+with|\s+for)?|purchase(?:\s+of)?|used for)\s*` + amountExpr + billedExpr + `.*?\bat\s+(?P<payee>.*?)(?:\s+(?:with|using)\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "تم خصم مبلغ 1,250.00 جنيه من بطاقة الائتمان ****1234 لدى AMAZON يوم 15/01/2026"
+		name:  "charge_ar",
+		regex: regexp.MustCompile(`(?:تم الخصم|تم خصم|شراء)\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr + billedExpr + `.*?(?:لدى|عند)\s+(?P<payee>.*?)(?:\s+ببطاق\S*\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+}
+
+// parseBanqueMisrCreditCard handles Banque Misr credit card purchases,
+// refunds, and repayments, grouped per card as
+// Banque_Misr_Credit_Card_XXXX. Statement summaries return errStatement.
+func parseBanqueMisrCreditCard(tx *models.Transaction, body string) error {
+	lower := strings.ToLower(body)
+	if utils.Contains(lower, "statement", "كشف حساب") && utils.Contains(lower, "due", "مستحق", "السداد") {
+		return errStatement
+	}
+
+	tx.TargetGroup = "Banque_Misr_Credit_Card"
+	if digits := cardDigits(body); digits != "" {
+		tx.TargetGroup = fmt.Sprintf("Banque_Misr_Credit_Card_%s", digits)
+		tx.CardLast4 = digits
+	}
+
+	return matchBankPatterns(tx, body, "parseBanqueMisrCreditCard", banqueMisrCreditPatterns)
+}
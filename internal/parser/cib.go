@@ -3,57 +3,217 @@ package parser
 import (
 	"fmt"
 	"regexp"
-	"strconv"
+	"slices"
 	"strings"
 
 	"sms-parser/internal/models"
 	"sms-parser/internal/utils"
 )
 
-// parseCIBMessage parses CIB bank SMS messages
-func parseCIBMessage(tx *models.Transaction, body string) {
-	// Detect credit card
-	ccPattern := regexp.MustCompile(`(?i)(?:credit card|ending with|card|بـ)\s*[#*]*\s*(\d{4})`)
-	ccMatch := ccPattern.FindStringSubmatch(body)
+// cibCurrency matches the currency written before a CIB amount
+const cibCurrency = `([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)`
 
-	isCreditCard := false
-	cardDigits := "Unknown"
+// cibAmount matches a CIB amount, with or without decimals
+const cibAmount = `([\d,]+(?:\.\d{1,2})?)`
 
-	if len(ccMatch) > 1 {
-		cardDigits = ccMatch[1]
-		// If it's not the Debit Card (7759) and not the Account (2373)
-		if cardDigits != "7759" && cardDigits != "2373" {
-			isCreditCard = true
-			tx.TargetGroup = fmt.Sprintf("CIB_Credit_Card_%s", cardDigits)
+// CIB message shapes and the card and account markers they use
+var (
+	// cibCardPattern finds the digits after a card marker, such as "card
+	// ending with 1234", "بـ 1234", or "XXXX1234"
+	cibCardPattern = regexp.MustCompile(`(?i)(?:\bcredit card|\bending(?:\s+with|\s+in)?|\bcard|بـ|\bx{4})\s*[#*x]*\s*(\d{4})`)
+	// cibAccountPattern finds the digits after an account marker, such as
+	// "account 1234" or "حسابكم رقم 1234"
+	cibAccountPattern = regexp.MustCompile(`(?i)(?:\baccount|حساب\S*)\s*(?:no\.?|number|رقم)?\s*[#*x]*\s*(\d{4})`)
+
+	cibChargePattern      = regexp.MustCompile(`(?i)charged for\s*` + cibCurrency + `?\s*` + cibAmount + `\s*(?:\(\s*` + cibCurrency + `?\s*` + cibAmount + `\s*\)\s*)?at\s*(.*?)(?:\s+on|\s+at|\. Available)`)
+	cibRefundPattern      = regexp.MustCompile(`(?i)(?:refunded|red|rd|رد)\s*` + cibCurrency + `?\s*` + cibAmount)
+	cibRepaymentPattern   = regexp.MustCompile(`مبلغ\s*` + cibAmount)
+	cibPendingPattern     = regexp.MustCompile(`(?i)(?:authori[sz]ation(?:\s+(?:of|for))?|pending(?:\s+(?:transaction|charge))?(?:\s+(?:of|for))?|تم حجز(?:\s*مبلغ)?)\s*` + cibCurrency + `?\s*` + cibAmount)
+	cibPendingPayee       = regexp.MustCompile(`(?i)(?:\bat|لدى|عند)\s+(.*?)(?:\s+(?:on|is|has|على|في|بتاريخ)\s|[.،]\s|[.،]?$)`)
+	cibInstallmentPattern = regexp.MustCompile(`(?i)(?:مبلغ|amount(?:\s+of)?|of)\s*` + cibCurrency + `?\s*` + cibAmount)
+	cibInstallmentPayee   = regexp.MustCompile(`(?i)(?:\bat|لدى|عند)\s+(.*?)(?:\s+(?:على|over|into|was|has|on|في)\s|[.،]\s|[.،]?$)`)
+	cibInstallmentMonths  = regexp.MustCompile(`(?i)(\d+)\s*(?:شهور|شهرا|شهر|أشهر|اشهر|months?|monthly)`)
+	cibDebitArPattern     = regexp.MustCompile(`خصم\s*(?:مبلغ\s*)?` + cibCurrency + `?\s*` + cibAmount + `\s*` + cibCurrency + `?\s*من.*?عند\s*(.*?)(\s+في|$)`)
+	cibDebitEnPattern     = regexp.MustCompile(`(?i)charged for\s*` + cibCurrency + `?\s*` + cibAmount + `\s*at\s*(.*?)(?:\s+on|\s+at)`)
+	cibWithdrawalPattern  = regexp.MustCompile(`سحب\s*(?:مبلغ)?\s*` + cibCurrency + `?\s*` + cibAmount)
+	cibAccountDebit       = regexp.MustCompile(`(?i)(?:amount|for)\s*` + cibCurrency + `?\s*` + cibAmount)
+	cibTransferOutPayee   = regexp.MustCompile(`to\s+(.*?)\s+with reference`)
+	cibIPNPattern         = regexp.MustCompile(`(?i)credited with IPN Inward for\s*` + cibCurrency + `?\s*` + cibAmount)
+	cibSalaryPattern      = regexp.MustCompile(`تحويل مبلغ\s*` + cibCurrency + `?` + cibAmount + `.*?جهة العمل`)
+	cibTransferInPayee    = regexp.MustCompile(`from\s+(.*?)\s+with reference`)
+	cibReferencePattern   = regexp.MustCompile(`(?i)with reference\s*(?:number|no\.?)?\s*:?\s*([A-Za-z0-9-]+)`)
+)
+
+// cibParser parses CIB bank SMS messages. Cards and accounts are told apart
+// by their last four digits: the configured debit cards and accounts go to
+// the current account group and every other card is a credit card.
+type cibParser struct {
+	cards      map[string]CIBCard
+	debitCards []string
+	accounts   []string
+}
+
+// newCIBParser creates the CIB message parser for the given cards and
+// accounts, keyed by their last four digits; with none configured, every
+// card is treated as a credit card and "account XXXX" messages as the
+// current account
+func newCIBParser(cards map[string]CIBCard) messageParser {
+	c := &cibParser{
+		cards:      cards,
+		debitCards: cardsOfType(cards, CIBDebit),
+		accounts:   cardsOfType(cards, CIBCurrent),
+	}
+	return c.parse
+}
+
+// parse parses a single CIB message
+func (c *cibParser) parse(tx models.Transaction, body string) ([]models.Transaction, error) {
+	// Amounts may be written in Eastern Arabic digits
+	body = utils.NormalizeDigits(body)
+
+	extractBalance(&tx, body)
+
+	// Smart Wallet messages come from the same sender
+	if cibSmartWalletMention.MatchString(body) {
+		if err := parseCIBSmartWallet(&tx, body); err != nil {
+			return nil, err
+		}
+		if tx.Source != "" {
+			return appendFee(tx, body)
 		}
 	}
 
-	if isCreditCard {
-		parseCIBCreditCard(tx, body)
-	} else if strings.Contains(body, "7759") || strings.Contains(body, "2373") {
-		parseCIBDebit(tx, body)
+	// Cards and accounts are only recognized after a marker, so digits
+	// inside an amount or reference are never taken for them
+	cards := markedDigits(cibCardPattern, body)
+	accountDigits := markedDigits(cibAccountPattern, body)
+
+	cardDigits := ""
+	if len(cards) > 0 {
+		cardDigits = cards[0]
+	}
+
+	debitCard := firstOf(cards, c.debitCards)
+	account := c.matchedAccount(cards, accountDigits)
+
+	var err error
+	switch {
+	case cardDigits != "" && !slices.Contains(c.debitCards, cardDigits) && !slices.Contains(c.accounts, cardDigits):
+		tx.TargetGroup = fmt.Sprintf("CIB_Credit_Card_%s", cardDigits)
+		tx.CardLast4 = cardDigits
+		credit := tx
+		if err = parseCIBCreditCard(&credit, body); err != nil {
+			break
+		}
+		// Debit card purchases from unconfigured cards look like credit
+		// card messages until the credit patterns fail to match
+		debit := tx
+		if credit.Source == "" && parseCIBDebit(&debit, body) == nil && debit.Source != "" {
+			credit = debit
+		}
+		tx = credit
+	case debitCard != "":
+		tx.CardLast4 = debitCard
+		err = parseCIBDebit(&tx, body)
+	case account != "":
+		tx.CardLast4 = account
+		err = parseCIBCurrentAccount(&tx, body)
+	}
+	if err != nil {
+		return nil, err
 	}
+	if card, ok := c.cards[tx.CardLast4]; ok && card.Name != "" && tx.TargetGroup != "" {
+		tx.TargetGroup = card.Name
+	}
+
+	// Authorizations are reported again once the charge settles
+	if tx.TargetGroup != "" && utils.Contains(strings.ToLower(body), "pending", "authorization", "authorisation", "تم حجز") {
+		if err := parseCIBPending(&tx, body); err != nil {
+			return nil, err
+		}
+	}
+
+	return appendFee(tx, body)
+}
+
+// matchedAccount returns the current account a message is about: one of the
+// configured accounts named after a card or account marker, or the first
+// "account XXXX" if none are configured. It returns "" for other messages.
+func (c *cibParser) matchedAccount(cards, accounts []string) string {
+	if len(c.accounts) > 0 {
+		return firstOf(slices.Concat(accounts, cards), c.accounts)
+	}
+
+	if len(accounts) > 0 {
+		return accounts[0]
+	}
+	return ""
+}
+
+// markedDigits returns the digits captured by every match of pattern in body
+func markedDigits(pattern *regexp.Regexp, body string) []string {
+	var digits []string
+	for _, match := range pattern.FindAllStringSubmatch(body, -1) {
+		digits = append(digits, match[1])
+	}
+	return digits
+}
+
+// firstOf returns the first of found that is one of values, or ""
+func firstOf(found, values []string) string {
+	for _, digits := range found {
+		if slices.Contains(values, digits) {
+			return digits
+		}
+	}
+	return ""
 }
 
 // parseCIBCreditCard handles CIB credit card transactions
-func parseCIBCreditCard(tx *models.Transaction, body string) {
+func parseCIBCreditCard(tx *models.Transaction, body string) error {
+	// Installment conversions repeat an earlier charge, so they are tagged
+	// rather than parsed as a new purchase
+	if utils.Contains(strings.ToLower(body), "installment", "قسط", "أقساط", "اقساط", "تقسيط") {
+		return parseCIBInstallment(tx, body)
+	}
+
 	if strings.Contains(body, "charged for") || strings.Contains(body, "purchasing transaction") {
-		pattern := regexp.MustCompile(`(?i)charged for\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*([\d,]+\.\d{2})\s*at\s*(.*?)(?:\s+on|\s+at|\. Available)`)
-		match := pattern.FindStringSubmatch(body)
-		if len(match) > 3 {
+		// Foreign-currency charges add the billed amount in brackets:
+		// "charged for USD 20.00 (EGP 985.00) at ..."
+		match := cibChargePattern.FindStringSubmatch(body)
+		if len(match) > 5 {
+			tx.Source = "parseCIBCreditCard:charge"
 			tx.Currency = utils.NormalizeCurrency(match[1])
-			amount, _ := strconv.ParseFloat(strings.ReplaceAll(match[2], ",", ""), 64)
+			amount, err := parseAmount(match[2])
+			if err != nil {
+				return err
+			}
 			tx.Amount = -amount
-			tx.Payee = utils.CleanPayeeName(strings.TrimSpace(match[3]))
+			tx.Payee = utils.CleanPayeeName(strings.TrimSpace(match[5]))
+
+			if match[4] != "" {
+				// Book the billed amount and keep the foreign one as the original
+				tx.OriginalCurrency = tx.Currency
+				tx.OriginalAmount = tx.Amount
+				tx.Currency = utils.NormalizeCurrency(match[3])
+				billed, err := parseAmount(match[4])
+				if err != nil {
+					return err
+				}
+				tx.Amount = -billed
+			}
 		}
 	} else if strings.Contains(body, "refunded") || strings.Contains(body, "rad") || strings.Contains(body, "رد") {
 		if !strings.Contains(body, "تم سداد") {
 			tx.Type = models.TypeIncome
-			pattern := regexp.MustCompile(`(?i)(?:refunded|red|rd|رد)\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*([\d,]+\.\d{2})`)
-			match := pattern.FindStringSubmatch(body)
+			match := cibRefundPattern.FindStringSubmatch(body)
 			if len(match) > 2 {
+				tx.Source = "parseCIBCreditCard:refund"
 				tx.Currency = utils.NormalizeCurrency(match[1])
-				amount, _ := strconv.ParseFloat(strings.ReplaceAll(match[2], ",", ""), 64)
+				amount, err := parseAmount(match[2])
+				if err != nil {
+					return err
+				}
 				tx.Amount = amount
 				tx.Payee = "Refund"
 			}
@@ -63,72 +223,149 @@ func parseCIBCreditCard(tx *models.Transaction, body string) {
 	if strings.Contains(body, "تم سداد") || (strings.Contains(body, "payment") && strings.Contains(body, "received")) {
 		tx.Type = models.TypeIncome
 		tx.Payee = "CIB Repayment"
-		pattern := regexp.MustCompile(`مبلغ\s*([\d,]+\.\d{2})`)
-		match := pattern.FindStringSubmatch(body)
+		match := cibRepaymentPattern.FindStringSubmatch(body)
 		if len(match) > 1 {
-			amount, _ := strconv.ParseFloat(strings.ReplaceAll(match[1], ",", ""), 64)
+			tx.Source = "parseCIBCreditCard:repayment"
+			amount, err := parseAmount(match[1])
+			if err != nil {
+				return err
+			}
 			tx.Amount = amount
 		}
 	}
+
+	return nil
+}
+
+// parseCIBPending handles pending card authorizations, e.g. "An authorization
+// of EGP 100.00 at KFC is pending" / "تم حجز مبلغ 100 جنيه لدى KFC"
+func parseCIBPending(tx *models.Transaction, body string) error {
+	tx.Pending = true
+	tx.Note = "[Pending] " + tx.Note
+
+	match := cibPendingPattern.FindStringSubmatch(body)
+	if len(match) > 2 {
+		tx.Source = "parseCIBPending:authorization"
+		tx.Type = models.TypeExpense
+		tx.Currency = utils.NormalizeCurrency(match[1])
+		amount, err := parseAmount(match[2])
+		if err != nil {
+			return err
+		}
+		tx.Amount = -amount
+	}
+
+	if payeeMatch := cibPendingPayee.FindStringSubmatch(body); len(payeeMatch) > 1 {
+		if payee := utils.CleanPayeeName(strings.TrimSpace(payeeMatch[1])); payee != "" {
+			tx.Payee = payee
+		}
+	}
+
+	return nil
+}
+
+// parseCIBInstallment handles messages about converting a credit card
+// purchase to installments, e.g. "تم تقسيط مبلغ 12,000 جنيه على 12 شهر"
+func parseCIBInstallment(tx *models.Transaction, body string) error {
+	tx.Tags = append(tx.Tags, models.TagInstallment)
+	tx.Payee = "Installment Plan"
+
+	match := cibInstallmentPattern.FindStringSubmatch(body)
+	if len(match) > 2 {
+		tx.Source = "parseCIBCreditCard:installment"
+		tx.Currency = utils.NormalizeCurrency(match[1])
+		amount, err := parseAmount(match[2])
+		if err != nil {
+			return err
+		}
+		tx.Amount = -amount
+	}
+
+	if payeeMatch := cibInstallmentPayee.FindStringSubmatch(body); len(payeeMatch) > 1 {
+		if payee := utils.CleanPayeeName(strings.TrimSpace(payeeMatch[1])); payee != "" {
+			tx.Payee = payee
+		}
+	}
+
+	if monthsMatch := cibInstallmentMonths.FindStringSubmatch(body); len(monthsMatch) > 1 {
+		tx.Note = fmt.Sprintf("[Installment: %s months] %s", monthsMatch[1], tx.Note)
+	} else {
+		tx.Note = "[Installment] " + tx.Note
+	}
+
+	return nil
 }
 
-// parseCIBDebit handles CIB debit card and current account transactions
-func parseCIBDebit(tx *models.Transaction, body string) {
+// parseCIBDebit handles CIB debit card transactions, which are booked on the
+// current account
+func parseCIBDebit(tx *models.Transaction, body string) error {
 	tx.TargetGroup = "CIB_Current_Debit"
 
-	if strings.Contains(body, "7759") &&
-		(strings.Contains(body, "charged for") || strings.Contains(body, "خصم") ||
-			strings.Contains(body, "withdrawal") || strings.Contains(body, "سحب")) {
+	if strings.Contains(body, "charged for") || strings.Contains(body, "خصم") ||
+		strings.Contains(body, "withdrawal") || strings.Contains(body, "سحب") {
 
-		// Arabic pattern
-		patternAr := regexp.MustCompile(`خصم\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*([\d,]+\.\d{2})\s*من.*?عند\s*(.*?)(\s+في|$)`)
-		matchAr := patternAr.FindStringSubmatch(body)
+		matchAr := cibDebitArPattern.FindStringSubmatch(body)
 
-		// English pattern
-		patternEn := regexp.MustCompile(`(?i)charged for\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*([\d,]+\.\d{2})\s*at\s*(.*?)(?:\s+on|\s+at)`)
-		matchEn := patternEn.FindStringSubmatch(body)
+		matchEn := cibDebitEnPattern.FindStringSubmatch(body)
 
-		// Withdrawal pattern
-		patternWith := regexp.MustCompile(`سحب\s*(?:مبلغ)?\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*([\d,]+\.\d{2})`)
-		matchWith := patternWith.FindStringSubmatch(body)
+		matchWith := cibWithdrawalPattern.FindStringSubmatch(body)
 
-		if len(matchAr) > 3 {
-			tx.Currency = utils.NormalizeCurrency(matchAr[1])
-			amount, _ := strconv.ParseFloat(strings.ReplaceAll(matchAr[2], ",", ""), 64)
+		if len(matchAr) > 4 {
+			// The currency comes before or after the amount: "خصم EGP 250.00"
+			// or "خصم 250.00 جنيه"
+			tx.Source = "parseCIBDebit:arabic"
+			tx.Currency = utils.NormalizeCurrency(firstNonEmpty(matchAr[1], matchAr[3]))
+			amount, err := parseAmount(matchAr[2])
+			if err != nil {
+				return err
+			}
 			tx.Amount = -amount
-			tx.Payee = utils.CleanPayeeName(strings.TrimSpace(matchAr[3]))
+			tx.Payee = utils.CleanPayeeName(strings.TrimSpace(matchAr[4]))
 		} else if len(matchEn) > 3 {
+			tx.Source = "parseCIBDebit:english"
 			tx.Currency = utils.NormalizeCurrency(matchEn[1])
-			amount, _ := strconv.ParseFloat(strings.ReplaceAll(matchEn[2], ",", ""), 64)
+			amount, err := parseAmount(matchEn[2])
+			if err != nil {
+				return err
+			}
 			tx.Amount = -amount
 			tx.Payee = utils.CleanPayeeName(strings.TrimSpace(matchEn[3]))
 		} else if len(matchWith) > 2 {
+			tx.Source = "parseCIBDebit:withdrawal"
 			tx.Currency = utils.NormalizeCurrency(matchWith[1])
-			amount, _ := strconv.ParseFloat(strings.ReplaceAll(matchWith[2], ",", ""), 64)
+			amount, err := parseAmount(matchWith[2])
+			if err != nil {
+				return err
+			}
 			tx.Amount = -amount
 			tx.Payee = "ATM Withdrawal"
 		}
-	} else if strings.Contains(body, "2373") {
-		parseCIBCurrentAccount(tx, body)
 	}
+
+	return nil
 }
 
 // parseCIBCurrentAccount handles CIB current account transactions
-func parseCIBCurrentAccount(tx *models.Transaction, body string) {
+func parseCIBCurrentAccount(tx *models.Transaction, body string) error {
+	tx.TargetGroup = "CIB_Current_Debit"
+
 	if strings.Contains(body, "debited") || strings.Contains(body, "charged with") || strings.Contains(body, "تم تحويل") {
-		pattern := regexp.MustCompile(`(?i)(?:amount|for)\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*([\d,]+\.\d{2})`)
-		match := pattern.FindStringSubmatch(body)
+		match := cibAccountDebit.FindStringSubmatch(body)
 		if len(match) > 2 {
+			tx.Source = "parseCIBCurrentAccount:debit"
 			tx.Currency = utils.NormalizeCurrency(match[1])
-			amount, _ := strconv.ParseFloat(strings.ReplaceAll(match[2], ",", ""), 64)
+			amount, err := parseAmount(match[2])
+			if err != nil {
+				return err
+			}
 			tx.Amount = -amount
+			parseCIBReference(tx, body)
 
 			if strings.Contains(body, "transfer to another account") {
 				tx.Payee = "Transfer to Account / CC"
 				tx.Category = models.CatFinancial
 			} else {
-				payeePattern := regexp.MustCompile(`to\s+(.*?)\s+with reference`)
-				payeeMatch := payeePattern.FindStringSubmatch(body)
+				payeeMatch := cibTransferOutPayee.FindStringSubmatch(body)
 				if len(payeeMatch) > 1 {
 					tx.Payee = strings.TrimSpace(payeeMatch[1])
 				} else {
@@ -139,31 +376,46 @@ func parseCIBCurrentAccount(tx *models.Transaction, body string) {
 	} else if strings.Contains(body, "credited") || strings.Contains(body, "تحويل مبلغ") || strings.Contains(body, "add") {
 		tx.Type = models.TypeIncome
 
-		// IPN pattern
-		patternIPN := regexp.MustCompile(`(?i)credited with IPN Inward for\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*([\d,]+\.\d{2})`)
-		matchIPN := patternIPN.FindStringSubmatch(body)
+		matchIPN := cibIPNPattern.FindStringSubmatch(body)
 
-		// Salary pattern
-		patternSal := regexp.MustCompile(`تحويل مبلغ\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?([\d,]+\.\d{2}).*?جهة العمل`)
-		matchSal := patternSal.FindStringSubmatch(body)
+		matchSal := cibSalaryPattern.FindStringSubmatch(body)
 
 		if len(matchIPN) > 2 {
+			tx.Source = "parseCIBCurrentAccount:ipn"
 			tx.Currency = utils.NormalizeCurrency(matchIPN[1])
-			amount, _ := strconv.ParseFloat(strings.ReplaceAll(matchIPN[2], ",", ""), 64)
+			amount, err := parseAmount(matchIPN[2])
+			if err != nil {
+				return err
+			}
 			tx.Amount = amount
+			parseCIBReference(tx, body)
 
-			payeePattern := regexp.MustCompile(`from\s+(.*?)\s+with reference`)
-			payeeMatch := payeePattern.FindStringSubmatch(body)
+			payeeMatch := cibTransferInPayee.FindStringSubmatch(body)
 			if len(payeeMatch) > 1 {
 				tx.Payee = strings.TrimSpace(payeeMatch[1])
 			} else {
 				tx.Payee = "Transfer In"
 			}
 		} else if len(matchSal) > 2 {
+			tx.Source = "parseCIBCurrentAccount:salary"
 			tx.Currency = utils.NormalizeCurrency(matchSal[1])
-			amount, _ := strconv.ParseFloat(strings.ReplaceAll(matchSal[2], ",", ""), 64)
+			amount, err := parseAmount(matchSal[2])
+			if err != nil {
+				return err
+			}
 			tx.Amount = amount
 			tx.Payee = "Salary / Work"
 		}
 	}
+
+	return nil
+}
+
+// parseCIBReference captures the bank reference of CIB transfer messages,
+// e.g. "... with reference 1234567890"
+func parseCIBReference(tx *models.Transaction, body string) {
+	match := cibReferencePattern.FindStringSubmatch(body)
+	if len(match) > 1 {
+		tx.Reference = match[1]
+	}
 }
@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseCIBMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "cib.yaml"), func(tc goldenCase) messageParser {
+		return newCIBParser(tc.Cards)
+	})
+}
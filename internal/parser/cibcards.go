@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+
+	"sms-parser/internal/utils"
+)
+
+// CIB card types
+const (
+	// CIBDebit is a debit card booked on the current account
+	CIBDebit = "debit"
+	// CIBCredit is a credit card with its own group
+	CIBCredit = "credit"
+	// CIBCurrent is a current account
+	CIBCurrent = "current"
+)
+
+// CIBCardTypes lists the valid CIBCard.Type values
+var CIBCardTypes = []string{CIBDebit, CIBCredit, CIBCurrent}
+
+// CIBCard describes one of your CIB cards or accounts, keyed by its last four
+// digits in Config.CIBCards
+type CIBCard struct {
+	// Name is the group its transactions go to; empty keeps the default
+	// (CIB_Current_Debit, or CIB_Credit_Card_XXXX for credit cards)
+	Name string `json:"name" yaml:"name"`
+	// Type is one of CIBCardTypes
+	Type string `json:"type" yaml:"type"`
+}
+
+// lastFourPattern matches the last four digits of a card or account
+var lastFourPattern = regexp.MustCompile(`^\d{4}$`)
+
+// LoadCIBCards reads a YAML or JSON file mapping the last four digits of CIB
+// cards and accounts to their name and type, e.g.
+//
+//	"1234": {name: Checking, type: debit}
+//	"9018": {name: Visa, type: credit}
+func LoadCIBCards(path string) (map[string]CIBCard, error) {
+	var cards map[string]CIBCard
+	if err := utils.DecodeFile(path, &cards); err != nil {
+		return nil, err
+	}
+
+	for _, digits := range sortedCardDigits(cards) {
+		if !lastFourPattern.MatchString(digits) {
+			return nil, fmt.Errorf("%s: %q is not the last four digits of a card or account", path, digits)
+		}
+		if !slices.Contains(CIBCardTypes, cards[digits].Type) {
+			return nil, fmt.Errorf("%s: card %s has unknown type %q (valid: %q)", path, digits, cards[digits].Type, CIBCardTypes)
+		}
+	}
+	return cards, nil
+}
+
+// sortedCardDigits returns the digits of cards in order, so that lookups
+// do not depend on map iteration order
+func sortedCardDigits(cards map[string]CIBCard) []string {
+	digits := make([]string, 0, len(cards))
+	for d := range cards {
+		digits = append(digits, d)
+	}
+	sort.Strings(digits)
+	return digits
+}
+
+// cardsOfType returns the digits of the cards of the given type, in order
+func cardsOfType(cards map[string]CIBCard, cardType string) []string {
+	var digits []string
+	for _, d := range sortedCardDigits(cards) {
+		if cards[d].Type == cardType {
+			digits = append(digits, d)
+		}
+	}
+	return digits
+}
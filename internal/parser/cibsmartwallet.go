@@ -0,0 +1,74 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+)
+
+// cibSmartWalletMention finds messages about the CIB Smart Wallet, which the
+// CIB sender reports alongside the bank's own alerts
+var cibSmartWalletMention = regexp.MustCompile(`(?i)smart\s*wallet|المحفظة الذكية|محفظة CIB`)
+
+// cibSmartWalletSuffix is the mention of the wallet that follows the
+// counterparty of a P2P transfer: "to 010... from your CIB Smart Wallet"
+var cibSmartWalletSuffix = regexp.MustCompile(`(?i)\s+(?:from|in|to|من|في|إلى)\s+(?:your\s+)?(?:CIB\s+)?(?:smart\s*wallet|المحفظة الذكية|محفظة CIB).*$`)
+
+// cibSmartWalletPatterns are the Smart Wallet shapes that are not plain P2P
+// transfers, which parseWalletMessage handles. Moves between the wallet and
+// your own account come first.
+var cibSmartWalletPatterns = []bankPattern{
+	{
+		// "Your CIB Smart Wallet has been topped up with EGP 500.00 from your account **5678" /
+		// "تم شحن محفظة CIB الذكية بمبلغ 500 جنيه"
+		name:   "top_up",
+		regex:  regexp.MustCompile(`(?i)(?:topped up(?:\s+with|\s+by)?|top[- ]?up(?:\s+of)?|تم شحن\s*(?:محفظ\S*)?(?:\s*CIB)?(?:\s*الذكية)?\s*(?:بمبلغ|مبلغ)?)\s*` + amountExpr),
+		income: true,
+		payee:  "Wallet Top Up",
+	},
+	{
+		// "EGP 200.00 has been transferred from your CIB Smart Wallet to your account **5678"
+		name:  "to_account",
+		regex: regexp.MustCompile(`(?i)` + amountExpr + `\s+(?:has been|was)\s+(?:transferred|moved)\s+from your (?:CIB\s+)?smart\s*wallet\s+to your (?:CIB\s+)?account`),
+		payee: "Wallet Cash Out",
+	},
+	{
+		// "You have paid EGP 120.00 to CARREFOUR from your CIB Smart Wallet. Ref 123456" /
+		// "تم دفع مبلغ 120 جنيه لدى CARREFOUR من محفظة CIB الذكية"
+		name:  "payment",
+		regex: regexp.MustCompile(`(?i)(?:paid|payment of|تم دفع(?:\s*مبلغ)?)\s*` + amountExpr + `\s*(?:to|at|لدى|لـ)\s*(?P<payee>.*?)(?:\s+(?:from|using|من)\s|` + payeeEnd + `)`),
+		payee: "Wallet Payment",
+	},
+}
+
+// parseCIBSmartWallet fills tx from a Smart Wallet message into the
+// CIB_Smart_Wallet group. Top-ups from and cash-outs to your own account
+// are transfers; payments are expenses and P2P transfers are booked as
+// parseWalletMessage books them. tx is left unchanged when no shape matches,
+// so account alerts that mention the wallet are parsed as usual.
+func parseCIBSmartWallet(tx *models.Transaction, body string) error {
+	wallet := *tx
+	wallet.TargetGroup = "CIB_Smart_Wallet"
+	if err := matchBankPatterns(&wallet, body, "parseCIBSmartWallet", cibSmartWalletPatterns); err != nil {
+		return err
+	}
+
+	switch wallet.Source {
+	case "":
+		if err := parseWalletMessage(&wallet, body, "CIB_Smart_Wallet"); err != nil {
+			return err
+		}
+		wallet.Payee = cibSmartWalletSuffix.ReplaceAllString(wallet.Payee, "")
+	case "parseCIBSmartWallet:top_up", "parseCIBSmartWallet:to_account":
+		wallet.Type = models.TypeTransfer
+		wallet.Category = models.CatFinancial
+		extractReference(&wallet, body)
+	default:
+		extractReference(&wallet, body)
+	}
+
+	if wallet.Source != "" {
+		*tx = wallet
+	}
+	return nil
+}
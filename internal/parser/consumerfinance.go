@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// consumerFinancePatterns are the loan message shapes shared by Halan, Aman,
+// and Contact, most specific first
+var consumerFinancePatterns = []bankPattern{
+	{
+		// "Your loan of EGP 10,000.00 has been disbursed"
+		name:   "disbursement_en",
+		regex:  regexp.MustCompile(`(?i)(?:loan|financing)(?:\s+of)?\s*` + amountExpr + `\s+(?:has been|was)\s+(?:disbursed|transferred|credited)`),
+		income: true,
+		payee:  "Loan Disbursement",
+	},
+	{
+		// "We have disbursed EGP 10,000.00 to your wallet"
+		name:   "disbursed",
+		regex:  regexp.MustCompile(`(?i)disbursed\s*` + amountExpr),
+		income: true,
+		payee:  "Loan Disbursement",
+	},
+	{
+		// "تم صرف قرض بمبلغ 10,000 جنيه" / "تم صرف التمويل بمبلغ 10,000 جنيه"
+		name:   "disbursement_ar",
+		regex:  regexp.MustCompile(`تم (?:صرف|تحويل|ايداع|إيداع)\s*(?:قرض\S*|التمويل|تمويل\S*|السلفة|سلفة)\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr),
+		income: true,
+		payee:  "Loan Disbursement",
+	},
+	{
+		// "Your installment payment of EGP 950.00 has been received" /
+		// "تم سداد قسط بمبلغ 950 جنيه"
+		name:  "installment",
+		regex: regexp.MustCompile(`(?i)(?:installment(?:\s+payment)?(?:\s+of)?|payment of|تم (?:سداد|دفع|استلام)\s*(?:قسط\S*|القسط\S*|مبلغ القسط)\s*(?:رقم\s*\d+\s*)?(?:بمبلغ|مبلغ)?)\s*` + amountExpr),
+		payee: "Loan Installment",
+	},
+}
+
+// parseHalanMessage parses Halan loan messages
+func parseHalanMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	return parseConsumerFinanceMessage(tx, body, "Halan")
+}
+
+// parseAmanMessage parses Aman loan messages
+func parseAmanMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	return parseConsumerFinanceMessage(tx, body, "Aman")
+}
+
+// parseContactMessage parses Contact consumer finance messages
+func parseContactMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	return parseConsumerFinanceMessage(tx, body, "Contact")
+}
+
+// parseConsumerFinanceMessage handles loan disbursements, booked as income,
+// and installment payments, booked as recurring financial expenses.
+// Reminders of upcoming installments return errReminder.
+func parseConsumerFinanceMessage(tx models.Transaction, body, provider string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	tx.TargetGroup = provider
+	if err := matchBankPatterns(&tx, body, "parseConsumerFinanceMessage", consumerFinancePatterns); err != nil {
+		return nil, err
+	}
+
+	if tx.Amount <= 0 && isReminder(body) {
+		return nil, errReminder
+	}
+	if tx.Amount < 0 {
+		tx.Category = models.CatFinancial
+		tx.Tags = append(tx.Tags, models.TagRecurring)
+	}
+	if tx.Payee == "Loan Disbursement" || tx.Payee == "Loan Installment" {
+		tx.Payee = provider + " " + tx.Payee
+	}
+	extractReference(&tx, body)
+
+	return []models.Transaction{tx}, nil
+}
@@ -0,0 +1,21 @@
+package parser
+
+import "testing"
+
+func TestParseHalanMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "halan.yaml"), func(goldenCase) messageParser {
+		return parseHalanMessage
+	})
+}
+
+func TestParseAmanMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "aman.yaml"), func(goldenCase) messageParser {
+		return parseAmanMessage
+	})
+}
+
+func TestParseContactMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "contact.yaml"), func(goldenCase) messageParser {
+		return parseContactMessage
+	})
+}
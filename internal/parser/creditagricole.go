@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// creditAgricolePatterns are the Credit Agricole Egypt message shapes, most
+// specific first
+var creditAgricolePatterns = []bankPattern{
+	{
+		// "Standing order of EGP 1,500.00 to Ahmed Ali has been executed from account ****5678" /
+		// "تم تنفيذ أمر مستديم بمبلغ 1,500 جنيه من حسابكم رقم ****5678"
+		name:  "standing_order",
+		regex: regexp.MustCompile(`(?i)(?:standing order(?:\s+of)?|(?:أمر|امر)\s*(?:مستديم|دفع دوري)(?:\s*(?:بمبلغ|مبلغ))?)\s*` + amountExpr + `(?:\s+(?:to|الى|إلى|لصالح)\s+(?P<payee>.*?)(?:\s+(?:has been|was|from|من)\s|` + payeeEnd + `))?`),
+		payee: "Standing Order",
+	},
+	{
+		// "Purchase of EGP 300.00 with card ****1234 at HYPERONE on 01/01"
+		name:  "purchase_en",
+		regex: regexp.MustCompile(`(?i)(?:purchase|transaction|used for)(?:\s+of|\s+with|\s+for)?\s*` + amountExpr + `.*?\bat\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Card Purchase",
+	},
+	{
+		// "تم خصم مبلغ 300.00 جنيه من بطاقتكم رقم ****1234 لدى HYPERONE"
+		name:  "purchase_ar",
+		regex: regexp.MustCompile(`(?:تم خصم|عملية شراء)\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr + `.*?(?:لدى|عند)\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Card Purchase",
+	},
+	{
+		// "Incoming transfer of EGP 4,000.00 from Mohamed Samir credited to account ****5678" /
+		// "تم استلام تحويل بمبلغ 4,000 جنيه من محمد سمير"
+		name:   "transfer_in",
+		regex:  regexp.MustCompile(`(?i)(?:incoming transfer(?:\s+of)?|credited(?:\s+with|\s+by)?|تم (?:استلام تحويل|ايداع|إيداع|اضافة|إضافة)(?:\s*(?:بمبلغ|مبلغ))?)\s*` + amountExpr + `(?:\s+(?:from|من)\s+(?P<payee>.*?)(?:\s+(?:credited|has been|was|الى|إلى)\s|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your account ****5678 has been debited with EGP 1,000.00"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)debited(?:\s+with|\s+by)?\s*` + amountExpr),
+		payee: "Transfer Out",
+	},
+}
+
+// parseCreditAgricoleMessage parses Credit Agricole Egypt SMS messages
+func parseCreditAgricoleMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	setCardGroup(&tx, body, "CreditAgricole")
+
+	if err := matchBankPatterns(&tx, body, "parseCreditAgricoleMessage", creditAgricolePatterns); err != nil {
+		return nil, err
+	}
+
+	return appendFee(tx, body)
+}
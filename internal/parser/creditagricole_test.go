@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseCreditAgricoleMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "creditagricole.yaml"), func(goldenCase) messageParser {
+		return parseCreditAgricoleMessage
+	})
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"sms-parser/internal/models"
+)
+
+// smsDecoder streams the <sms> elements of an SMS backup document one at a
+// time, so large backups are never held in memory as a whole
+type smsDecoder struct {
+	decoder *xml.Decoder
+	sawRoot bool
+}
+
+// newSMSDecoder creates a decoder reading the backup document from r
+func newSMSDecoder(r io.Reader) *smsDecoder {
+	return &smsDecoder{decoder: xml.NewDecoder(r)}
+}
+
+// Next returns the next message in the document, or io.EOF after the last one
+func (d *smsDecoder) Next() (models.SMS, error) {
+	for {
+		token, err := d.decoder.Token()
+		if errors.Is(err, io.EOF) {
+			if !d.sawRoot {
+				return models.SMS{}, errors.New("no <smses> root element")
+			}
+			return models.SMS{}, io.EOF
+		}
+		if err != nil {
+			return models.SMS{}, err
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		if !d.sawRoot {
+			if start.Name.Local != "smses" {
+				return models.SMS{}, fmt.Errorf("expected element type <smses> but have <%s>", start.Name.Local)
+			}
+			d.sawRoot = true
+			continue
+		}
+
+		if start.Name.Local != "sms" {
+			// MMS and other elements carry no bank messages
+			if err := d.decoder.Skip(); err != nil {
+				return models.SMS{}, err
+			}
+			continue
+		}
+
+		var sms models.SMS
+		if err := d.decoder.DecodeElement(&sms, &start); err != nil {
+			return models.SMS{}, err
+		}
+		return sms, nil
+	}
+}
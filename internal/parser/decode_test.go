@@ -0,0 +1,194 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"sms-parser/internal/models"
+)
+
+// TestSMSDecoder checks the streaming decoder reads the same messages as
+// decoding the whole document with xml.Unmarshal
+func TestSMSDecoder(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "backup.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want models.SMSBackup
+	if err := xml.Unmarshal(data, &want); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+	if len(want.SMS) != 4 {
+		t.Fatalf("xml.Unmarshal() read %d messages, want 4", len(want.SMS))
+	}
+
+	var got []models.SMS
+	decoder := newSMSDecoder(bytes.NewReader(data))
+	for {
+		sms, err := decoder.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		got = append(got, sms)
+	}
+
+	if !reflect.DeepEqual(got, want.SMS) {
+		t.Errorf("Next() read\n%+v\nxml.Unmarshal read\n%+v", got, want.SMS)
+	}
+
+	// Entity escapes are resolved and missing attributes are left empty
+	if body := got[0].Body; !strings.Contains(body, "H&M CITY STARS") {
+		t.Errorf("body = %q, want &amp; decoded", body)
+	}
+	if body := got[1].Body; !strings.Contains(body, "****4567\nBM CARREFOUR") {
+		t.Errorf("body = %q, want &#10; decoded", body)
+	}
+	if got[1].ReadableDate != "" || got[1].ContactName != "" {
+		t.Errorf("missing attributes decoded as %q and %q, want empty", got[1].ReadableDate, got[1].ContactName)
+	}
+	if body := got[2].Body; !strings.Contains(body, `"Ahmed Mohamed" <ahmed.ali@instapay>`) {
+		t.Errorf("body = %q, want &quot;, &lt; and &gt; decoded", body)
+	}
+}
+
+func TestSMSDecoderErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		document string
+	}{
+		{name: "empty", document: ""},
+		{name: "wrong root", document: `<messages><sms address="CIB" /></messages>`},
+		{name: "truncated", document: `<smses><sms address="CIB" body="Your card`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := newSMSDecoder(strings.NewReader(tt.document))
+			for {
+				_, err := decoder.Next()
+				if errors.Is(err, io.EOF) {
+					t.Fatal("Next() = io.EOF, want an error")
+				}
+				if err != nil {
+					return
+				}
+			}
+		})
+	}
+}
+
+// sliceSource yields messages decoded up front, the way Parse read backups
+// before the streaming decoder
+type sliceSource struct {
+	messages []models.SMS
+}
+
+func (s *sliceSource) Next() (models.SMS, error) {
+	if len(s.messages) == 0 {
+		return models.SMS{}, io.EOF
+	}
+	sms := s.messages[0]
+	s.messages = s.messages[1:]
+	return sms, nil
+}
+
+// syntheticBackup returns a backup document of n mixed bank, wallet, and
+// personal messages with <mms> elements and re-sent copies in between
+func syntheticBackup(n int) []byte {
+	rng := rand.New(rand.NewSource(1))
+	bodies := []struct{ sender, body string }{
+		{"CIB", "Your credit card ending with %[1]d was charged for EGP %[2]d.%02[3]d at H&amp;M STORE %[4]d on 01/01 at 12:00"},
+		{"CIB", "Your account 2373 was credited with IPN Inward for EGP %[2]d.00 from Sara Ali with reference %[1]d%[4]d"},
+		{"CIB", "Your account 2373 was debited with amount EGP %[2]d to Ahmed Mohamed with reference %[1]d%[4]d on 01/01"},
+		{"CIB", "Your CIB statement for card %[1]d is now available"},
+		{"Banque Misr", "تم الخصم مبلغ %[2]d.%02[3]d جنيه من بطاقة بنك مصر ****%[1]d BM CARREFOUR %[4]d يوم 01/01"},
+		{"Banque Misr", "Your OTP is %[1]d%[4]d"},
+		{"InstaPay", "You have received EGP %[2]d.00 from Mona Samir on 01/01. Ref %[1]d%[4]d"},
+		{"InstaPay", "You have sent EGP %[2]d.%02[3]d to Ahmed Mohamed via InstaPay on 01/01. Ref %[1]d%[4]d"},
+		{"+201001234567", "See you at %[4]d? &quot;Bring&quot; %[2]d &lt;3"},
+		{"Friend", "Dinner was EGP %[2]d, transfer when you can&#10;Thanks"},
+	}
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n<smses count=\"" + strconv.Itoa(n) + "\">\n")
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	element := func(sender, body string, date int64, attrs string) string {
+		return `  <sms protocol="0" address="` + sender + `" date="` + strconv.FormatInt(date, 10) + `" type="1" body="` + body + `"` + attrs + " />\n"
+	}
+	var lastSender, lastBody string
+	var lastDate int64
+	for i := 0; i < n; i++ {
+		date += rng.Int63n(int64(6 * time.Hour / time.Millisecond))
+		if i%50 == 0 {
+			b.WriteString(`  <mms date="` + strconv.FormatInt(date, 10) + `" address="+201001234567"><parts><part seq="0" ct="text/plain" text="photo" /></parts></mms>` + "\n")
+		}
+		if i%40 == 0 && lastBody != "" {
+			// A re-sent copy a few seconds later
+			b.WriteString(element(lastSender, lastBody, lastDate+3000, ""))
+		}
+
+		sample := bodies[rng.Intn(len(bodies))]
+		cards := []int{4321, 5694, 7759}
+		body := fmt.Sprintf(sample.body, cards[rng.Intn(len(cards))], 1+rng.Intn(20000), rng.Intn(100), rng.Intn(1000))
+		attrs := ""
+		if rng.Intn(2) == 0 {
+			attrs = ` readable_date="` + time.UnixMilli(date).UTC().Format("Jan 2, 2006 3:04:05 PM") + `"`
+		}
+		if rng.Intn(3) == 0 {
+			attrs += ` contact_name="(Unknown)"`
+		}
+		b.WriteString(element(sample.sender, body, date, attrs))
+		lastSender, lastBody, lastDate = sample.sender, body, date
+	}
+	b.WriteString("</smses>\n")
+	return []byte(b.String())
+}
+
+// TestParseMatchesUnmarshal checks that parsing a large backup through the
+// streaming decoder gives the same results as decoding it whole with
+// xml.Unmarshal, as Parse did before
+func TestParseMatchesUnmarshal(t *testing.T) {
+	data := syntheticBackup(5000)
+	cfg := Config{DetectTransfers: true, DedupWindow: time.Minute, Location: time.UTC}
+
+	streamed, streamedUnparsed, err := New(cfg).Parse(bytes.NewReader(data), Options{})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	var backup models.SMSBackup
+	if err := xml.Unmarshal(data, &backup); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+	p := New(cfg)
+	collected, wantUnparsed, err := p.parseMessages(&sliceSource{messages: backup.SMS}, Options{})
+	if err != nil {
+		t.Fatalf("parseMessages() error = %v", err)
+	}
+	want := p.Finish(collected)
+
+	if len(want) < 5 || len(wantUnparsed) == 0 {
+		t.Fatalf("the synthetic backup gave %d accounts and %d unparsed messages, want a mix", len(want), len(wantUnparsed))
+	}
+	if !reflect.DeepEqual(streamed, want) {
+		t.Errorf("Parse() gave %d accounts, xml.Unmarshal %d, or their transactions differ", len(streamed), len(want))
+	}
+	if !reflect.DeepEqual(streamedUnparsed, wantUnparsed) {
+		t.Errorf("Parse() gave %d unparsed messages, xml.Unmarshal %d, or they differ", len(streamedUnparsed), len(wantUnparsed))
+	}
+}
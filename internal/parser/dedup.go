@@ -0,0 +1,37 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"strings"
+	"time"
+
+	"sms-parser/internal/models"
+)
+
+// dedupKey groups messages that are candidates for being duplicates: a hash
+// of the sender and the body, ignoring whitespace differences. The amount is
+// part of the body, so equal keys also mean equal amounts. Only the hash is
+// kept, so large backups are not held in memory.
+type dedupKey [sha256.Size]byte
+
+// newDedupKey returns the dedupKey of sms
+func newDedupKey(sms models.SMS) dedupKey {
+	return sha256.Sum256([]byte(sms.Address + "|" + strings.Join(strings.Fields(sms.Body), " ")))
+}
+
+// isDuplicate reports whether a message received at candidate repeats one
+// received at one of the seen times (all sharing its dedupKey). With a zero
+// window only an identical timestamp counts; otherwise any message within
+// window of the candidate does.
+func isDuplicate(seen []time.Time, candidate time.Time, window time.Duration) bool {
+	for _, t := range seen {
+		gap := candidate.Sub(t)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap <= window {
+			return true
+		}
+	}
+	return false
+}
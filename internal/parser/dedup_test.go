@@ -0,0 +1,59 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"sms-parser/internal/models"
+)
+
+func TestIsDuplicate(t *testing.T) {
+	received := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		seen      []time.Time
+		candidate time.Time
+		window    time.Duration
+		want      bool
+	}{
+		{name: "nothing seen", candidate: received, window: time.Minute, want: false},
+		{name: "same timestamp without a window", seen: []time.Time{received}, candidate: received, want: true},
+		{name: "one millisecond apart without a window", seen: []time.Time{received}, candidate: received.Add(time.Millisecond), want: false},
+		{name: "inside the window", seen: []time.Time{received}, candidate: received.Add(30 * time.Second), window: time.Minute, want: true},
+		{name: "at the window edge", seen: []time.Time{received}, candidate: received.Add(time.Minute), window: time.Minute, want: true},
+		{name: "past the window edge", seen: []time.Time{received}, candidate: received.Add(time.Minute + time.Millisecond), window: time.Minute, want: false},
+		{name: "earlier than a seen copy", seen: []time.Time{received}, candidate: received.Add(-time.Minute), window: time.Minute, want: true},
+		{name: "near any seen copy", seen: []time.Time{received, received.Add(time.Hour)}, candidate: received.Add(time.Hour + time.Second), window: time.Minute, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDuplicate(tt.seen, tt.candidate, tt.window); got != tt.want {
+				t.Errorf("isDuplicate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewDedupKey(t *testing.T) {
+	sms := models.SMS{Address: "CIB", Body: "Your credit card ending with 4321 was charged for EGP 250.00"}
+
+	resent := sms
+	resent.Body = "Your credit card  ending with 4321\nwas charged for EGP 250.00 "
+	if newDedupKey(sms) != newDedupKey(resent) {
+		t.Error("messages differing only in whitespace have different keys")
+	}
+
+	otherAmount := sms
+	otherAmount.Body = "Your credit card ending with 4321 was charged for EGP 260.00"
+	if newDedupKey(sms) == newDedupKey(otherAmount) {
+		t.Error("messages with different amounts have the same key")
+	}
+
+	otherSender := sms
+	otherSender.Address = "Banque Misr"
+	if newDedupKey(sms) == newDedupKey(otherSender) {
+		t.Error("messages from different senders have the same key")
+	}
+}
@@ -0,0 +1,19 @@
+// Package parser extracts bank transactions from SMS backup XML documents.
+//
+// The simplest entry point parses an in-memory backup with the built-in
+// bank parsers and returns the transactions grouped by account:
+//
+//	accounts, err := parser.ParseBytes(data, parser.Options{
+//		Senders:   []string{"CIB"},
+//		StartDate: "2025-01-01",
+//	})
+//	for _, account := range accounts {
+//		for _, tx := range account.Transactions {
+//			fmt.Println(account.Name, tx.Date, tx.Payee, tx.Amount, tx.Reference)
+//		}
+//	}
+//
+// For custom rules or categories, create a Parser with New and call Parse or
+// ParseFile. For several backups, call Collect or CollectFile on each and
+// pass all the transactions to Finish once.
+package parser
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// emiratesNBDPatterns are the Emirates NBD Egypt message shapes, most specific
+// first. Charges abroad name the foreign amount first and may add the amount
+// billed in the card's currency (see billedExpr).
+var emiratesNBDPatterns = []bankPattern{
+	{
+		// "ATM withdrawal of AED 500.00 (EGP 6,900.00) from card ending 1234"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)(?:(?:ATM|cash) withdrawal(?:\s+of)?|سحب(?:\s*نقدي)?(?:\s*(?:بمبلغ|مبلغ))?)\s*` + amountExpr + billedExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "Purchase of AED 120.00 (EGP 1,650.00) at CARREFOUR DUBAI with card ending 1234. Available balance: EGP 5,000.00" /
+		// "Your card ending 1234 was used for EGP 250.00 at TALABAT on 01/01"
+		name:  "purchase",
+		regex: regexp.MustCompile(`(?i)(?:purchase(?:\s+of)?|transaction(?:\s+of)?|used for)\s*` + amountExpr + billedExpr + `.*?\bat\s+(?P<payee>.*?)(?:\s+(?:with|using)\s+card\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "عملية شراء بمبلغ 250.00 جنيه لدى TALABAT بالبطاقة المنتهية بـ 1234"
+		name:  "purchase_ar",
+		regex: regexp.MustCompile(`(?:شراء|خصم)\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr + billedExpr + `.*?(?:لدى|عند)\s+(?P<payee>.*?)(?:\s+بالبطاق\S*\s|\s+ببطاق\S*\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "A refund of AED 120.00 (EGP 1,650.00) was credited to card ending 1234"
+		name:   "refund",
+		regex:  regexp.MustCompile(`(?i)refund(?:\s+of)?\s*` + amountExpr + billedExpr),
+		income: true,
+		payee:  "Refund",
+	},
+	{
+		// "Your account ending 5678 has been credited with EGP 5,000.00"
+		name:   "credit",
+		regex:  regexp.MustCompile(`(?i)(?:credited(?:\s+with|\s+by)?|تم (?:ايداع|إيداع|اضافة|إضافة)(?:\s*مبلغ)?)\s*` + amountExpr),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your account ending 5678 has been debited with EGP 1,000.00"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)(?:debited(?:\s+with|\s+by)?|تم (?:خصم|تحويل)(?:\s*مبلغ)?)\s*` + amountExpr),
+		payee: "Transfer Out",
+	},
+}
+
+// parseEmiratesNBDMessage parses Emirates NBD Egypt SMS messages
+func parseEmiratesNBDMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	setCardGroup(&tx, body, "EmiratesNBD")
+
+	if err := matchBankPatterns(&tx, body, "parseEmiratesNBDMessage", emiratesNBDPatterns); err != nil {
+		return nil, err
+	}
+
+	return appendFee(tx, body)
+}
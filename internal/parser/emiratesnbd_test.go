@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseEmiratesNBDMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "emiratesnbd.yaml"), func(goldenCase) messageParser {
+		return parseEmiratesNBDMessage
+	})
+}
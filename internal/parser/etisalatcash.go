@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// etisalatCashSuccess matches the "successfully" confirmations put after the
+// counterparty; it is dropped so it does not end up in the payee
+var etisalatCashSuccess = regexp.MustCompile(`(?i)\s+(?:بنجاح|successfully)`)
+
+// etisalatCashPatterns are the Etisalat Cash (e& money) payment shapes, most
+// specific first. Transfers are left to parseWalletMessage.
+var etisalatCashPatterns = []bankPattern{
+	{
+		// "تم دفع فاتورة WE بمبلغ 120 جنيه بنجاح. رقم العملية 123456789"
+		name:  "bill_payment_ar",
+		regex: regexp.MustCompile(`تم (?:دفع|سداد)\s*فاتورة\s*(?P<payee>.*?)\s*(?:بمبلغ|مبلغ)\s*` + amountExpr),
+		payee: "Bill Payment",
+	},
+	{
+		// "تم دفع 120 جنيه لـ WE بنجاح" / "You have paid EGP 120.00 to WE successfully. Transaction ID 123456789"
+		name:  "payment",
+		regex: regexp.MustCompile(`(?i)(?:تم (?:دفع|سداد)(?:\s*مبلغ)?|paid)\s*` + amountExpr + `\s*(?:to|for|لـ|ل)\s*(?P<payee>.*?)` + payeeEnd),
+		payee: "Payment",
+	},
+}
+
+// etisalatCashRefPattern finds the "Transaction ID" reference of English
+// confirmations; Arabic ones use "رقم العملية", which extractReference knows
+var etisalatCashRefPattern = regexp.MustCompile(`(?i)transaction\s*id\s*:?\s*([A-Za-z0-9-]+)`)
+
+// parseEtisalatCashMessage parses Etisalat Cash (e& money) transfers and payments
+func parseEtisalatCashMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	body = etisalatCashSuccess.ReplaceAllString(body, "")
+
+	tx.TargetGroup = "Etisalat_Cash"
+	if err := matchBankPatterns(&tx, body, "parseEtisalatCashMessage", etisalatCashPatterns); err != nil {
+		return nil, err
+	}
+	if tx.Source == "" {
+		if err := parseWalletMessage(&tx, body, "Etisalat_Cash"); err != nil {
+			return nil, err
+		}
+	} else {
+		extractBalance(&tx, body)
+		extractReference(&tx, body)
+	}
+
+	if match := etisalatCashRefPattern.FindStringSubmatch(body); match != nil {
+		tx.Reference = match[1]
+	}
+	return []models.Transaction{tx}, nil
+}
@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseEtisalatCashMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "etisalatcash.yaml"), func(goldenCase) messageParser {
+		return parseEtisalatCashMessage
+	})
+}
@@ -0,0 +1,30 @@
+package parser_test
+
+import (
+	"fmt"
+	"log"
+
+	"sms-parser/internal/parser"
+)
+
+func ExampleParseBytes() {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<smses>
+  <sms address="CIB" date="1736935200000" body="Your credit card ending with 4321 was charged for EGP 250.00 at CARREFOUR on 15/01 at 12:00" />
+  <sms address="NBE" date="1736938800000" body="تم اضافة مبلغ 5,000 جنيه الى حسابكم رقم ****5678" />
+  <sms address="Mom" date="1736942400000" body="Call me when you're free" />
+</smses>`)
+
+	accounts, err := parser.ParseBytes(data, parser.Options{StartDate: "2025-01-01"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, account := range accounts {
+		for _, tx := range account.Transactions {
+			fmt.Println(account.Name, tx.Payee, tx.Amount, tx.Currency, tx.Category)
+		}
+	}
+	// Output:
+	// CIB_Credit_Card_4321 CARREFOUR -250 EGP Food & Drink
+	// NBE_Card_5678 Transfer In 5000 EGP Income
+}
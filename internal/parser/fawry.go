@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// fawryPatterns are the Fawry and myFawry message shapes, most specific first
+var fawryPatterns = []bankPattern{
+	{
+		// "تم شحن محفظة ماي فوري بمبلغ 500 جنيه" / "Your myFawry wallet has been charged with EGP 500.00"
+		name:   "wallet_top_up",
+		regex:  regexp.MustCompile(`(?i)(?:(?:تم\s*)?شحن\s*محفظ\S*(?:\s*ماي\s*فوري)?\s*(?:بمبلغ|مبلغ)?|wallet has been (?:charged|topped up)(?:\s+with|\s+by)?|top[- ]?up of)\s*` + amountExpr),
+		income: true,
+		payee:  "myFawry Wallet Top Up",
+	},
+	{
+		// "تم دفع فاتورة كهرباء شمال القاهرة بمبلغ 350.00 جنيه بنجاح. رقم العملية 123456789"
+		name:  "bill_ar",
+		regex: regexp.MustCompile(`تم (?:دفع|سداد)\s*(?:فاتورة\s*)?(?P<payee>.*?)\s*(?:بمبلغ|مبلغ)\s*` + amountExpr),
+		payee: "Bill Payment",
+	},
+	{
+		// "Your payment of EGP 350.00 for North Cairo Electricity was successful. Ref no 123456789"
+		name:  "bill_en",
+		regex: regexp.MustCompile(`(?i)payment of\s*` + amountExpr + `\s*(?:for|to)\s+(?P<payee>.*?)(?:\s+(?:was|has been|is)\s|` + payeeEnd + `)`),
+		payee: "Bill Payment",
+	},
+	{
+		// "You paid EGP 120.00 for WE Internet. Ref no 123456789"
+		name:  "paid_en",
+		regex: regexp.MustCompile(`(?i)paid\s*` + amountExpr + `\s*(?:for|to)\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Bill Payment",
+	},
+}
+
+// fawryBillCategories assigns utility bills to a category by the billed
+// service, since Arabic service names are not in the categorizer's keywords
+var fawryBillCategories = []struct {
+	category string
+	keywords []string
+}{
+	{
+		category: models.CatHousing,
+		keywords: []string{"electricity", "كهرباء", "water", "مياه", "gas", "غاز"},
+	},
+	{
+		category: models.CatComms,
+		keywords: []string{
+			"internet", "انترنت", "إنترنت", "landline", "تليفون أرضي", "تليفون ارضي",
+			"mobile", "موبايل", "recharge", "شحن رصيد", "we ", "vodafone", "فودافون",
+			"orange", "اورنج", "أورنج", "etisalat", "اتصالات",
+		},
+	},
+}
+
+// parseFawryMessage parses Fawry and myFawry payment confirmations. The
+// billed service is the payee, and utility bills are categorized by it.
+func parseFawryMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	tx.TargetGroup = "Fawry"
+	if err := matchBankPatterns(&tx, body, "parseFawryMessage", fawryPatterns); err != nil {
+		return nil, err
+	}
+	extractBalance(&tx, body)
+	extractReference(&tx, body)
+
+	if tx.Amount < 0 {
+		service := strings.ToLower(tx.Payee) + " "
+		for _, bill := range fawryBillCategories {
+			if utils.Contains(service, bill.keywords...) {
+				tx.Category = bill.category
+				break
+			}
+		}
+	}
+
+	return []models.Transaction{tx}, nil
+}
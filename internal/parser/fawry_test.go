@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseFawryMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "fawry.yaml"), func(goldenCase) messageParser {
+		return parseFawryMessage
+	})
+}
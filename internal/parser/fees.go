@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// feePattern matches fee mentions such as "plus fees of EGP 5.00",
+// "Transaction cost, KES 23.00", or "رسوم 5.00 جنيه"
+var feePattern = regexp.MustCompile(`(?i)(?:\bfees?(?:\s+of)?|transaction cost,?|رسوم(?:\s+قدرها)?)\s*:?\s*((?-i:[A-Z]{3}|SR)\b|L\.E\.?|ج\.م|جنيه|جم|ر\.س|ريال|د\.إ|درهم|د\.ك|د\.أ|د\.ا)?\s*([\d,]+(?:\.\d{1,3})?)\s*((?-i:[A-Z]{3}|SR)\b|L\.E\.?|ج\.م|جنيه|جم|ر\.س|ريال|د\.إ|درهم|د\.ك|د\.أ|د\.ا)?`)
+
+// appendFee returns tx followed by a separate "Bank Fee" expense when the
+// message mentions a fee, so account totals reconcile with the bank's
+func appendFee(tx models.Transaction, body string) ([]models.Transaction, error) {
+	transactions := []models.Transaction{tx}
+	if tx.TargetGroup == "" || tx.Amount == 0 {
+		return transactions, nil
+	}
+
+	match := feePattern.FindStringSubmatch(body)
+	if len(match) < 4 {
+		return transactions, nil
+	}
+	fee, err := parseAmount(match[2])
+	if err != nil {
+		return nil, err
+	}
+	if fee == 0 {
+		return transactions, nil
+	}
+
+	currency := tx.Currency
+	if detected := firstNonEmpty(match[1], match[3]); detected != "" {
+		currency = utils.NormalizeCurrency(detected)
+	}
+
+	feeTx := tx
+	feeTx.Source = "appendFee:fee"
+	feeTx.Payee = "Bank Fee"
+	feeTx.Amount = -fee
+	feeTx.Currency = currency
+	feeTx.Type = models.TypeExpense
+	feeTx.Category = models.CatFinancial
+	// The balance and FX details belong to the main transaction
+	feeTx.Balance = 0
+	feeTx.HasBalance = false
+	feeTx.OriginalAmount = 0
+	feeTx.OriginalCurrency = ""
+
+	return append(transactions, feeTx), nil
+}
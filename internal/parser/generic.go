@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// genericAmountPattern finds an amount that carries a currency, before or
+// after it; bare numbers are too often phone, card, or reference numbers
+var genericAmountPattern = regexp.MustCompile(`(?i)(?:(?P<currency>(?-i:[A-Z]{3})|L\.E\.?|ج\.م|جنيه|جم)\s*(?P<amount>\d[\d,]*(?:\.\d{1,3})?)|(?P<amount2>\d[\d,]*(?:\.\d{1,3})?)\s*(?P<currency2>(?-i:[A-Z]{3})\b|L\.E\.?|ج\.م|جنيه|جم))`)
+
+// genericCreditPattern and genericDebitPattern tell the direction of a
+// transaction-like message; credits are checked first, since refunds and
+// deposits often also say where the money came from
+var (
+	genericCreditPattern = regexp.MustCompile(`(?i)\b(?:credited|received|deposited|refund(?:ed)?|added to)\b|تم (?:ايداع|إيداع|اضافة|إضافة|استلام|استقبال|رد)`)
+	genericDebitPattern  = regexp.MustCompile(`(?i)\b(?:debited|deducted|charged|purchase|spent|withdrawn|withdrawal|paid|sent|transferred)\b|تم (?:خصم|سحب|دفع|سداد|تحويل|شراء)|عملية شراء`)
+)
+
+// genericPayeePattern finds a counterparty after the amount
+var genericPayeePattern = regexp.MustCompile(`(?i)(?:\bat|\bto|\bfrom|لدى|عند|الى|إلى|من)\s+(.*?)` + payeeEnd)
+
+// genericOwnAccount matches a captured payee that is the user's own account
+var genericOwnAccount = regexp.MustCompile(`(?i)^(?:your\b|حساب|بطاق)`)
+
+// looksLikeTransaction reports whether body has an amount with a currency
+// and a debit or credit keyword
+func looksLikeTransaction(body string) bool {
+	body = utils.NormalizeDigits(body)
+	return genericAmountPattern.MatchString(body) &&
+		(genericCreditPattern.MatchString(body) || genericDebitPattern.MatchString(body))
+}
+
+// isGenericSender reports whether sender looks like a business sender ID
+// rather than a phone number, so personal messages never reach the fallback
+func isGenericSender(sender string) bool {
+	return strings.IndexFunc(sender, unicode.IsLetter) >= 0
+}
+
+// genericGroup turns a sender ID into an "Unknown_<sender>" group name that
+// is safe as a file name
+func genericGroup(sender string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(sender))
+	return "Unknown_" + name
+}
+
+// newGenericParser returns a best-effort parser for an unrecognized sender,
+// used for messages that pass looksLikeTransaction
+func newGenericParser(sender string) messageParser {
+	group := genericGroup(sender)
+	return func(tx models.Transaction, body string) ([]models.Transaction, error) {
+		return parseGenericMessage(tx, body, group)
+	}
+}
+
+// parseGenericMessage takes the first amount with a currency as the
+// transaction amount, its direction from credit/debit keywords, and the
+// payee from the words after "at", "to", "from", or their Arabic forms
+func parseGenericMessage(tx models.Transaction, body, group string) ([]models.Transaction, error) {
+	// Amounts may be written in Eastern Arabic digits
+	body = utils.NormalizeDigits(body)
+
+	// Skip OTP and login messages
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	tx.TargetGroup = group
+	tx.CardLast4 = cardDigits(body)
+
+	// The balance is not the transaction amount
+	rest := balancePattern.ReplaceAllString(body, "")
+	loc := genericAmountPattern.FindStringSubmatchIndex(rest)
+	if loc == nil {
+		return []models.Transaction{tx}, nil
+	}
+	field := func(name string) string {
+		if i := genericAmountPattern.SubexpIndex(name); loc[2*i] >= 0 {
+			return rest[loc[2*i]:loc[2*i+1]]
+		}
+		return ""
+	}
+
+	amount, err := parseAmount(firstNonEmpty(field("amount"), field("amount2")))
+	if err != nil {
+		return nil, err
+	}
+	tx.Currency = utils.NormalizeCurrency(firstNonEmpty(field("currency"), field("currency2")))
+
+	if genericCreditPattern.MatchString(body) {
+		tx.Source = "parseGenericMessage:credit"
+		tx.Type = models.TypeIncome
+		tx.Amount = amount
+		tx.Payee = "Deposit"
+	} else {
+		tx.Source = "parseGenericMessage:debit"
+		tx.Amount = -amount
+		tx.Payee = "Payment"
+	}
+
+	// "from your account" names the user's own account, not a counterparty
+	if match := genericPayeePattern.FindStringSubmatch(rest[loc[1]:]); match != nil && !genericOwnAccount.MatchString(match[1]) {
+		if payee := utils.CleanPayeeName(strings.TrimSpace(match[1])); payee != "" {
+			tx.Payee = payee
+		}
+	}
+	extractReference(&tx, body)
+
+	return []models.Transaction{tx}, nil
+}
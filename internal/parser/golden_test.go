@@ -0,0 +1,134 @@
+package parser
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// goldenCase is a sample message body from testdata and the transactions a
+// parser must produce for it
+type goldenCase struct {
+	Name string `yaml:"name"`
+	Body string `yaml:"body"`
+	// Cards configures the CIB parser; empty means no cards are configured
+	Cards map[string]CIBCard `yaml:"cards"`
+	// Skipped expects the message to be rejected as a non-transaction, such
+	// as an OTP or a statement summary
+	Skipped bool                `yaml:"skipped"`
+	Want    []goldenTransaction `yaml:"want"`
+}
+
+// goldenTransaction holds the fields of a parsed transaction the golden data
+// checks
+type goldenTransaction struct {
+	Group            string   `yaml:"group"`
+	Payee            string   `yaml:"payee"`
+	Amount           float64  `yaml:"amount"`
+	Currency         string   `yaml:"currency"`
+	Type             string   `yaml:"type"`
+	Source           string   `yaml:"source"`
+	Card             string   `yaml:"card"`
+	Reference        string   `yaml:"reference"`
+	Balance          *float64 `yaml:"balance"`
+	OriginalAmount   float64  `yaml:"original_amount"`
+	OriginalCurrency string   `yaml:"original_currency"`
+	Pending          bool     `yaml:"pending"`
+	Tags             []string `yaml:"tags"`
+}
+
+// newGoldenTransaction extracts the checked fields of tx
+func newGoldenTransaction(tx models.Transaction) goldenTransaction {
+	got := goldenTransaction{
+		Group:            tx.TargetGroup,
+		Payee:            tx.Payee,
+		Amount:           tx.Amount,
+		Currency:         tx.Currency,
+		Type:             tx.Type,
+		Source:           tx.Source,
+		Card:             tx.CardLast4,
+		Reference:        tx.Reference,
+		OriginalAmount:   tx.OriginalAmount,
+		OriginalCurrency: tx.OriginalCurrency,
+		Pending:          tx.Pending,
+	}
+	if tx.HasBalance {
+		balance := tx.Balance
+		got.Balance = &balance
+	}
+	if len(tx.Tags) > 0 {
+		got.Tags = tx.Tags
+	}
+	return got
+}
+
+// loadGolden reads the golden cases in testdata/name
+func loadGolden(t *testing.T, name string) []goldenCase {
+	t.Helper()
+
+	var cases []goldenCase
+	if err := utils.DecodeFile(filepath.Join("testdata", name), &cases); err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatalf("no cases in testdata/%s", name)
+	}
+	return cases
+}
+
+// runGolden runs each case through the parser parserFor returns for it,
+// starting from the transaction Parse passes in, and compares the result
+func runGolden(t *testing.T, cases []goldenCase, parserFor func(goldenCase) messageParser) {
+	t.Helper()
+
+	for _, tc := range cases {
+		t.Run(tc.Name, func(t *testing.T) {
+			tx := models.Transaction{
+				Currency: "EGP",
+				Type:     models.TypeExpense,
+				Category: models.CatGeneral,
+				Note:     tc.Body,
+			}
+
+			parsed, err := parserFor(tc)(tx, tc.Body)
+			if tc.Skipped {
+				if !isSkipped(err) {
+					t.Fatalf("error = %v, want the message skipped", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+
+			got := make([]goldenTransaction, len(parsed))
+			for i, tx := range parsed {
+				got[i] = newGoldenTransaction(tx)
+			}
+			if !reflect.DeepEqual(got, tc.Want) {
+				t.Errorf("parsed\n%+v\nwant\n%+v", formatGolden(got), formatGolden(tc.Want))
+			}
+		})
+	}
+}
+
+// formatGolden dereferences balances so failures show their values
+func formatGolden(transactions []goldenTransaction) []map[string]any {
+	formatted := make([]map[string]any, len(transactions))
+	for i, tx := range transactions {
+		balance := any(nil)
+		if tx.Balance != nil {
+			balance = *tx.Balance
+		}
+		formatted[i] = map[string]any{
+			"group": tx.Group, "payee": tx.Payee, "amount": tx.Amount, "currency": tx.Currency,
+			"type": tx.Type, "source": tx.Source, "card": tx.Card, "reference": tx.Reference,
+			"balance": balance, "original_amount": tx.OriginalAmount,
+			"original_currency": tx.OriginalCurrency, "pending": tx.Pending, "tags": tx.Tags,
+		}
+	}
+	return formatted
+}
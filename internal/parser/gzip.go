@@ -0,0 +1,42 @@
+package parser
+
+import (
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// gzipMagic is the two-byte header every gzip stream starts with
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// maybeDecompress returns a reader over the decompressed contents of r when r
+// holds a gzip stream, and over r unchanged otherwise
+func maybeDecompress(r io.Reader) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+
+	magic, err := buffered.Peek(len(gzipMagic))
+	if err != nil || magic[0] != gzipMagic[0] || magic[1] != gzipMagic[1] {
+		// Too short or not gzip: let the XML decoder report on it
+		return buffered, nil
+	}
+
+	gz, err := gzip.NewReader(buffered)
+	if err != nil {
+		return nil, fmt.Errorf("error reading gzip header: %w", gzipError(err))
+	}
+	return gz, nil
+}
+
+// gzipError turns the low-level errors of a damaged gzip stream into a
+// message that says so
+func gzipError(err error) error {
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return fmt.Errorf("gzip stream is truncated: %w", err)
+	case errors.Is(err, gzip.ErrChecksum), errors.Is(err, gzip.ErrHeader):
+		return fmt.Errorf("gzip stream is corrupt: %w", err)
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// hsbcAccountPattern finds the account in "Your account XXXX has been
+// debited" alerts, which may show the full number or only its last digits
+var hsbcAccountPattern = regexp.MustCompile(`(?i)account\s*(?:no\.?|number)?\s*[*xX#]*(\d[\d-]*\d)`)
+
+// hsbcCardPatterns are the HSBC Egypt credit card advice shapes
+var hsbcCardPatterns = []bankPattern{
+	{
+		// "HSBC Credit Card ending 1234: a transaction of EGP 500.00 at AMAZON on 01/01 has been approved"
+		name:  "card_advice",
+		regex: regexp.MustCompile(`(?i)(?:transaction|purchase|used for|charged(?:\s+with|\s+for)?)(?:\s+of)?\s*` + amountExpr + `.*?\bat\s+(?P<payee>.*?)(?:\s+(?:has|was|is)\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "A refund of EGP 500.00 from AMAZON has been credited to your HSBC Credit Card ending 1234"
+		name:   "card_refund",
+		regex:  regexp.MustCompile(`(?i)refund(?:\s+of)?\s*` + amountExpr + `(?:\s+from\s+(?P<payee>.*?)(?:\s+(?:has|was|is)\s|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Refund",
+	},
+	{
+		// "Payment of EGP 5,000.00 has been received on your HSBC Credit Card ending 1234"
+		name:   "card_payment",
+		regex:  regexp.MustCompile(`(?i)payment(?:\s+of)?\s*` + amountExpr),
+		income: true,
+		payee:  "Card Payment",
+	},
+}
+
+// hsbcAccountPatterns are the HSBC Egypt account alert shapes
+var hsbcAccountPatterns = []bankPattern{
+	{
+		// "Your account XXXX has been debited with EGP 1,000.00 on 01/01 for ATM WITHDRAWAL"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)debited(?:\s+with|\s+by|\s+for)?\s*` + amountExpr + `(?:.*?\bfor\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "Your account XXXX has been credited with EGP 15,000.00 on 01/01 for SALARY"
+		name:   "credit",
+		regex:  regexp.MustCompile(`(?i)credited(?:\s+with|\s+by|\s+for)?\s*` + amountExpr + `(?:.*?\b(?:for|from)\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		income: true,
+		payee:  "Transfer In",
+	},
+}
+
+// parseHSBCMessage parses HSBC Egypt SMS messages. Credit card advices are
+// grouped per card and account alerts per account.
+func parseHSBCMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+
+	var err error
+	if utils.Contains(body, "Credit Card", "credit card", "CREDIT CARD") {
+		tx.TargetGroup = "HSBC_Credit_Card"
+		if digits := cardDigits(body); digits != "" {
+			tx.TargetGroup = fmt.Sprintf("HSBC_Credit_Card_%s", digits)
+			tx.CardLast4 = digits
+		}
+		err = matchBankPatterns(&tx, body, "parseHSBCMessage", hsbcCardPatterns)
+	} else {
+		tx.TargetGroup = "HSBC"
+		if match := hsbcAccountPattern.FindStringSubmatch(body); len(match) > 1 {
+			if digits := strings.ReplaceAll(match[1], "-", ""); len(digits) >= 4 {
+				tx.TargetGroup = fmt.Sprintf("HSBC_Account_%s", digits[len(digits)-4:])
+				tx.CardLast4 = digits[len(digits)-4:]
+			}
+		}
+		err = matchBankPatterns(&tx, body, "parseHSBCMessage", hsbcAccountPatterns)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return appendFee(tx, body)
+}
@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseHSBCMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "hsbc.yaml"), func(goldenCase) messageParser {
+		return parseHSBCMessage
+	})
+}
@@ -0,0 +1,156 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+
+	"sms-parser/internal/models"
+)
+
+// inrAmountExpr is an amount that must name its currency, for the shapes that
+// open with the amount and would otherwise take a masked account number for it
+const inrAmountExpr = `(?P<currency>INR)\s*(?P<amount>[\d,]+(?:\.\d{1,2})?)`
+
+// indianBankPatterns are the alert shapes shared by HDFC, ICICI, and SBI,
+// most specific first. They run on bodies whose "Rs." and "₹" amounts were
+// rewritten to "INR" (see indianRupee). Debits come before credits, since
+// UPI debits also name the credited payee.
+var indianBankPatterns = []bankPattern{
+	{
+		// "Rs 2000 withdrawn at SBI ATM S1ABC001 from A/cX1234 on 15Jan26"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)` + inrAmountExpr + `\s+(?:has been\s+|was\s+)?withdrawn`),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "ATM withdrawal of INR 2,000.00 from HDFC Bank A/c XX1234"
+		name:  "atm_prefix",
+		regex: regexp.MustCompile(`(?i)(?:ATM|cash) withdrawal(?:\s+of)?\s*` + amountExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "INR 1,234.00 spent using ICICI Bank Card XX5678 on 15-Jan-26 on AMAZON. Avl Limit: INR 50,000.00"
+		name:  "card_spent",
+		regex: regexp.MustCompile(`(?i)` + inrAmountExpr + `\s+spent\b.*?\bon\s+\d\S*\s+(?:on|at)\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Card Purchase",
+	},
+	{
+		// "Spent Rs.1,234.00 On HDFC Bank Card 5678 At AMAZON On 2026-01-15:10:30:00" /
+		// "transaction of Rs 1234.00 on SBI Debit Card ending 5678 at AMAZON on 15Jan26"
+		name:  "card_purchase",
+		regex: regexp.MustCompile(`(?i)(?:spent|transaction of|purchase of)\s*` + amountExpr + `.*?\bat\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Card Purchase",
+	},
+	{
+		// "ICICI Bank Acct XX123 debited for Rs 500.00 on 15-Jan-26; SWIGGY credited. UPI:401234567890"
+		name:  "upi_debit_credited",
+		regex: regexp.MustCompile(`(?i)debited\s+(?:for|by|with)\s*` + amountExpr + `[^;]*;\s*(?P<payee>.*?)\s+credited`),
+		payee: "Transfer Out",
+	},
+	{
+		// "Sent Rs.500.00 From HDFC Bank A/C *1234 To SWIGGY On 15/01/26 Ref 401234567890"
+		name:  "sent",
+		regex: regexp.MustCompile(`(?i)\bsent\s*` + amountExpr + `(?:.*?\bto\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "Rs.2000.00 debited from A/c **1234 on 15-01-26 to VPA merchant@okaxis (UPI Ref No 401234567890)"
+		name:  "debit_amount_first",
+		regex: regexp.MustCompile(`(?i)` + inrAmountExpr + `\s+(?:has been\s+|is\s+)?debited\b(?:.*?\bto\s+(?:VPA\s+)?(?P<payee>.*?)(?:\s+\(|` + payeeEnd + `))?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "Dear UPI user A/C X1234 debited by 500.0 on date 15Jan26 trf to SWIGGY Refno 401234567890" /
+		// "Your A/C XXXXX1234 Debited INR 2,000.00 on 15/01/26 -Transferred to JOHN DOE. Avl Balance INR 10,000.00"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)debited(?:\s+(?:by|for|with))?\s*` + amountExpr + `(?:.*?\b(?:trf|transferred)\s+to\s+(?P<payee>.*?)(?:\s+Ref\S*\s|` + payeeEnd + `))?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "Received Rs.1500.00 in your HDFC Bank A/c XX1234 from JOHN DOE on 15-01-26" /
+		// "Money Received - INR 1,500.00 in HDFC Bank A/c xx1234 on 15-01-26 by A/c linked to VPA john@okicici (UPI Ref No 401234567890)"
+		name:   "received",
+		regex:  regexp.MustCompile(`(?i)received\s*-?\s*` + amountExpr + `(?:.*?\b(?:from|VPA)\s+(?P<payee>.*?)(?:\s+\(|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Update! INR 5,000.00 deposited in HDFC Bank A/c XX1234 on 15-JAN-26 for NEFT Cr-ACME CORP. Avl bal INR 25,000.00"
+		name:   "credit_amount_first",
+		regex:  regexp.MustCompile(`(?i)` + inrAmountExpr + `\s+(?:has been\s+|is\s+)?(?:deposited|credited)\b(?:.*?(?:\bfrom\s+|\bCr-)(?P<payee>.*?)(?:\s+\(|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "ICICI Bank Account XX123 credited:Rs. 1,500.00 on 15-Jan-26. Info NEFT-ACME CORP." /
+		// "Your A/C XXXXX1234 Credited INR 5,000.00 on 15/01/26 -Deposit by transfer from ACME CORP."
+		name:   "credit",
+		regex:  regexp.MustCompile(`(?i)credited(?:\s*:|\s+(?:by|with))?\s*` + amountExpr + `(?:.*?(?:\bfrom\s+|\bInfo\s+(?:(?:NEFT|IMPS|RTGS|UPI)-)?)(?P<payee>.*?)(?:\s+\(|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Transfer In",
+	},
+}
+
+// indianRupee matches the "Rs." and "₹" amount prefixes
+var indianRupee = regexp.MustCompile(`(?:\bRs\.?|₹)\s*`)
+
+// indianAccountPattern finds the masked account or card number, which ICICI
+// shortens to three digits: "A/c XX1234", "A/cX1234", "Acct XX123"
+var indianAccountPattern = regexp.MustCompile(`(?i)(?:a/c|acct|account|card)\s*(?:no\.?\s*)?(?:ending\s+)?[x*]*\s*(\d{3,4})\b`)
+
+// indianRefPattern finds the UPI or bank reference number
+var indianRefPattern = regexp.MustCompile(`(?i)(?:\bref(?:erence)?\s*(?:no\.?)?|\bUPI)\s*:?\s*(\d{6,})`)
+
+// indianSenderPrefix matches the operator and circle prefix ("VM-", "AD-")
+// and the message category suffix ("-S", "-T") of Indian DLT sender IDs
+var indianSenderPrefix = regexp.MustCompile(`^[A-Z]{2}-|-[SPTG]$`)
+
+// indianSenderID strips the DLT prefix and suffix from an Indian sender ID,
+// so "VM-HDFCBK-S" is looked up as "HDFCBK"
+func indianSenderID(address string) string {
+	return indianSenderPrefix.ReplaceAllString(address, "")
+}
+
+// parseHDFCMessage parses HDFC Bank SMS alerts
+func parseHDFCMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	return parseIndianBankMessage(tx, body, "HDFC", "parseHDFCMessage")
+}
+
+// parseICICIMessage parses ICICI Bank SMS alerts
+func parseICICIMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	return parseIndianBankMessage(tx, body, "ICICI", "parseICICIMessage")
+}
+
+// parseSBIMessage parses State Bank of India SMS alerts
+func parseSBIMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	return parseIndianBankMessage(tx, body, "SBI", "parseSBIMessage")
+}
+
+// parseIndianBankMessage handles the UPI, NEFT/IMPS, card, and ATM alerts of
+// Indian banks, grouped as "<Bank>_Card_XXXX" by the masked account or card
+// number. Amounts without a currency are in INR, and the UPI or bank
+// reference number is kept as the reference.
+func parseIndianBankMessage(tx models.Transaction, body, prefix, function string) ([]models.Transaction, error) {
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	body = indianRupee.ReplaceAllString(body, "INR ")
+
+	extractBalance(&tx, body)
+	tx.TargetGroup = prefix
+	if match := indianAccountPattern.FindStringSubmatch(body); match != nil {
+		tx.TargetGroup = fmt.Sprintf("%s_Card_%s", prefix, match[1])
+		tx.CardLast4 = match[1]
+	}
+
+	if err := matchBankPatterns(&tx, body, function, indianBankPatterns); err != nil {
+		return nil, err
+	}
+	setLocalCurrency(&tx, body, "INR")
+	if match := indianRefPattern.FindStringSubmatch(body); match != nil {
+		tx.Reference = match[1]
+	}
+
+	return appendFee(tx, body)
+}
@@ -0,0 +1,21 @@
+package parser
+
+import "testing"
+
+func TestParseHDFCMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "hdfc.yaml"), func(goldenCase) messageParser {
+		return parseHDFCMessage
+	})
+}
+
+func TestParseICICIMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "icici.yaml"), func(goldenCase) messageParser {
+		return parseICICIMessage
+	})
+}
+
+func TestParseSBIMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "sbi.yaml"), func(goldenCase) messageParser {
+		return parseSBIMessage
+	})
+}
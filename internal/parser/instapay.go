@@ -0,0 +1,114 @@
+package parser
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"time"
+
+	"sms-parser/internal/models"
+)
+
+// instaPayWindow is the maximum time between an InstaPay confirmation and the
+// bank's message about the same transfer
+const instaPayWindow = 15 * time.Minute
+
+// ipaPattern finds an InstaPay address, e.g. "ahmed.ali@instapay"
+var ipaPattern = regexp.MustCompile(`(?i)[a-z0-9._-]+@instapay`)
+
+// parseInstaPayMessage parses InstaPay transfer confirmations. The payee is
+// the counterparty's name, or their InstaPay address (IPA) when the message
+// gives no name.
+func parseInstaPayMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	if err := parseWalletMessage(&tx, body, "InstaPay"); err != nil {
+		return nil, err
+	}
+
+	if ipa := ipaPattern.FindString(body); ipa != "" {
+		// "Ahmed Ali (ahmed.ali@instapay)" keeps the name; a bare IPA is the payee
+		name := strings.TrimSpace(strings.Trim(strings.Replace(tx.Payee, ipa, "", 1), " ()"))
+		switch {
+		case name != "" && tx.Payee != "Transfer In" && tx.Payee != "Transfer Out":
+			tx.Payee = name
+		case tx.Amount != 0:
+			tx.Payee = strings.ToLower(ipa)
+		}
+	}
+
+	return []models.Transaction{tx}, nil
+}
+
+// instaPayMention finds a bank message's mention of InstaPay, or of IPN, the
+// name CIB gives InstaPay transfers
+var instaPayMention = regexp.MustCompile(`(?i)instapay|insta pay|\bIPN\b|انستاباي|إنستاباي|انستا باي|إنستا باي`)
+
+// isInstaPayCounterpart reports whether a bank or wallet transaction can be
+// the bank's side of an InstaPay confirmation: a transfer, or a message that
+// mentions InstaPay. Card purchases of the same amount are left alone.
+func isInstaPayCounterpart(tx models.Transaction) bool {
+	return isTransfer(tx) || instaPayMention.MatchString(tx.Body)
+}
+
+// dropInstaPayDuplicates removes each InstaPay confirmation that repeats a
+// bank or wallet transfer of the same amount and currency within
+// instaPayWindow, since the bank's message already counts the transfer. A
+// generic payee or missing reference on the bank's side is filled in from the
+// confirmation. Each bank transaction absorbs at most one confirmation.
+func dropInstaPayDuplicates(transactions []models.Transaction) []models.Transaction {
+	dropped := make([]bool, len(transactions))
+	bankUsed := make([]bool, len(transactions))
+
+	for i := range transactions {
+		confirmation := &transactions[i]
+		if confirmation.TargetGroup != "InstaPay" {
+			continue
+		}
+
+		match := -1
+		var matchGap time.Duration
+		for j := range transactions {
+			bank := &transactions[j]
+			if bankUsed[j] || bank.TargetGroup == "InstaPay" || bank.Currency != confirmation.Currency {
+				continue
+			}
+			if math.Abs(bank.Amount-confirmation.Amount) >= 0.005 {
+				continue
+			}
+
+			gap := bank.Timestamp.Sub(confirmation.Timestamp)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap > instaPayWindow || !isInstaPayCounterpart(*bank) {
+				continue
+			}
+
+			if match < 0 || gap < matchGap {
+				match = j
+				matchGap = gap
+			}
+		}
+
+		if match < 0 {
+			continue
+		}
+
+		bank := &transactions[match]
+		if bank.Payee == "" || bank.Payee == "Transfer In" || bank.Payee == "Transfer Out" {
+			bank.Payee = confirmation.Payee
+		}
+		if bank.Reference == "" {
+			bank.Reference = confirmation.Reference
+		}
+		bankUsed[match] = true
+		dropped[i] = true
+	}
+
+	kept := make([]models.Transaction, 0, len(transactions))
+	for i, tx := range transactions {
+		if !dropped[i] {
+			kept = append(kept, tx)
+		}
+	}
+	return kept
+}
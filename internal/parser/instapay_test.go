@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"testing"
+	"time"
+
+	"sms-parser/internal/models"
+)
+
+func TestDropInstaPayDuplicates(t *testing.T) {
+	at := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	confirmation := models.Transaction{
+		TargetGroup: "InstaPay",
+		Payee:       "Ahmed Mohamed",
+		Amount:      -500,
+		Currency:    "EGP",
+		Reference:   "771204",
+		Source:      "parseWalletMessage:sent",
+		Body:        "You have sent EGP 500.00 to Ahmed Mohamed via InstaPay. Ref 771204",
+		Timestamp:   at,
+	}
+
+	tests := []struct {
+		name string
+		bank models.Transaction
+		// wantGroups are the groups of the kept transactions, in order
+		wantGroups []string
+		wantPayee  string
+	}{
+		{
+			name: "bank transfer absorbs the confirmation",
+			bank: models.Transaction{
+				TargetGroup: "NBE_Card_1234",
+				Payee:       "Transfer Out",
+				Amount:      -500,
+				Currency:    "EGP",
+				Source:      "parseNBEMessage:transfer_out",
+				Timestamp:   at.Add(2 * time.Minute),
+			},
+			wantGroups: []string{"NBE_Card_1234"},
+			wantPayee:  "Ahmed Mohamed",
+		},
+		{
+			name: "bank message mentioning IPN absorbs the confirmation",
+			bank: models.Transaction{
+				TargetGroup: "CIB_Current_Account",
+				Payee:       "Transfer Out",
+				Amount:      -500,
+				Currency:    "EGP",
+				Source:      "parseCIBCurrentAccount:other",
+				Body:        "Your account **5678 was debited with EGP 500.00 for IPN Outward transfer",
+				Timestamp:   at.Add(-time.Minute),
+			},
+			wantGroups: []string{"CIB_Current_Account"},
+			wantPayee:  "Ahmed Mohamed",
+		},
+		{
+			name: "card purchase of the same amount survives",
+			bank: models.Transaction{
+				TargetGroup: "CIB_Card_4321",
+				Payee:       "CARREFOUR",
+				Amount:      -500,
+				Currency:    "EGP",
+				Source:      "parseCIBDebit:purchase",
+				Body:        "Your credit card ending with 4321 was charged for EGP 500.00 at CARREFOUR",
+				Timestamp:   at.Add(time.Minute),
+			},
+			wantGroups: []string{"InstaPay", "CIB_Card_4321"},
+			wantPayee:  "CARREFOUR",
+		},
+		{
+			name: "transfer outside the window survives",
+			bank: models.Transaction{
+				TargetGroup: "NBE_Card_1234",
+				Payee:       "Transfer Out",
+				Amount:      -500,
+				Currency:    "EGP",
+				Source:      "parseNBEMessage:transfer_out",
+				Timestamp:   at.Add(instaPayWindow + time.Second),
+			},
+			wantGroups: []string{"InstaPay", "NBE_Card_1234"},
+			wantPayee:  "Transfer Out",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := dropInstaPayDuplicates([]models.Transaction{confirmation, tt.bank})
+
+			var groups []string
+			for _, tx := range got {
+				groups = append(groups, tx.TargetGroup)
+			}
+			if len(groups) != len(tt.wantGroups) {
+				t.Fatalf("kept %v, want %v", groups, tt.wantGroups)
+			}
+			for i := range groups {
+				if groups[i] != tt.wantGroups[i] {
+					t.Fatalf("kept %v, want %v", groups, tt.wantGroups)
+				}
+			}
+
+			bank := got[len(got)-1]
+			if bank.Payee != tt.wantPayee {
+				t.Errorf("bank payee = %q, want %q", bank.Payee, tt.wantPayee)
+			}
+		})
+	}
+}
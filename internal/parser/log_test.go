@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+// logBackup has one message for each outcome logMessage reports
+const logBackup = `<smses>
+  <sms address="CIB" date="1736935200000" body="Your credit card ending with 4321 was charged for EGP 250.00 at CARREFOUR on 15/01 at 12:00" />
+  <sms address="CIB" date="1736935210000" body="Your credit card ending with 4321 was charged for EGP 250.00 at CARREFOUR on 15/01 at 12:00" />
+  <sms address="NBE" date="1736938800000" body="Your OTP is 1234" />
+  <sms address="NBE" date="1736940800000" body="Thank you for banking with us" />
+  <sms address="Mom" date="1736942400000" body="Call me" />
+</smses>`
+
+func TestLogMessage(t *testing.T) {
+	var buf bytes.Buffer
+	p := New(Config{Logger: log.New(&buf, "", 0), Location: time.UTC, DedupWindow: time.Minute})
+	if _, _, err := p.Parse(strings.NewReader(logBackup), Options{}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := []string{
+		`sms date=1736935200000 sender="CIB" result=accepted parser=parseCIBCreditCard pattern=charge group="CIB_Credit_Card_4321" amount=-250.00 currency=EGP payee="CARREFOUR" category="Food & Drink"`,
+		`sms date=1736935210000 sender="CIB" result=duplicate`,
+		`sms date=1736938800000 sender="NBE" result=skipped reason="OTP or login message"`,
+		`sms date=1736940800000 sender="NBE" result=discarded reason="no amount matched"`,
+		`sms date=1736942400000 sender="Mom" result=ignored reason="unknown sender"`,
+	}
+	got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(got) != len(want) {
+		t.Fatalf("logged %d lines, want %d:\n%s", len(got), len(want), buf.String())
+	}
+	for i := range want {
+		// Lines with a transaction go on to name its fields; compare the prefix
+		if !strings.HasPrefix(got[i], want[i]) {
+			t.Errorf("line %d = %s\nwant prefix %s", i+1, got[i], want[i])
+		}
+	}
+}
+
+func TestLogMessageSilentByDefault(t *testing.T) {
+	var buf bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&buf)
+
+	if _, _, err := New(Config{}).Parse(strings.NewReader(logBackup), Options{}); err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if buf.Len() > 0 {
+		t.Errorf("Parse() without a Logger wrote %q to the standard logger", buf.String())
+	}
+}
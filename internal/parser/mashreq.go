@@ -0,0 +1,85 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// mashreqPatterns are the Mashreq message shapes, most specific first. The
+// Egyptian and UAE senders share them; UAE accounts report amounts in AED.
+var mashreqPatterns = []bankPattern{
+	{
+		// "Cash withdrawal of AED 500.00 from card ending 1234"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)(?:ATM|cash) withdrawal(?:\s+of)?\s*` + amountExpr + billedExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "Purchase of AED 45.00 at CARREFOUR with your Mashreq Card ending 1234 on 01-JAN-2026" /
+		// "Your card ending 1234 was used for USD 20.00 (AED 73.60) at AMAZON"
+		name:  "purchase",
+		regex: regexp.MustCompile(`(?i)(?:purchase(?:\s+of)?|transaction(?:\s+of)?|used for)\s*` + amountExpr + billedExpr + `.*?\bat\s+(?P<payee>.*?)(?:\s+(?:with|using)\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "AED 500.00 has been transferred from your account XXX5678 to Ahmed Ali on 01-JAN-2026"
+		name:  "transfer_out",
+		regex: regexp.MustCompile(`(?i)` + amountExpr + `\s+(?:has been|was)\s+transferred\s+from\s+your\s+(?:account|a/c)\S*(?:.*?\bto\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "Transfer of EGP 2,000.00 to Ahmed Ali from your account XXX5678"
+		name:  "transfer_out_prefix",
+		regex: regexp.MustCompile(`(?i)transfer(?:\s+of)?\s*` + amountExpr + `(?:\s+to\s+(?:(?:account|a/c)\s*\S+|(?P<payee>.*?)(?:\s+from\s|` + payeeEnd + `)))?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "A refund of AED 45.00 from CARREFOUR has been credited to card ending 1234"
+		name:   "refund",
+		regex:  regexp.MustCompile(`(?i)refund(?:\s+of)?\s*` + amountExpr + billedExpr + `(?:\s+from\s+(?P<payee>.*?)(?:\s+(?:has been|was)\s|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Refund",
+	},
+	{
+		// "Your a/c XXX5678 has been credited with AED 5,000.00"
+		name:   "credit",
+		regex:  regexp.MustCompile(`(?i)credited(?:\s+with|\s+by)?\s*` + amountExpr),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your a/c XXX5678 has been debited with AED 1,000.00"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)debited(?:\s+with|\s+by)?\s*` + amountExpr),
+		payee: "Transfer Out",
+	},
+}
+
+// mashreqOwnCardPattern finds the user's own card or account, which transfers
+// name after the beneficiary's
+var mashreqOwnCardPattern = regexp.MustCompile(`(?i)your\s+(?:mashreq\s+)?(?:account|a/c|card)(?:\s+ending)?\s*(?:\*+|x{2,})?(\d{4})\b`)
+
+// parseMashreqMessage parses Mashreq Egypt and UAE SMS messages
+func parseMashreqMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	if match := mashreqOwnCardPattern.FindStringSubmatch(body); match != nil {
+		tx.TargetGroup = "Mashreq_Card_" + match[1]
+		tx.CardLast4 = match[1]
+	} else {
+		setCardGroup(&tx, body, "Mashreq")
+	}
+
+	if err := matchBankPatterns(&tx, body, "parseMashreqMessage", mashreqPatterns); err != nil {
+		return nil, err
+	}
+
+	return appendFee(tx, body)
+}
@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseMashreqMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "mashreq.yaml"), func(goldenCase) messageParser {
+		return parseMashreqMessage
+	})
+}
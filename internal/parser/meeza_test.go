@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseMeezaMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "meeza.yaml"), func(goldenCase) messageParser {
+		return parseMeezaMessage
+	})
+}
@@ -0,0 +1,95 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+)
+
+// mpesaPatterns are the M-Pesa confirmation shapes, most specific first. They
+// run on bodies whose "Ksh" amounts were rewritten to "KES" (see mpesaKsh).
+var mpesaPatterns = []bankPattern{
+	{
+		// "QAB1CD2EF3 Confirmed. Reversal of transaction QAA1BC2DE3 has been successfully reversed.
+		// KES 1,500.00 is credited to your M-PESA account"
+		name:   "reversal",
+		regex:  regexp.MustCompile(`(?i)` + amountExpr + `\s+(?:is|has been)\s+credited`),
+		income: true,
+		payee:  "Reversal",
+	},
+	{
+		// "QAB1CD2EF4 Confirmed.You have received KES 2,000.00 from JANE DOE 0722000000 on 15/1/26 at 11:00 AM"
+		name:   "received",
+		regex:  regexp.MustCompile(`(?i)received\s*` + amountExpr + `(?:\s+from\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "QAB1CD2EF5 Confirmed. KES 1,200.00 sent to KPLC PREPAID for account 12345678 on 15/1/26 at 12:00 PM"
+		name:  "paybill",
+		regex: regexp.MustCompile(`(?i)` + amountExpr + `\s+sent to\s+(?P<payee>.*?)\s+for account\b`),
+		payee: "Bill Payment",
+	},
+	{
+		// "QAB1CD2EF3 Confirmed. KES 1,500.00 sent to JOHN DOE 0712345678 on 15/1/26 at 10:30 AM"
+		name:  "sent",
+		regex: regexp.MustCompile(`(?i)` + amountExpr + `\s+sent to\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Transfer Out",
+	},
+	{
+		// "QAB1CD2EF6 Confirmed. KES 350.00 paid to NAIVAS SUPERMARKET. on 15/1/26 at 1:00 PM"
+		name:  "buy_goods",
+		regex: regexp.MustCompile(`(?i)` + amountExpr + `\s+paid to\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Merchant Payment",
+	},
+	{
+		// "QAB1CD2EF7 Confirmed.on 15/1/26 at 2:00 PMWithdraw KES 2,000.00 from 123456 - AGENT NAME New M-PESA balance is KES 1,700.00"
+		name:  "withdraw",
+		regex: regexp.MustCompile(`(?i)withdraw\s*` + amountExpr),
+		payee: "Cash Withdrawal",
+	},
+	{
+		// "QAB1CD2EF8 confirmed.You bought KES 100.00 of airtime on 15/1/26 at 3:00 PM"
+		name:  "airtime",
+		regex: regexp.MustCompile(`(?i)bought\s*` + amountExpr + `\s+of airtime`),
+		payee: "Airtime",
+	},
+	{
+		// "QAB1CD2EF9 Confirmed. On 15/1/26 at 4:00 PM Give KES 1,000.00 cash to AGENT NAME"
+		name:   "deposit",
+		regex:  regexp.MustCompile(`(?i)give\s*` + amountExpr + `\s+cash to`),
+		income: true,
+		payee:  "Cash Deposit",
+	},
+}
+
+// mpesaKsh matches M-Pesa's "Ksh1,500.00" amount prefix
+var mpesaKsh = regexp.MustCompile(`\bKsh\.?\s*`)
+
+// mpesaCodePattern finds the transaction code that opens every confirmation
+var mpesaCodePattern = regexp.MustCompile(`^\s*([A-Z0-9]{10})\s+[Cc]onfirmed`)
+
+// parseMPesaMessage parses Safaricom M-Pesa confirmations: transfers sent
+// and received, paybill and till payments, agent withdrawals and deposits,
+// and airtime. All of them go to the MPesa group in KES, with the
+// transaction code as the reference and the transaction cost as a fee.
+func parseMPesaMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	body = mpesaKsh.ReplaceAllString(body, "KES ")
+
+	tx.TargetGroup = "MPesa"
+	if err := matchBankPatterns(&tx, body, "parseMPesaMessage", mpesaPatterns); err != nil {
+		return nil, err
+	}
+	tx.Currency = "KES"
+
+	extractBalance(&tx, body)
+	if match := mpesaCodePattern.FindStringSubmatch(body); match != nil {
+		tx.Reference = match[1]
+	}
+
+	return appendFee(tx, body)
+}
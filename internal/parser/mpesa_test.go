@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseMPesaMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "mpesa.yaml"), func(goldenCase) messageParser {
+		return parseMPesaMessage
+	})
+}
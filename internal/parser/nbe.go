@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// nbePatterns are the National Bank of Egypt message shapes, most specific first
+var nbePatterns = []bankPattern{
+	{
+		// "تم سحب مبلغ 2,000.00 جنيه من ماكينة الصراف الآلي" / "ATM withdrawal of EGP 2,000.00"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)(?:تم سحب(?:\s*نقدي)?(?:\s*مبلغ)?|(?:ATM|cash) withdrawal(?:\s+of)?)\s*` + amountExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "تم خصم مبلغ 250.00 جنيه من بطاقتكم رقم ****1234 لدى CARREFOUR في 01/01"
+		name:  "purchase_ar",
+		regex: regexp.MustCompile(`تم خصم(?:\s*مبلغ)?\s*` + amountExpr + `\s*من\s*بطاق.*?(?:لدى|عند)\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Card Purchase",
+	},
+	{
+		// "Purchase of EGP 250.00 with card ****1234 at CARREFOUR on 01/01"
+		name:  "purchase_en",
+		regex: regexp.MustCompile(`(?i)(?:purchase|transaction)(?:\s+of|\s+with|\s+for)?\s*` + amountExpr + `.*?\bat\s+(?P<payee>.*?)` + payeeEnd),
+		payee: "Card Purchase",
+	},
+	{
+		// "تم تحويل مبلغ 1,000 جنيه من حسابكم رقم ****5678 الى أحمد"
+		name:  "transfer_out_ar",
+		regex: regexp.MustCompile(`تم تحويل(?:\s*مبلغ)?\s*` + amountExpr + `\s*من\s*حساب\S*(?:.*?(?:الى|إلى)\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "تم اضافة مبلغ 5,000 جنيه الى حسابكم رقم ****5678"
+		name:   "transfer_in_ar",
+		regex:  regexp.MustCompile(`(?:تم (?:اضافة|إضافة|ايداع|إيداع|تحويل))(?:\s*مبلغ)?\s*` + amountExpr + `\s*(?:الى|إلى|ل)\s*حساب`),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your account ****5678 has been credited with EGP 5,000.00"
+		name:   "credit_en",
+		regex:  regexp.MustCompile(`(?i)credited(?:\s+with|\s+by)?\s*` + amountExpr),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your account ****5678 has been debited with EGP 1,000.00"
+		name:  "debit_en",
+		regex: regexp.MustCompile(`(?i)debited(?:\s+with|\s+by)?\s*` + amountExpr),
+		payee: "Transfer Out",
+	},
+}
+
+// parseNBEMessage parses National Bank of Egypt SMS messages
+func parseNBEMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	// Payroll Meeza cards issued by the bank get their own group
+	if meezaMention.MatchString(body) {
+		return parseMeezaMessage(tx, body)
+	}
+
+	extractBalance(&tx, body)
+	setCardGroup(&tx, body, "NBE")
+
+	if err := matchBankPatterns(&tx, body, "parseNBEMessage", nbePatterns); err != nil {
+		return nil, err
+	}
+
+	return appendFee(tx, body)
+}
@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseNBEMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "nbe.yaml"), func(goldenCase) messageParser {
+		return parseNBEMessage
+	})
+}
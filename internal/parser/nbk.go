@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// nbkPatterns are the National Bank of Kuwait message shapes, most specific
+// first. Amounts have three decimals ("KWD 12.500").
+var nbkPatterns = []bankPattern{
+	{
+		// "Cash withdrawal of KWD 50.000 from your card ending 1234 at NBK ATM SHARQ"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)(?:(?:ATM|cash) withdrawal(?:\s+of)?|سحب(?:\s*نقدي)?(?:\s*(?:بمبلغ|مبلغ))?)\s*` + amountExpr + billedExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "Your account XXXX1234 has been debited by KWD 12.500 for POS purchase at SULTAN CENTER on 15/01/2026. Ref No: 123456789" /
+		// "Purchase of USD 20.00 (KWD 6.150) at NETFLIX.COM with card ending 1234"
+		name:  "purchase",
+		regex: regexp.MustCompile(`(?i)(?:debited(?:\s+by|\s+with)?|purchase(?:\s+of)?|used for)\s*` + amountExpr + billedExpr + `.*?\bat\s+(?P<payee>.*?)(?:\s+(?:with|using)\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "تم خصم مبلغ 12.500 د.ك من حسابك رقم 1234 لدى SULTAN CENTER بتاريخ 15/01/2026"
+		name:  "purchase_ar",
+		regex: regexp.MustCompile(`(?:شراء|تم خصم)\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr + billedExpr + `.*?(?:لدى|عند)\s+(?P<payee>.*?)(?:\s+بالبطاق\S*\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "A refund of KWD 12.500 from SULTAN CENTER has been credited to your card ending 1234"
+		name:   "refund",
+		regex:  regexp.MustCompile(`(?i)refund(?:\s+of)?\s*` + amountExpr + billedExpr + `(?:\s+from\s+(?P<payee>.*?)(?:\s+(?:has been|was)\s|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Refund",
+	},
+	{
+		// "Your account XXXX1234 has been credited by KWD 850.000 Salary. Ref No: 123456789" /
+		// "تم إيداع مبلغ 850.000 د.ك في حسابك رقم 1234"
+		name:   "credit",
+		regex:  regexp.MustCompile(`(?i)(?:credited(?:\s+with|\s+by)?|تم (?:ايداع|إيداع|اضافة|إضافة)(?:\s*مبلغ)?)\s*` + amountExpr),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your account XXXX1234 has been debited by KWD 100.000 for transfer to AHMED ALI. Ref No: 123456789" /
+		// "تم خصم مبلغ 100.000 د.ك من حسابك رقم 1234"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)(?:debited(?:\s+with|\s+by)?|تم (?:خصم|تحويل)(?:\s*مبلغ)?)\s*` + amountExpr + `(?:.*?\btransfer\s+to\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		payee: "Transfer Out",
+	},
+}
+
+// parseNBKMessage parses National Bank of Kuwait SMS messages. Amounts
+// without a currency are in KWD, and the "Ref No" is kept as the reference.
+func parseNBKMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	setCardGroup(&tx, body, "NBK")
+
+	if err := matchBankPatterns(&tx, body, "parseNBKMessage", nbkPatterns); err != nil {
+		return nil, err
+	}
+	setLocalCurrency(&tx, body, "KWD")
+	extractReference(&tx, body)
+
+	return appendFee(tx, body)
+}
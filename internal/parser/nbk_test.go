@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseNBKMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "nbk.yaml"), func(goldenCase) messageParser {
+		return parseNBKMessage
+	})
+}
@@ -0,0 +1,53 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// orangeMoneyPatterns are the Orange Money shapes that are not plain P2P
+// transfers, which parseWalletMessage handles
+var orangeMoneyPatterns = []bankPattern{
+	{
+		// "Your Orange Money wallet has been topped up with EGP 200.00" /
+		// "تم شحن محفظتك بمبلغ 200 جنيه"
+		name:   "top_up",
+		regex:  regexp.MustCompile(`(?i)(?:topped up(?:\s+with|\s+by)?|top[- ]?up(?:\s+of)?|(?:تم\s*)?(?:شحن|ايداع|إيداع)\s*(?:محفظت\S*|رصيد\S*)?\s*(?:بمبلغ|مبلغ)?)\s*` + amountExpr),
+		income: true,
+		payee:  "Wallet Top Up",
+	},
+	{
+		// "You paid EGP 150.00 to merchant CARREFOUR. Ref 12345" /
+		// "تم دفع مبلغ 150 جنيه للتاجر CARREFOUR"
+		name:  "merchant_payment",
+		regex: regexp.MustCompile(`(?i)(?:paid|payment of|تم (?:دفع|سداد)(?:\s*مبلغ)?)\s*` + amountExpr + `\s*(?:to\s+merchant|at|(?:لل|ل)\s*تاجر|لدى)\s*(?P<payee>.*?)` + payeeEnd),
+		payee: "Merchant Payment",
+	},
+}
+
+// parseOrangeMoneyMessage parses Orange Money wallet notifications: top-ups,
+// merchant payments, and P2P transfers
+func parseOrangeMoneyMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	tx.TargetGroup = "Orange_Money"
+	if err := matchBankPatterns(&tx, body, "parseOrangeMoneyMessage", orangeMoneyPatterns); err != nil {
+		return nil, err
+	}
+	if tx.Source == "" {
+		if err := parseWalletMessage(&tx, body, "Orange_Money"); err != nil {
+			return nil, err
+		}
+		return []models.Transaction{tx}, nil
+	}
+
+	extractBalance(&tx, body)
+	extractReference(&tx, body)
+	return []models.Transaction{tx}, nil
+}
@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseOrangeMoneyMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "orangemoney.yaml"), func(goldenCase) messageParser {
+		return parseOrangeMoneyMessage
+	})
+}
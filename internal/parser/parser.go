@@ -1,119 +1,687 @@
 package parser
 
 import (
-	"encoding/xml"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
-	"strconv"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"sms-parser/internal/categorizer"
 	"sms-parser/internal/models"
+	"sms-parser/internal/rules"
+	"sms-parser/internal/utils"
+	"sms-parser/pkg/bankparser"
 )
 
+// messageParser turns the body of a single SMS into transactions. tx comes
+// pre-filled with the message date and defaults; parsers return it filled in,
+// followed by any extra line items such as fees. It returns one of the
+// errors isSkipped recognizes for messages that are intentionally not
+// transactions and ErrInvalidAmount for amounts that cannot be parsed.
+type messageParser func(tx models.Transaction, body string) ([]models.Transaction, error)
+
+// errSkipped marks OTP, login, and similar non-transaction messages
+var errSkipped = errors.New("OTP or login message")
+
+// errStatement marks card statement summaries, whose amounts repeat the
+// transactions already reported one by one
+var errStatement = errors.New("card statement summary")
+
+// errReminder marks reminders of upcoming payments, which are reported
+// again once paid
+var errReminder = errors.New("payment reminder")
+
+// reminderPattern and paidPattern tell reminders of upcoming payments apart
+// from confirmations that mention the next due date
+var (
+	reminderPattern = regexp.MustCompile(`(?i)\bdue\b|reminder|تذكير|مستحق|يستحق`)
+	paidPattern     = regexp.MustCompile(`(?i)\bpaid\b|received|تم سداد|تم دفع|تم استلام`)
+)
+
+// isReminder reports whether body reminds of a payment rather than confirming one
+func isReminder(body string) bool {
+	return reminderPattern.MatchString(body) && !paidPattern.MatchString(body)
+}
+
+// errSkipWord marks messages containing one of a rules bank's skip words
+var errSkipWord = errors.New("contains a skip word")
+
+// isSkipped reports whether err marks a message that is intentionally not
+// a transaction
+func isSkipped(err error) bool {
+	return errors.Is(err, errSkipped) || errors.Is(err, errStatement) || errors.Is(err, errReminder) ||
+		errors.Is(err, errSkipWord) || errors.Is(err, bankparser.ErrSkipped)
+}
+
+// reviewThresholds is the smallest uncategorized expense flagged for review
+// in each currency, about a dollar or two; smaller ones are not worth a
+// keyword. Expenses in other currencies are always flagged.
+var reviewThresholds = map[string]float64{
+	"EGP": 50,
+	"USD": 2, "EUR": 2, "GBP": 2,
+	"SAR": 5, "AED": 5, "QAR": 5,
+	"KWD": 0.5, "BHD": 0.5, "OMR": 0.5, "JOD": 1,
+	"INR": 100, "KES": 200,
+}
+
+// needsReview reports whether an uncategorized transaction should be flagged
+// for review: an expense of at least its currency's threshold that is not a
+// transfer, since transfers to people have no merchant to add a keyword for
+func needsReview(tx models.Transaction, transfer bool) bool {
+	return !transfer && tx.Amount < 0 && -tx.Amount >= reviewThresholds[tx.Currency]
+}
+
+// transferShapes are the message shapes, as the pattern part of
+// Transaction.Source, that send money to a person or account
+var transferShapes = []string{
+	"transfer", "transfer_out", "transfer_out_ar", "transfer_out_prefix", "to_account", "sent",
+	"transfer_in", "transfer_in_ar", "received",
+}
+
+// isTransfer reports whether tx moves money to or from a person or account
+// rather than paying a merchant
+func isTransfer(tx models.Transaction) bool {
+	if tx.Payee == "Transfer In" || tx.Payee == "Transfer Out" ||
+		tx.Source == "parseCIBCurrentAccount:debit" || tx.Source == "parseCIBCurrentAccount:ipn" {
+		return true
+	}
+	_, shape, _ := strings.Cut(tx.Source, ":")
+	return slices.Contains(transferShapes, shape)
+}
+
+// isOTPMessage reports whether a message is a one-time password or login notice
+func isOTPMessage(body string) bool {
+	return utils.Contains(body, "OTP", "password", "تسجيل الدخول", "code")
+}
+
 // Parser handles SMS backup parsing
 type Parser struct {
-	categorizer *categorizer.Categorizer
+	categorizer     *categorizer.Categorizer
+	parsers         map[string]messageParser
+	detectTransfers bool
+	aliases         map[string]string
+	excluded        map[string]bool
+	location        *time.Location
+
+	skipInstallments bool
+	includePending   bool
+	keepInstaPay     bool
+	skipUnknown      bool
+	noteMode         string
+	redact           bool
+
+	// seenMessages is shared across Parse calls so that overlapping
+	// backups parsed by the same Parser are deduplicated
+	seenMessages map[dedupKey][]time.Time
+	dedupWindow  time.Duration
+
+	logger *log.Logger
+}
+
+// Config holds optional Parser settings
+type Config struct {
+	// Rules adds or overrides sender parsers with declarative definitions;
+	// nil keeps only the built-in CIB and Banque Misr parsers
+	Rules *rules.RuleSet
+
+	// Plugins add or override sender parsers with externally built ones;
+	// rules still take precedence over them
+	Plugins []bankparser.BankParser
+
+	// Categorizer assigns categories to parsed transactions; nil uses the
+	// built-in keyword lists
+	Categorizer *categorizer.Categorizer
+
+	// DetectTransfers pairs outgoing and incoming legs between groups and
+	// marks them as internal transfers instead of expense/income
+	DetectTransfers bool
+
+	// DedupWindow collapses messages with the same sender and body that arrive
+	// within this duration of each other; zero only drops exact duplicates
+	DedupWindow time.Duration
+
+	// CIBCards maps the last four digits of your CIB cards and accounts to
+	// their type and group name. Debit cards and current accounts are
+	// grouped as CIB_Current_Debit unless named; every other CIB card is
+	// treated as a credit card.
+	CIBCards map[string]CIBCard
+
+	// Aliases maps payee substrings to canonical payee names (see
+	// utils.NormalizePayee); nil keeps payees as the parsers produced them
+	Aliases map[string]string
+
+	// ExcludeCategories drops transactions in these categories from the
+	// output after categorization and transfer detection
+	ExcludeCategories []string
+
+	// Location is the timezone transaction dates are shown in and the
+	// --from/--to days are interpreted in; nil means the local timezone
+	Location *time.Location
+
+	// SkipInstallments leaves purchases converted to installments out of the
+	// output, since the original charge is already counted
+	SkipInstallments bool
+
+	// IncludePending keeps pending card authorizations, dropping only those
+	// superseded by a later settled charge; by default all are dropped
+	IncludePending bool
+
+	// KeepInstaPayDuplicates keeps InstaPay confirmations that repeat a
+	// transfer the bank also reported; by default only the bank's is kept
+	KeepInstaPayDuplicates bool
+
+	// SkipUnknownSenders ignores every message from a sender without a
+	// parser; by default transaction-like messages from business sender IDs
+	// are parsed by a generic extractor into "Unknown_<sender>" groups
+	SkipUnknownSenders bool
+
+	// NoteMode controls how much of the message ends up in the note (one of
+	// NoteModes); empty means NoteFull
+	NoteMode string
+
+	// Redact masks card, account, phone, and reference numbers in notes
+	// (see utils.RedactSensitive)
+	Redact bool
+
+	// Logger receives one line per message describing how it was parsed;
+	// nil keeps parsing silent
+	Logger *log.Logger
 }
 
+// Note modes
+const (
+	// NoteFull keeps the whole message body after the category tag
+	NoteFull = "full"
+	// NoteCategoryOnly keeps the category tag and markers such as
+	// [Pending], dropping the message body
+	NoteCategoryOnly = "category-only"
+	// NoteNone leaves the note empty; the category keeps its own column
+	NoteNone = "none"
+)
+
+// NoteModes lists the valid Config.NoteMode values
+var NoteModes = []string{NoteFull, NoteCategoryOnly, NoteNone}
+
 // New creates a new Parser instance
-func New() *Parser {
+func New(cfg Config) *Parser {
+	parsers := map[string]messageParser{
+		"CIB":             newCIBParser(cfg.CIBCards),
+		"Banque Misr":     parseBanqueMisrMessage,
+		"Mashreq":         parseMashreqMessage,
+		"MASHREQ":         parseMashreqMessage,
+		"MashreqEG":       parseMashreqMessage,
+		"NBE":             parseNBEMessage,
+		"QNB":             parseQNBMessage,
+		"QNB ALAHLI":      parseQNBMessage,
+		"HSBC":            parseHSBCMessage,
+		"AlexBank":        parseAlexBankMessage,
+		"ALEXBANK":        parseAlexBankMessage,
+		"BDC":             parseBDCMessage,
+		"Banque du Caire": parseBDCMessage,
+		"ADIB":            parseADIBMessage,
+		"AAIB":            parseAAIBMessage,
+		"CA-EGYPT":        parseCreditAgricoleMessage,
+		"EmiratesNBD":     parseEmiratesNBDMessage,
+		"ENBD":            parseEmiratesNBDMessage,
+		"InstaPay":        parseInstaPayMessage,
+		"VodafoneCash":    parseVodafoneCashMessage,
+		"Vodafone Cash":   parseVodafoneCashMessage,
+		"OrangeMoney":     parseOrangeMoneyMessage,
+		"Orange Money":    parseOrangeMoneyMessage,
+		"EtisalatCash":    parseEtisalatCashMessage,
+		"e& money":        parseEtisalatCashMessage,
+		"Fawry":           parseFawryMessage,
+		"FAWRY":           parseFawryMessage,
+		"myFawry":         parseFawryMessage,
+		"Telda":           parseTeldaMessage,
+		"ValU":            parseValUMessage,
+		"VALU":            parseValUMessage,
+		"Halan":           parseHalanMessage,
+		"HALAN":           parseHalanMessage,
+		"Aman":            parseAmanMessage,
+		"AMAN":            parseAmanMessage,
+		"Contact":         parseContactMessage,
+		"CONTACT":         parseContactMessage,
+		"Meeza":           parseMeezaMessage,
+		"MEEZA":           parseMeezaMessage,
+		"AlRajhiBank":     parseAlRajhiMessage,
+		"AlRajhi":         parseAlRajhiMessage,
+		"SNB-AlAhli":      parseSNBMessage,
+		"SNB":             parseSNBMessage,
+		"SAB":             parseSABMessage,
+		"FAB":             parseFABMessage,
+		"RAKBANK":         parseRAKBANKMessage,
+		"MPESA":           parseMPesaMessage,
+		"M-PESA":          parseMPesaMessage,
+		"HDFCBK":          parseHDFCMessage,
+		"ICICIB":          parseICICIMessage,
+		"ICICIT":          parseICICIMessage,
+		"SBIINB":          parseSBIMessage,
+		"SBIPSG":          parseSBIMessage,
+		"CBSSBI":          parseSBIMessage,
+		"ATMSBI":          parseSBIMessage,
+		"NBK":             parseNBKMessage,
+		"ArabBank":        parseArabBankMessage,
+		"Arab Bank":       parseArabBankMessage,
+	}
+
+	for _, plugin := range cfg.Plugins {
+		parse := newPluginParser(plugin)
+		for _, sender := range plugin.Senders() {
+			parsers[sender] = parse
+		}
+	}
+
+	if cfg.Rules != nil {
+		for _, bank := range cfg.Rules.Banks {
+			parse := newRulesParser(bank)
+			for _, sender := range bank.Senders {
+				parsers[sender] = parse
+			}
+		}
+	}
+
+	cat := cfg.Categorizer
+	if cat == nil {
+		// The built-in keyword lists need no file, so this cannot fail
+		cat, _ = categorizer.New("")
+	}
+
+	logger := cfg.Logger
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+
+	location := cfg.Location
+	if location == nil {
+		location = time.Local
+	}
+
+	noteMode := cfg.NoteMode
+	if noteMode == "" {
+		noteMode = NoteFull
+	}
+
+	excluded := make(map[string]bool, len(cfg.ExcludeCategories))
+	for _, category := range cfg.ExcludeCategories {
+		excluded[category] = true
+	}
+
 	return &Parser{
-		categorizer: categorizer.New(),
+		categorizer:     cat,
+		parsers:         parsers,
+		detectTransfers: cfg.DetectTransfers,
+		aliases:         cfg.Aliases,
+		excluded:        excluded,
+		location:        location,
+
+		skipInstallments: cfg.SkipInstallments,
+		includePending:   cfg.IncludePending,
+		keepInstaPay:     cfg.KeepInstaPayDuplicates,
+		skipUnknown:      cfg.SkipUnknownSenders,
+		noteMode:         noteMode,
+		redact:           cfg.Redact,
+
+		seenMessages: make(map[dedupKey][]time.Time),
+		dedupWindow:  cfg.DedupWindow,
+
+		logger: logger,
+	}
+}
+
+// Options holds the message filters applied while parsing
+type Options struct {
+	// Senders keeps only messages from these addresses; empty keeps all
+	Senders []string
+	// StartDate keeps messages from this day onwards (YYYY-MM-DD); empty is unbounded
+	StartDate string
+	// EndDate keeps messages up to and including this day (YYYY-MM-DD); empty is unbounded
+	EndDate string
+}
+
+// ParseBytes parses an in-memory SMS backup XML document with the built-in
+// bank parsers and categories, without touching the filesystem
+func ParseBytes(data []byte, opts Options) ([]models.Account, error) {
+	transactions, _, err := New(Config{}).Parse(bytes.NewReader(data), opts)
+	return transactions, err
+}
+
+// ParseFile reads and parses an SMS backup XML file with optional filters.
+// Besides the accounts, sorted by name, it returns the messages from known
+// senders that did not produce a usable transaction.
+func (p *Parser) ParseFile(filePath string, opts Options) ([]models.Account, []models.UnparsedMessage, error) {
+	transactions, unparsed, err := p.CollectFile(filePath, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.Finish(transactions), unparsed, nil
+}
+
+// Parse reads and parses an SMS backup XML document from r with optional
+// filters. Gzip-compressed input is detected and decompressed automatically.
+func (p *Parser) Parse(r io.Reader, opts Options) ([]models.Account, []models.UnparsedMessage, error) {
+	transactions, unparsed, err := p.Collect(r, opts)
+	if err != nil {
+		return nil, nil, err
 	}
+	return p.Finish(transactions), unparsed, nil
 }
 
-// ParseFile reads and parses an SMS backup XML file with optional filters
-func (p *Parser) ParseFile(filePath, senderFilter, startDateFilter string) (map[string][]models.Transaction, error) {
-	// Read XML file
-	xmlFile, err := os.ReadFile(filePath)
+// CollectFile is Collect for an SMS backup XML file
+func (p *Parser) CollectFile(filePath string, opts Options) ([]models.Transaction, []models.UnparsedMessage, error) {
+	file, err := os.Open(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("error reading file: %w", err)
+		return nil, nil, fmt.Errorf("error reading file: %w", err)
 	}
+	defer file.Close()
 
-	// Parse XML
-	var backup models.SMSBackup
-	if err := xml.Unmarshal(xmlFile, &backup); err != nil {
-		return nil, fmt.Errorf("error parsing XML: %w", err)
+	return p.Collect(file, opts)
+}
+
+// Collect reads the transactions of one of several SMS backups, without the
+// steps that compare messages with each other. Pass the transactions of all
+// the backups to Finish, so a transfer whose two legs are in different
+// backups is still paired.
+func (p *Parser) Collect(r io.Reader, opts Options) ([]models.Transaction, []models.UnparsedMessage, error) {
+	// Transparently decompress gzip-compressed backups
+	r, err := maybeDecompress(r)
+	if err != nil {
+		return nil, nil, err
 	}
 
+	return p.parseMessages(newSMSDecoder(r), opts)
+}
+
+// messageSource yields the messages of a backup one at a time, returning
+// io.EOF after the last one
+type messageSource interface {
+	Next() (models.SMS, error)
+}
+
+// parseMessages parses the messages read from source with optional filters
+func (p *Parser) parseMessages(source messageSource, opts Options) ([]models.Transaction, []models.UnparsedMessage, error) {
+	var err error
+
 	// Parse start date filter if provided
 	var startDate time.Time
-	if startDateFilter != "" {
-		startDate, err = time.Parse("2006-01-02", startDateFilter)
+	if opts.StartDate != "" {
+		startDate, err = time.ParseInLocation("2006-01-02", opts.StartDate, p.location)
 		if err != nil {
-			return nil, fmt.Errorf("invalid date format (use YYYY-MM-DD): %w", err)
+			return nil, nil, fmt.Errorf("invalid date format (use YYYY-MM-DD): %w", err)
 		}
 	}
 
-	// Initialize grouped data - will be populated dynamically
-	groupedData := map[string][]models.Transaction{}
+	// Parse end date filter if provided; the whole end day is included
+	var endDate time.Time
+	if opts.EndDate != "" {
+		endDay, err := time.ParseInLocation("2006-01-02", opts.EndDate, p.location)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid date format (use YYYY-MM-DD): %w", err)
+		}
+		endDate = endDay.AddDate(0, 0, 1)
+	}
+
+	senders := make(map[string]bool, len(opts.Senders))
+	for _, sender := range opts.Senders {
+		senders[sender] = true
+	}
 
-	seenTransactions := make(map[string]bool)
+	var transactions []models.Transaction
+	var unparsed []models.UnparsedMessage
+
+	// Stream messages out of the XML document
+	for {
+		sms, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("error parsing XML: %w", gzipError(err))
+		}
 
-	for _, sms := range backup.SMS {
 		// Apply sender filter
-		if senderFilter != "" && sms.Address != senderFilter {
+		if len(senders) > 0 && !senders[sms.Address] {
 			continue
 		}
 
-		// Create message signature for deduplication
-		msgSignature := fmt.Sprintf("%s|%s|%s", sms.Date, sms.Address, sms.Body)
-		if seenTransactions[msgSignature] {
+		// Messages without a parser, such as personal ones, are neither
+		// deduplicated nor kept
+		parse, ok := p.parserFor(sms.Address, sms.Body)
+		if !ok {
+			p.logMessage(sms, nil, "ignored", "unknown sender")
 			continue
 		}
-		seenTransactions[msgSignature] = true
 
-		// Parse date
-		dateMs, err := strconv.ParseInt(sms.Date, 10, 64)
+		// Parse date, falling back to readable_date without a valid epoch
+		dateObj, err := sms.Time(p.location)
 		if err != nil {
 			continue
 		}
-		dateObj := time.Unix(dateMs/1000, 0)
+
+		// Skip re-sent copies of a message already seen
+		key := newDedupKey(sms)
+		if isDuplicate(p.seenMessages[key], dateObj, p.dedupWindow) {
+			p.logMessage(sms, nil, "duplicate", "")
+			continue
+		}
+		p.seenMessages[key] = append(p.seenMessages[key], dateObj)
+		dateObj = dateObj.Truncate(time.Second)
 
 		// Apply date filter
 		if !startDate.IsZero() && dateObj.Before(startDate) {
 			continue
 		}
+		if !endDate.IsZero() && !dateObj.Before(endDate) {
+			continue
+		}
 
 		dateStr := dateObj.Format("2006-01-02 15:04:05")
 
 		tx := models.Transaction{
-			Date:     dateStr,
-			Payee:    "",
-			Amount:   0.0,
-			Currency: "EGP",
-			Type:     models.TypeExpense,
-			Category: models.CatGeneral,
-			Note:     sms.Body,
+			Date:      dateStr,
+			Timestamp: dateObj,
+			Payee:     "",
+			Amount:    0.0,
+			Currency:  "EGP",
+			Type:      models.TypeExpense,
+			Category:  models.CatGeneral,
+			Note:      sms.Body,
 		}
 
-		// Parse based on sender
-		switch sms.Address {
-		case "CIB":
-			parseCIBMessage(&tx, sms.Body)
-		case "Banque Misr":
-			parseBanqueMisrMessage(&tx, sms.Body)
+		parsed, err := parse(tx, sms.Body)
+		if err != nil {
+			result := "discarded"
+			if isSkipped(err) {
+				result = "skipped"
+			}
+			p.logMessage(sms, &tx, result, err.Error())
+			unparsed = append(unparsed, models.UnparsedMessage{
+				SMS:     sms,
+				Reason:  err.Error(),
+				Skipped: isSkipped(err),
+				Err:     err,
+			})
+			continue
 		}
 
-		// Apply categorization
-		if tx.TargetGroup != "" && tx.Amount != 0 && tx.Category == models.CatGeneral {
-			tx.Category = p.categorizer.Categorize(tx.Payee, tx.Note, tx.Amount)
-		}
+		for _, tx := range parsed {
+			transfer := isTransfer(tx)
+
+			// Transfers whose body names no counterparty fall back to the
+			// sender's contact name, when the backup has one
+			if (tx.Payee == "Transfer In" || tx.Payee == "Transfer Out") && sms.ContactName != "" && sms.ContactName != "(Unknown)" {
+				tx.Payee = sms.ContactName
+			}
+			tx.Payee = utils.NormalizePayee(tx.Payee, p.aliases)
 
-		// Add category to note and append to group
-		if tx.TargetGroup != "" && tx.Amount != 0 {
-			if _, exists := groupedData[tx.TargetGroup]; !exists {
-				groupedData[tx.TargetGroup] = []models.Transaction{}
+			// Apply categorization; expenses no keyword matched are flagged
+			// so their merchants can be added to the keyword lists
+			if tx.TargetGroup != "" && tx.Amount != 0 && tx.Category == models.CatGeneral {
+				match := p.categorizer.Classify(tx.Payee, tx.Note, tx.Amount)
+				tx.Category = match.Category
+				if match.Category == models.CatGeneral && needsReview(tx, transfer) {
+					tx.Tags = append(tx.Tags, models.TagNeedsReview)
+					tx.Note = "[NeedsReview] " + tx.Note
+				}
 			}
 
-			if tx.Category != models.CatGeneral {
-				tx.Note = fmt.Sprintf("[%s] %s", tx.Category, tx.Note)
+			switch {
+			case tx.TargetGroup == "":
+				p.logMessage(sms, &tx, "discarded", "no account matched")
+				unparsed = append(unparsed, models.UnparsedMessage{SMS: sms, Reason: "no account matched"})
+			case tx.Amount == 0:
+				p.logMessage(sms, &tx, "discarded", "no amount matched")
+				unparsed = append(unparsed, models.UnparsedMessage{SMS: sms, Reason: "no amount matched"})
+			case tx.Pending && !p.includePending:
+				p.logMessage(sms, &tx, "skipped", "pending authorization")
+				unparsed = append(unparsed, models.UnparsedMessage{SMS: sms, Reason: "pending authorization", Skipped: true})
+			case p.skipInstallments && tx.HasTag(models.TagInstallment):
+				p.logMessage(sms, &tx, "skipped", "installment plan")
+				unparsed = append(unparsed, models.UnparsedMessage{SMS: sms, Reason: "installment plan", Skipped: true})
+			default:
+				if tx.OriginalCurrency == "" {
+					tx.OriginalAmount = tx.Amount
+					tx.OriginalCurrency = tx.Currency
+				}
+				tx.ID = transactionID(sms, tx.Amount)
+				tx.Body = sms.Body
+				if p.noteMode != NoteFull {
+					// Keep only the markers parsers put in front of the body
+					tx.Note = strings.TrimSpace(strings.TrimSuffix(tx.Note, sms.Body))
+				}
+				p.logMessage(sms, &tx, "accepted", "")
+				transactions = append(transactions, tx)
 			}
+		}
+	}
+
+	return transactions, unparsed, nil
+}
 
-			groupedData[tx.TargetGroup] = append(groupedData[tx.TargetGroup], tx)
+// Finish runs the steps that compare transactions with each other over the
+// transactions collected from every backup: pending authorizations are
+// superseded, InstaPay duplicates dropped, and internal transfers paired.
+// It then drops excluded categories, formats the notes, and groups the
+// transactions into accounts sorted by name.
+func (p *Parser) Finish(transactions []models.Transaction) []models.Account {
+	if p.includePending {
+		transactions = supersedePending(transactions)
+	}
+
+	if !p.keepInstaPay {
+		transactions = dropInstaPayDuplicates(transactions)
+	}
+
+	if p.detectTransfers {
+		detectTransfers(transactions)
+	}
+
+	// Add category to note, dropping excluded categories
+	kept := transactions[:0]
+	for _, tx := range transactions {
+		if p.excluded[tx.Category] {
+			continue
 		}
+
+		if p.redact {
+			tx.Note = utils.RedactSensitive(tx.Note)
+			tx.Body = utils.RedactSensitive(tx.Body)
+		}
+
+		switch {
+		case p.noteMode == NoteNone:
+			tx.Note = ""
+		case tx.Category == models.CatGeneral:
+		case tx.Note == "":
+			tx.Note = fmt.Sprintf("[%s]", tx.Category)
+		default:
+			tx.Note = fmt.Sprintf("[%s] %s", tx.Category, tx.Note)
+		}
+
+		kept = append(kept, tx)
+	}
+
+	return models.GroupAccounts(kept)
+}
+
+// parserFor returns the parser for a message from sender, or false when the
+// message is ignored
+func (p *Parser) parserFor(sender, body string) (messageParser, bool) {
+	if parse, ok := p.parsers[sender]; ok {
+		return parse, true
+	}
+	// Indian sender IDs carry an operator prefix, e.g. "VM-HDFCBK"
+	if parse, ok := p.parsers[indianSenderID(sender)]; ok {
+		return parse, true
+	}
+
+	// Transaction-like messages from other business senders go to the
+	// generic extractor rather than being dropped
+	if p.skipUnknown || !isGenericSender(sender) || !looksLikeTransaction(body) {
+		return nil, false
+	}
+	return newGenericParser(sender), true
+}
+
+// Handles reports whether a message from sender is parsed, rather than
+// ignored as personal or unrelated
+func (p *Parser) Handles(sender, body string) bool {
+	_, ok := p.parserFor(sender, body)
+	return ok
+}
+
+// Counterparties returns the people or accounts a message names as the other
+// side of a transfer, as the parsers capture them, such as "Ahmed Ali" in
+// "You have received EGP 500.00 from Ahmed Ali"
+func (p *Parser) Counterparties(sender, body string) []string {
+	parse, ok := p.parserFor(sender, body)
+	if !ok {
+		return nil
+	}
+
+	tx := models.Transaction{Currency: "EGP", Type: models.TypeExpense, Category: models.CatGeneral, Note: body}
+	parsed, err := parse(tx, body)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, tx := range parsed {
+		if isTransfer(tx) && tx.Payee != "" && tx.Payee != "Transfer In" && tx.Payee != "Transfer Out" {
+			names = append(names, tx.Payee)
+		}
+	}
+	return names
+}
+
+// transactionID hashes the message date, sender, and body together with the
+// transaction amount, which tells apart the line items of one message
+func transactionID(sms models.SMS, amount float64) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%.2f|%s", sms.Date, sms.Address, amount, sms.Body)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// logMessage writes a grep-friendly key=value line describing the outcome of
+// parsing a message; tx is nil when no parser ran
+func (p *Parser) logMessage(sms models.SMS, tx *models.Transaction, result, reason string) {
+	line := fmt.Sprintf("sms date=%s sender=%q result=%s", sms.Date, sms.Address, result)
+	if reason != "" {
+		line += fmt.Sprintf(" reason=%q", reason)
+	}
+
+	if tx != nil {
+		function, pattern, _ := strings.Cut(tx.Source, ":")
+		line += fmt.Sprintf(" parser=%s pattern=%s group=%q amount=%.2f currency=%s payee=%q category=%q",
+			function, pattern, tx.TargetGroup, tx.Amount, tx.Currency, tx.Payee, tx.Category)
 	}
 
-	return groupedData, nil
+	p.logger.Println(line)
 }
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"math"
+	"time"
+
+	"sms-parser/internal/models"
+)
+
+// pendingWindow is the maximum time between a pending authorization and the
+// charge that settles it
+const pendingWindow = 72 * time.Hour
+
+// supersedePending drops each pending transaction that is followed, within
+// pendingWindow, by a settled transaction of the same amount and currency in
+// the same group. Each settled transaction supersedes at most one pending one.
+func supersedePending(transactions []models.Transaction) []models.Transaction {
+	superseded := make([]bool, len(transactions))
+	settledUsed := make([]bool, len(transactions))
+
+	for i := range transactions {
+		pending := &transactions[i]
+		if !pending.Pending {
+			continue
+		}
+
+		match := -1
+		var matchGap time.Duration
+		for j := range transactions {
+			settled := &transactions[j]
+			if settledUsed[j] || settled.Pending || settled.TargetGroup != pending.TargetGroup || settled.Currency != pending.Currency {
+				continue
+			}
+			if math.Abs(settled.Amount-pending.Amount) >= 0.005 {
+				continue
+			}
+
+			gap := settled.Timestamp.Sub(pending.Timestamp)
+			if gap < 0 || gap > pendingWindow {
+				continue
+			}
+
+			if match < 0 || gap < matchGap {
+				match = j
+				matchGap = gap
+			}
+		}
+
+		if match < 0 {
+			continue
+		}
+
+		settledUsed[match] = true
+		superseded[i] = true
+	}
+
+	kept := make([]models.Transaction, 0, len(transactions))
+	for i, tx := range transactions {
+		if !superseded[i] {
+			kept = append(kept, tx)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,32 @@
+package parser
+
+import (
+	"fmt"
+
+	"sms-parser/internal/models"
+	"sms-parser/pkg/bankparser"
+)
+
+// newPluginParser adapts an externally built bankparser.BankParser into a
+// message parser. Transactions without a Source are attributed to the plugin,
+// and a panicking plugin fails only the message it was given.
+func newPluginParser(bank bankparser.BankParser) messageParser {
+	return func(tx models.Transaction, body string) (parsed []models.Transaction, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				parsed, err = nil, fmt.Errorf("plugin panicked: %v", r)
+			}
+		}()
+
+		parsed, err = bank.Parse(tx, body)
+		if err != nil {
+			return nil, err
+		}
+		for i := range parsed {
+			if parsed[i].Source == "" {
+				parsed[i].Source = fmt.Sprintf("plugin:%T", bank)
+			}
+		}
+		return parsed, nil
+	}
+}
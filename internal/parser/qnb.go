@@ -0,0 +1,81 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// qnbPatterns are the QNB Alahli message shapes, most specific first. The
+// alerts are often bilingual, so the English part usually matches first.
+var qnbPatterns = []bankPattern{
+	{
+		// "ATM withdrawal of EGP 1,000.00 from card xx1234" / "سحب نقدي بمبلغ 1,000 جنيه"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)(?:(?:ATM|cash) withdrawal(?:\s+of)?|سحب(?:\s*نقدي)?(?:\s*(?:بمبلغ|مبلغ))?)\s*` + amountExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "POS purchase of EGP 350.00 at SPINNEYS using card xx1234" /
+		// "Your card xx1234 was used for EGP 350.00 at SPINNEYS on 01/01"
+		name:  "pos_en",
+		regex: regexp.MustCompile(`(?i)(?:purchase(?:\s+of)?|used for|charged(?:\s+with|\s+for)?)\s*` + amountExpr + `.*?\bat\s+(?P<payee>.*?)(?:\s+(?:using|with card|by card)\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "عملية شراء بمبلغ 350.00 جنيه لدى SPINNEYS ببطاقتكم xx1234"
+		name:  "pos_ar",
+		regex: regexp.MustCompile(`(?:شراء|خصم)\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr + `.*?(?:لدى|عند)\s+(?P<payee>.*?)(?:\s+ببطاق\S*\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "Payment of EGP 5,000.00 received for credit card xx1234" / "تم سداد مبلغ 5,000 جنيه"
+		name:   "payment",
+		regex:  regexp.MustCompile(`(?i)(?:payment(?:\s+of)?|تم سداد(?:\s*مبلغ)?)\s*` + amountExpr),
+		income: true,
+		payee:  "Card Payment",
+	},
+	{
+		// "Your account xx5678 was credited with EGP 5,000.00" / "تم ايداع مبلغ 5,000 جنيه"
+		name:   "credit",
+		regex:  regexp.MustCompile(`(?i)(?:credited(?:\s+with|\s+by)?|تم (?:ايداع|إيداع|اضافة|إضافة)(?:\s*مبلغ)?)\s*` + amountExpr),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your account xx5678 was debited with EGP 1,000.00"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)(?:debited(?:\s+with|\s+by)?|تم (?:خصم|تحويل)(?:\s*مبلغ)?)\s*` + amountExpr),
+		payee: "Transfer Out",
+	},
+}
+
+// parseQNBMessage parses QNB Alahli SMS messages
+func parseQNBMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	// Monthly statements repeat the card's transactions as a total
+	lower := strings.ToLower(body)
+	if utils.Contains(lower, "statement", "كشف حساب") && utils.Contains(lower, "due", "مستحق", "السداد") {
+		return nil, errStatement
+	}
+
+	extractBalance(&tx, body)
+	prefix := "QNB"
+	if utils.Contains(lower, "credit card", "الائتمان") {
+		prefix = "QNB_Credit"
+	}
+	setCardGroup(&tx, body, prefix)
+
+	if err := matchBankPatterns(&tx, body, "parseQNBMessage", qnbPatterns); err != nil {
+		return nil, err
+	}
+
+	return appendFee(tx, body)
+}
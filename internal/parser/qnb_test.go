@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseQNBMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "qnb.yaml"), func(goldenCase) messageParser {
+		return parseQNBMessage
+	})
+}
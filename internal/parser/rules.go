@@ -0,0 +1,58 @@
+package parser
+
+import (
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/rules"
+	"sms-parser/internal/utils"
+)
+
+// newRulesParser builds a message parser from a declarative bank definition.
+// Patterns are tried in order and the first one that matches wins.
+func newRulesParser(bank rules.Bank) messageParser {
+	return func(tx models.Transaction, body string) ([]models.Transaction, error) {
+		if bank.Skips(body) {
+			return nil, errSkipWord
+		}
+
+		// An unparsable amount is reported only if no later pattern matches
+		var amountErr error
+		for i := range bank.Patterns {
+			pattern := &bank.Patterns[i]
+			captures, ok := pattern.Match(body)
+			if !ok {
+				continue
+			}
+
+			amount, err := parseAmount(captures["amount"])
+			if err != nil {
+				if amountErr == nil {
+					amountErr = err
+				}
+				continue
+			}
+
+			tx.Source = "rules:" + pattern.Name
+			tx.TargetGroup = pattern.TargetGroup
+			tx.Type = bank.TypeOf(pattern, body, captures)
+			tx.Currency = utils.NormalizeCurrency(captures["currency"])
+			tx.Payee = pattern.Payee
+			if payee := utils.CleanPayeeName(strings.TrimSpace(captures["payee"])); payee != "" {
+				tx.Payee = payee
+			}
+
+			if tx.Type == models.TypeIncome {
+				tx.Amount = amount
+			} else {
+				tx.Amount = -amount
+			}
+			return []models.Transaction{tx}, nil
+		}
+
+		if amountErr != nil {
+			return nil, amountErr
+		}
+		return []models.Transaction{tx}, nil
+	}
+}
@@ -0,0 +1,177 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// saudiFieldPatterns are the message shapes shared by Al Rajhi and SNB, most
+// specific first. Alerts open with the kind of transaction on its own line,
+// followed by "Field: value" lines, in Arabic or English. Charges abroad may
+// add the amount billed in SAR (see billedExpr).
+var saudiFieldPatterns = []bankPattern{
+	{
+		// "استرداد\nبطاقة:4567;مدى\nمبلغ:SAR 45.50\nمن:NOON"
+		name:   "refund",
+		regex:  regexp.MustCompile(`(?is)^\s*(?:استرداد|استرجاع|refund)[^\n]*.*?(?:مبلغ|المبلغ|amount)\s*:\s*` + amountExpr + billedExpr + `(?:.*?\n\s*(?:لدى|من|at|from|merchant)\s*:\s*(?P<payee>[^\d\s*][^\n]*))?`),
+		income: true,
+		payee:  "Refund",
+	},
+	{
+		// "سحب:صراف آلي\nبطاقة:4567;مدى\nمبلغ:SAR 500\nمكان السحب:RIYADH"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?is)^\s*(?:سحب|(?:ATM|cash) withdrawal)[^\n]*.*?(?:مبلغ|المبلغ|amount)\s*:\s*` + amountExpr + billedExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "حوالة واردة: محلية\nمبلغ:SAR 5,000\nالى:5678\nمن:ACME CO" /
+		// "Incoming Transfer\nAmount: SAR 5,000.00\nTo: *5678\nFrom: ACME CO";
+		// senders given only as an account number keep the default payee
+		name:   "transfer_in",
+		regex:  regexp.MustCompile(`(?is)^\s*(?:حوالة واردة|تحويل وارد|إيداع|ايداع|incoming transfer|deposit)[^\n]*.*?(?:مبلغ|المبلغ|amount)\s*:\s*` + amountExpr + billedExpr + `(?:.*?\n\s*(?:من|from)\s*:\s*(?P<payee>[^\d\s*][^\n]*))?`),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "حوالة صادرة: محلية\nمن:5678\nمبلغ:SAR 1,000\nالى:MOHAMMED ALI" /
+		// "Outgoing Transfer\nAmount: SAR 1,000.00\nTo: MOHAMMED ALI"
+		name:  "transfer_out",
+		regex: regexp.MustCompile(`(?is)^\s*(?:حوالة صادرة|حوالة|تحويل صادر|تحويل|outgoing transfer|transfer)[^\n]*.*?(?:مبلغ|المبلغ|amount)\s*:\s*` + amountExpr + billedExpr + `(?:.*?\n\s*(?:الى|إلى|لـ|to)\s*:\s*(?P<payee>[^\d\s*][^\n]*))?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "سداد فاتورة\nمبلغ:SAR 230\nالمفوتر:STC"
+		name:  "bill_payment",
+		regex: regexp.MustCompile(`(?is)^\s*(?:سداد|bill payment|payment)[^\n]*.*?(?:مبلغ|المبلغ|amount)\s*:\s*` + amountExpr + billedExpr + `(?:.*?\n\s*(?:المفوتر|الجهة|جهة الفوترة|biller)\s*:\s*(?P<payee>[^\n]+))?`),
+		payee: "Bill Payment",
+	},
+	{
+		// "شراء عبر نقاط البيع\nبطاقة:4567;مدى-أبل باي\nمبلغ:SAR 45.50\nلدى:STARBUCKS\nفي:26-01-15 10:30" /
+		// "POS Purchase\nCard: mada *1234\nAmount: SAR 85.00\nAt: PANDA"
+		name:  "purchase",
+		regex: regexp.MustCompile(`(?is)^\s*(?:شراء|purchase|pos purchase|online purchase)[^\n]*.*?(?:مبلغ|المبلغ|amount)\s*:\s*` + amountExpr + billedExpr + `(?:.*?\n\s*(?:لدى|من|عند|at|from|merchant)\s*:\s*(?P<payee>[^\d\s*][^\n]*))?`),
+		payee: "Card Purchase",
+	},
+}
+
+// sabPatterns are the SAB (Saudi Awwal Bank) message shapes, most specific
+// first. Alerts are single sentences naming the card or account.
+var sabPatterns = []bankPattern{
+	{
+		// "ATM withdrawal of SAR 500.00 using card ending 1234 at RIYADH on 15/01/2026"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)(?:(?:ATM|cash) withdrawal(?:\s+of)?|سحب(?:\s*نقدي)?(?:\s*(?:بمبلغ|مبلغ))?)\s*` + amountExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "Purchase transaction using card ending 1234 for SAR 120.00 at JARIR BOOKSTORE on 15/01/2026" /
+		// "Purchase of USD 20.00 (SAR 75.20) at NETFLIX.COM with card ending 1234"
+		name:  "purchase",
+		regex: regexp.MustCompile(`(?i)(?:purchase(?:\s+transaction)?.*?\b(?:for|of)|purchase(?:\s+of)?)\s*` + amountExpr + billedExpr + `.*?\bat\s+(?P<payee>.*?)(?:\s+(?:with|using)\s+card\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "عملية شراء بمبلغ 120.00 ريال لدى JARIR BOOKSTORE بالبطاقة المنتهية بـ 1234"
+		name:  "purchase_ar",
+		regex: regexp.MustCompile(`شراء\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr + billedExpr + `.*?(?:لدى|عند|من)\s+(?P<payee>.*?)(?:\s+بالبطاق\S*\s|\s+ببطاق\S*\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "A refund of SAR 120.00 from JARIR BOOKSTORE was credited to your card ending 1234"
+		name:   "refund",
+		regex:  regexp.MustCompile(`(?i)(?:refund(?:\s+of)?|استرداد(?:\s*مبلغ)?)\s*` + amountExpr + `(?:\s+(?:from|من)\s+(?P<payee>.*?)(?:\s+(?:was|has)\s|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Refund",
+	},
+	{
+		// "Your account ending 5678 has been credited with SAR 8,000.00" /
+		// "تم إيداع مبلغ 8,000.00 ريال في حسابك المنتهي بـ 5678"
+		name:   "credit",
+		regex:  regexp.MustCompile(`(?i)(?:credited(?:\s+with|\s+by)?|تم (?:ايداع|إيداع|اضافة|إضافة)(?:\s*مبلغ)?)\s*` + amountExpr),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your account ending 5678 has been debited with SAR 1,000.00"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)(?:debited(?:\s+with|\s+by)?|تم (?:خصم|تحويل)(?:\s*مبلغ)?)\s*` + amountExpr),
+		payee: "Transfer Out",
+	},
+}
+
+// saudiCardPattern finds the card line of a field-style alert, such as
+// "بطاقة:4567;مدى" or "Card: mada *1234"
+var saudiCardPattern = regexp.MustCompile(`(?im)^\s*(?:بطاقة|البطاقة|card)\s*:\D*?(\d{4})\b`)
+
+// saudiBalancePattern finds the balance line of a field-style alert, such
+// as "الرصيد:SAR 1,234.50" or "Balance: SAR 1,234.50"
+var saudiBalancePattern = regexp.MustCompile(`(?im)^\s*(?:الرصيد|رصيد|balance)\s*:\s*(?:(?-i:[A-Z]{3}|SR)|ر\.س|ريال)?\s*(-?[\d,]+(?:\.\d{1,2})?)`)
+
+// isSaudiOTPMessage reports whether body is an OTP or login message. Saudi
+// banks often name the code only as رمز التحقق (verification code) or
+// كلمة المرور (password).
+func isSaudiOTPMessage(body string) bool {
+	return isOTPMessage(body) || utils.Contains(body, "رمز التحقق", "كلمة المرور")
+}
+
+// parseAlRajhiMessage parses Al Rajhi Bank SMS messages
+func parseAlRajhiMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	return parseSaudiFieldMessage(tx, body, "AlRajhi", "parseAlRajhiMessage")
+}
+
+// parseSNBMessage parses Saudi National Bank (AlAhli) SMS messages
+func parseSNBMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	return parseSaudiFieldMessage(tx, body, "SNB", "parseSNBMessage")
+}
+
+// parseSaudiFieldMessage handles the field-style alerts of Al Rajhi and SNB.
+// All of them go to the bank's group; the card used is kept in
+// Transaction.CardLast4. Amounts without a currency are in SAR.
+func parseSaudiFieldMessage(tx models.Transaction, body, group, function string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isSaudiOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	if match := saudiBalancePattern.FindStringSubmatch(body); match != nil && !tx.HasBalance {
+		if balance, err := utils.ParseAmount(match[1]); err == nil {
+			tx.Balance = balance
+			tx.HasBalance = true
+		}
+	}
+	tx.TargetGroup = group
+	if match := saudiCardPattern.FindStringSubmatch(body); match != nil {
+		tx.CardLast4 = match[1]
+	}
+
+	if err := matchBankPatterns(&tx, body, function, saudiFieldPatterns); err != nil {
+		return nil, err
+	}
+	setLocalCurrency(&tx, body, "SAR")
+
+	return appendFee(tx, body)
+}
+
+// parseSABMessage parses SAB (Saudi Awwal Bank) SMS messages. Amounts
+// without a currency are in SAR.
+func parseSABMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isSaudiOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	setCardGroup(&tx, body, "SAB")
+
+	if err := matchBankPatterns(&tx, body, "parseSABMessage", sabPatterns); err != nil {
+		return nil, err
+	}
+	setLocalCurrency(&tx, body, "SAR")
+
+	return appendFee(tx, body)
+}
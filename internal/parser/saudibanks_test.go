@@ -0,0 +1,21 @@
+package parser
+
+import "testing"
+
+func TestParseAlRajhiMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "alrajhi.yaml"), func(goldenCase) messageParser {
+		return parseAlRajhiMessage
+	})
+}
+
+func TestParseSNBMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "snb.yaml"), func(goldenCase) messageParser {
+		return parseSNBMessage
+	})
+}
+
+func TestParseSABMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "sab.yaml"), func(goldenCase) messageParser {
+		return parseSABMessage
+	})
+}
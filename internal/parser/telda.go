@@ -0,0 +1,70 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// teldaPatterns are the Telda prepaid card message shapes, most specific first
+var teldaPatterns = []bankPattern{
+	{
+		// "You spent EGP 250.00 at Carrefour. Your Telda balance is EGP 1,200.00" /
+		// "Purchase of EGP 250.00 at Carrefour with your Telda card"
+		name:  "purchase",
+		regex: regexp.MustCompile(`(?i)(?:spent|purchase of|paid)\s*` + amountExpr + billedExpr + `\s*(?:at|to)\s+(?P<payee>.*?)(?:\s+(?:with|using)\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "You withdrew EGP 1,000.00 from an ATM"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)(?:withdrew|withdrawal of)\s*` + amountExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "Refund of EGP 100.00 from Amazon has been added to your Telda balance"
+		name:   "refund",
+		regex:  regexp.MustCompile(`(?i)refund(?:\s+of)?\s*` + amountExpr + `(?:\s+from\s+(?P<payee>.*?)(?:\s+(?:has been|was)\s|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Refund",
+	},
+	{
+		// "You received EGP 500.00 from Ahmed Ali"
+		name:   "received",
+		regex:  regexp.MustCompile(`(?i)received\s*` + amountExpr + `(?:\s+from\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "You sent EGP 200.00 to Mona"
+		name:  "sent",
+		regex: regexp.MustCompile(`(?i)(?:sent|transferred)\s*` + amountExpr + `(?:\s+to\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "Your Telda account was topped up with EGP 1,000.00"
+		name:   "top_up",
+		regex:  regexp.MustCompile(`(?i)(?:topped up(?:\s+with|\s+by)?|top[- ]?up of|added)\s*` + amountExpr),
+		income: true,
+		payee:  "Telda Top Up",
+	},
+}
+
+// parseTeldaMessage parses Telda prepaid card alerts
+func parseTeldaMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	setCardGroup(&tx, body, "Telda")
+
+	if err := matchBankPatterns(&tx, body, "parseTeldaMessage", teldaPatterns); err != nil {
+		return nil, err
+	}
+
+	return []models.Transaction{tx}, nil
+}
@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseTeldaMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "telda.yaml"), func(goldenCase) messageParser {
+		return parseTeldaMessage
+	})
+}
@@ -0,0 +1,61 @@
+package parser
+
+import (
+	"math"
+	"time"
+
+	"sms-parser/internal/models"
+)
+
+// transferWindow is the maximum time between the two legs of an internal transfer
+const transferWindow = 10 * time.Minute
+
+// detectTransfers pairs each outgoing transaction with the closest incoming
+// transaction of the same absolute amount and currency in another group,
+// and marks both legs as an internal transfer. Each leg is paired at most once.
+func detectTransfers(transactions []models.Transaction) {
+	paired := make([]bool, len(transactions))
+
+	for i := range transactions {
+		out := &transactions[i]
+		if paired[i] || out.Amount >= 0 {
+			continue
+		}
+
+		match := -1
+		var matchGap time.Duration
+		for j := range transactions {
+			in := &transactions[j]
+			if paired[j] || in.Amount <= 0 || in.TargetGroup == out.TargetGroup || in.Currency != out.Currency {
+				continue
+			}
+			if math.Abs(in.Amount+out.Amount) >= 0.005 {
+				continue
+			}
+
+			gap := in.Timestamp.Sub(out.Timestamp)
+			if gap < 0 {
+				gap = -gap
+			}
+			if gap > transferWindow {
+				continue
+			}
+
+			if match < 0 || gap < matchGap {
+				match = j
+				matchGap = gap
+			}
+		}
+
+		if match < 0 {
+			continue
+		}
+
+		paired[i] = true
+		paired[match] = true
+		for _, leg := range []*models.Transaction{out, &transactions[match]} {
+			leg.Type = models.TypeTransfer
+			leg.Category = models.CatFinancial
+		}
+	}
+}
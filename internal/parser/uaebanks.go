@@ -0,0 +1,139 @@
+package parser
+
+import (
+	"regexp"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// fabPatterns are the First Abu Dhabi Bank message shapes, most specific
+// first. Charges abroad may add the amount billed in AED (see billedExpr).
+var fabPatterns = []bankPattern{
+	{
+		// "Cash withdrawal of AED 500.00 from your FAB Debit Card ending 1234 at ADCB ATM MUSHRIF"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)(?:(?:ATM|cash) withdrawal(?:\s+of)?|سحب(?:\s*نقدي)?(?:\s*(?:بمبلغ|مبلغ))?)\s*` + amountExpr + billedExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "Your FAB Debit Card ending 1234 was used for AED 55.00 at ADNOC 123 on 15/01/2026 12:30" /
+		// "Purchase of USD 20.00 (AED 73.60) on Credit Card XXXX1234 at NETFLIX.COM"
+		name:  "purchase",
+		regex: regexp.MustCompile(`(?i)(?:used for|purchase(?:\s+of)?|transaction(?:\s+of)?)\s*` + amountExpr + billedExpr + `.*?\bat\s+(?P<payee>.*?)(?:\s+(?:with|using)\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "تم استخدام بطاقتك المنتهية بـ 1234 لعملية شراء بمبلغ 55.00 درهم لدى ADNOC"
+		name:  "purchase_ar",
+		regex: regexp.MustCompile(`شراء\s*(?:بمبلغ|مبلغ)?\s*` + amountExpr + billedExpr + `.*?(?:لدى|عند|من)\s+(?P<payee>.*?)(?:\s+بالبطاق\S*\s|\s+ببطاق\S*\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "A refund of AED 55.00 from NOON.COM has been credited to your Credit Card XXXX1234"
+		name:   "refund",
+		regex:  regexp.MustCompile(`(?i)refund(?:\s+of)?\s*` + amountExpr + billedExpr + `(?:\s+from\s+(?P<payee>.*?)(?:\s+(?:has been|was)\s|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Refund",
+	},
+	{
+		// "AED 12,000.00 has been credited to your account XXXX5678"
+		name:   "credit_amount_first",
+		regex:  regexp.MustCompile(`(?i)\b` + amountExpr + `\s+(?:has been|was)\s+credited`),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "Your account XXXX5678 has been credited with AED 12,000.00"
+		name:   "credit",
+		regex:  regexp.MustCompile(`(?i)(?:credited(?:\s+with|\s+by)?|تم (?:ايداع|إيداع|اضافة|إضافة)(?:\s*مبلغ)?)\s*` + amountExpr),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "AED 1,000.00 has been debited from your account XXXX5678 towards transfer to AHMED ALI on 15/01/2026"
+		name:  "debit_amount_first",
+		regex: regexp.MustCompile(`(?i)\b` + amountExpr + `\s+(?:has been|was)\s+debited(?:.*?\btransfer\s+to\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		payee: "Transfer Out",
+	},
+	{
+		// "Your account XXXX5678 has been debited with AED 1,000.00"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)(?:debited(?:\s+with|\s+by)?|تم خصم(?:\s*مبلغ)?)\s*` + amountExpr),
+		payee: "Transfer Out",
+	},
+}
+
+// rakbankPatterns are the RAKBANK message shapes, most specific first
+var rakbankPatterns = []bankPattern{
+	{
+		// "Cash withdrawal of AED 500.00 from your RAKBANK Debit Card ending 1234"
+		name:  "atm",
+		regex: regexp.MustCompile(`(?i)(?:ATM|cash) withdrawal(?:\s+of)?\s*` + amountExpr),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "AED 500.00 withdrawn from your RAKBANK Debit Card ending 1234 at ATM RAK MALL"
+		name:  "atm_amount_first",
+		regex: regexp.MustCompile(`(?i)\b` + amountExpr + `\s+(?:has been\s+|was\s+)?withdrawn`),
+		payee: "ATM Withdrawal",
+	},
+	{
+		// "Your RAKBANK Credit Card ending 1234 has been used for AED 75.00 at UBER on 15-01-2026"
+		name:  "purchase",
+		regex: regexp.MustCompile(`(?i)(?:used for|purchase(?:\s+of)?|transaction(?:\s+of)?)\s*` + amountExpr + billedExpr + `.*?\bat\s+(?P<payee>.*?)(?:\s+(?:with|using)\s|` + payeeEnd + `)`),
+		payee: "Card Purchase",
+	},
+	{
+		// "A refund of AED 75.00 from UBER has been credited to your Credit Card ending 1234"
+		name:   "refund",
+		regex:  regexp.MustCompile(`(?i)refund(?:\s+of)?\s*` + amountExpr + billedExpr + `(?:\s+from\s+(?P<payee>.*?)(?:\s+(?:has been|was)\s|` + payeeEnd + `))?`),
+		income: true,
+		payee:  "Refund",
+	},
+	{
+		// "AED 10,000.00 credited to A/C XXX5678 on 25-01-2026. Avl Bal AED 14,500.00"
+		name:   "credit",
+		regex:  regexp.MustCompile(`(?i)\b` + amountExpr + `\s+(?:has been\s+|was\s+)?credited`),
+		income: true,
+		payee:  "Transfer In",
+	},
+	{
+		// "AED 1,000.00 debited from A/C XXX5678 for transfer to AHMED ALI on 15-01-2026"
+		name:  "debit",
+		regex: regexp.MustCompile(`(?i)\b` + amountExpr + `\s+(?:has been\s+|was\s+)?debited(?:.*?\btransfer\s+to\s+(?P<payee>.*?)` + payeeEnd + `)?`),
+		payee: "Transfer Out",
+	},
+}
+
+// parseFABMessage parses First Abu Dhabi Bank SMS messages. Amounts without
+// a currency are in AED.
+func parseFABMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	return parseUAEBankMessage(tx, body, "FAB", "parseFABMessage", fabPatterns)
+}
+
+// parseRAKBANKMessage parses RAKBANK SMS messages. Amounts without a
+// currency are in AED.
+func parseRAKBANKMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	return parseUAEBankMessage(tx, body, "RAKBANK", "parseRAKBANKMessage", rakbankPatterns)
+}
+
+// parseUAEBankMessage matches body against a UAE bank's patterns, grouping
+// it by card or account and defaulting to AED
+func parseUAEBankMessage(tx models.Transaction, body, prefix, function string, patterns []bankPattern) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	extractBalance(&tx, body)
+	setCardGroup(&tx, body, prefix)
+
+	if err := matchBankPatterns(&tx, body, function, patterns); err != nil {
+		return nil, err
+	}
+	setLocalCurrency(&tx, body, "AED")
+
+	return appendFee(tx, body)
+}
@@ -0,0 +1,15 @@
+package parser
+
+import "testing"
+
+func TestParseFABMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "fab.yaml"), func(goldenCase) messageParser {
+		return parseFABMessage
+	})
+}
+
+func TestParseRAKBANKMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "rakbank.yaml"), func(goldenCase) messageParser {
+		return parseRAKBANKMessage
+	})
+}
@@ -0,0 +1,71 @@
+package parser
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// valuPatterns are the ValU message shapes, most specific first
+var valuPatterns = []bankPattern{
+	{
+		// "You have purchased from B.TECH with amount EGP 12,000.00 over 12 months" /
+		// "تم شراء من بي تك بمبلغ 12,000 جنيه على 12 شهر"
+		name:  "purchase",
+		regex: regexp.MustCompile(`(?i)(?:purchased from|تم (?:الشراء|شراء) من)\s+(?P<payee>.*?)\s+(?:with amount(?:\s+of)?|for|بمبلغ|مبلغ)\s*` + amountExpr),
+		payee: "ValU Purchase",
+	},
+	{
+		// "Your installment of EGP 1,100.00 (3/12) for B.TECH has been paid successfully" /
+		// "تم سداد القسط رقم 3/12 بمبلغ 1,100 جنيه لمشتريات بي تك"
+		name:  "installment",
+		regex: regexp.MustCompile(`(?i)(?:installment(?:\s+of)?|(?:تم سداد|تم دفع)\s*القسط\S*(?:\s*رقم\s*\d+\s*/\s*\d+)?\s*(?:بمبلغ|مبلغ)?)\s*` + amountExpr + `(?:\s*\(\d+\s*/\s*\d+\))?(?:\s*(?:for|لمشتريات|ل)\s*(?P<payee>.*?)(?:\s+(?:has been|was)\s|` + payeeEnd + `))?`),
+		payee: "ValU Installment",
+	},
+}
+
+// valuInstallmentNumber finds "3/12" (installment 3 of 12)
+var valuInstallmentNumber = regexp.MustCompile(`(\d+)\s*/\s*(\d+)`)
+
+// parseValUMessage parses ValU purchase and installment messages. Purchases
+// are tagged TagInstallment, like CIB installment conversions, since their
+// amount is paid off by the monthly installments; the monthly payments are
+// tagged TagRecurring.
+func parseValUMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	body = utils.NormalizeDigits(body)
+
+	if isOTPMessage(body) {
+		return nil, errSkipped
+	}
+
+	tx.TargetGroup = "ValU"
+	if err := matchBankPatterns(&tx, body, "parseValUMessage", valuPatterns); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case strings.HasSuffix(tx.Source, ":installment"):
+		if isReminder(body) {
+			return nil, errReminder
+		}
+		tx.Tags = append(tx.Tags, models.TagRecurring)
+		if match := valuInstallmentNumber.FindStringSubmatch(body); match != nil {
+			tx.Note = fmt.Sprintf("[Installment %s/%s] %s", match[1], match[2], tx.Note)
+		} else {
+			tx.Note = "[Installment] " + tx.Note
+		}
+	case strings.HasSuffix(tx.Source, ":purchase"):
+		tx.Tags = append(tx.Tags, models.TagInstallment)
+		monthsPattern := regexp.MustCompile(`(?i)(\d+)\s*(?:شهور|شهرا|شهر|أشهر|اشهر|months?)`)
+		if match := monthsPattern.FindStringSubmatch(body); match != nil {
+			tx.Note = fmt.Sprintf("[Installment: %s months] %s", match[1], tx.Note)
+		} else {
+			tx.Note = "[Installment] " + tx.Note
+		}
+	}
+
+	return []models.Transaction{tx}, nil
+}
@@ -0,0 +1,9 @@
+package parser
+
+import "testing"
+
+func TestParseValUMessage(t *testing.T) {
+	runGolden(t, loadGolden(t, "valu.yaml"), func(goldenCase) messageParser {
+		return parseValUMessage
+	})
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"regexp"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// parseVodafoneCashMessage parses Vodafone Cash wallet notifications
+func parseVodafoneCashMessage(tx models.Transaction, body string) ([]models.Transaction, error) {
+	if err := parseWalletMessage(&tx, body, "Vodafone_Cash"); err != nil {
+		return nil, err
+	}
+	return []models.Transaction{tx}, nil
+}
+
+var (
+	// walletSentPattern matches outgoing transfers: "You have sent EGP 500.00 to Ahmed (ahmed@instapay)" / "تم تحويل مبلغ 500 جنيه إلى أحمد"
+	walletSentPattern = regexp.MustCompile(`(?i)(?:sent|transferred|تم تحويل(?:\s*مبلغ)?|تم ارسال(?:\s*مبلغ)?|تم إرسال(?:\s*مبلغ)?|تم دفع(?:\s*مبلغ)?)\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*([\d,]+(?:\.\d{1,2})?)\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*(?:to|إلى|الى|ل)\s*(?:رقم\s*)?(.*?)(?:\s+(?:on|at|في|بتاريخ|Ref|ref|رقم العملية|رصيد\S*)\s|[.،]\s|[.،]?$)`)
+	// walletReceivedPattern matches incoming transfers: "You have received EGP 500.00 from Ahmed" / "تم استلام مبلغ 500 جنيه من أحمد"
+	walletReceivedPattern = regexp.MustCompile(`(?i)(?:received|تم استلام(?:\s*مبلغ)?|تم استقبال(?:\s*مبلغ)?|تم اضافة(?:\s*مبلغ)?|تم إضافة(?:\s*مبلغ)?)\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*([\d,]+(?:\.\d{1,2})?)\s*([A-Za-z]{3}|L\.E\.?|ج\.م|جنيه|جم)?\s*(?:from|من)\s*(?:رقم\s*)?(.*?)(?:\s+(?:on|at|في|بتاريخ|Ref|ref|رقم العملية|رصيد\S*)\s|[.،]\s|[.،]?$)`)
+)
+
+// parseWalletMessage handles the sent/received messages shared by InstaPay
+// and mobile wallets, in both English and Arabic
+func parseWalletMessage(tx *models.Transaction, body, targetGroup string) error {
+	// Amounts may be written in Eastern Arabic digits
+	body = utils.NormalizeDigits(body)
+
+	// Skip OTP and login messages
+	if isOTPMessage(body) {
+		return errSkipped
+	}
+
+	tx.TargetGroup = targetGroup
+	extractBalance(tx, body)
+
+	if match := walletSentPattern.FindStringSubmatch(body); len(match) > 4 {
+		tx.Source = "parseWalletMessage:sent"
+		amount, err := parseAmount(match[2])
+		if err != nil {
+			return err
+		}
+		tx.Amount = -amount
+		tx.Currency = utils.NormalizeCurrency(firstNonEmpty(match[1], match[3]))
+		tx.Payee = walletPayee(match[4], "Transfer Out")
+	} else if match := walletReceivedPattern.FindStringSubmatch(body); len(match) > 4 {
+		tx.Source = "parseWalletMessage:received"
+		tx.Type = models.TypeIncome
+		amount, err := parseAmount(match[2])
+		if err != nil {
+			return err
+		}
+		tx.Amount = amount
+		tx.Currency = utils.NormalizeCurrency(firstNonEmpty(match[1], match[3]))
+		tx.Payee = walletPayee(match[4], "Transfer In")
+	}
+
+	extractReference(tx, body)
+	return nil
+}
+
+// walletRefPattern finds a transaction reference number
+var walletRefPattern = regexp.MustCompile(`(?i)(?:\bref(?:erence)?\b(?:\s*no\.?)?|رقم العملية|رقم المعاملة|الرقم المرجعي|رقم المرجع)\s*:?\s*([A-Za-z0-9-]+)`)
+
+// extractReference sets tx.Reference from the message's reference number, if any
+func extractReference(tx *models.Transaction, body string) {
+	if match := walletRefPattern.FindStringSubmatch(body); len(match) > 1 {
+		tx.Reference = match[1]
+	}
+}
+
+// viaInstaPayPattern finds the "via InstaPay" that follows the counterparty in
+// transfers made through InstaPay
+var viaInstaPayPattern = regexp.MustCompile(`(?i)\s*(?:via|by|through|using|عبر|من خلال|بواسطة)\s*(?:instapay|insta pay|انستاباي|إنستاباي|انستا باي|إنستا باي)`)
+
+// walletPayee cleans a captured counterparty, dropping any "via InstaPay", and
+// falls back to a generic label
+func walletPayee(raw, fallback string) string {
+	if payee := strings.TrimSpace(viaInstaPayPattern.ReplaceAllString(raw, "")); payee != "" {
+		return payee
+	}
+	return fallback
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,230 @@
+package report
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// largestCount is the number of largest expenses listed per month
+const largestCount = 10
+
+// Month holds the summary of one calendar month: totals per currency with
+// their category breakdown, totals per account, and the largest expenses
+type Month struct {
+	// Month is formatted as YYYY-MM
+	Month    string
+	Totals   []CurrencySummary
+	Accounts []AccountSummary
+	Largest  []models.Transaction
+}
+
+// Months splits the transactions by calendar month, most recent first, and
+// summarizes each month
+func Months(accounts []models.Account) []Month {
+	byMonth := make(map[string]map[string][]models.Transaction)
+	for _, account := range accounts {
+		for _, tx := range account.Transactions {
+			month := tx.Timestamp.Format("2006-01")
+			if byMonth[month] == nil {
+				byMonth[month] = make(map[string][]models.Transaction)
+			}
+			byMonth[month][account.Name] = append(byMonth[month][account.Name], tx)
+		}
+	}
+
+	months := make([]Month, 0, len(byMonth))
+	for month, groups := range byMonth {
+		var monthAccounts []models.Account
+		var all []models.Transaction
+		for name, transactions := range groups {
+			monthAccounts = append(monthAccounts, models.Account{Name: name, Transactions: transactions})
+			all = append(all, transactions...)
+		}
+
+		// Summarizing every transaction as one account gives the month's totals
+		totals := Summarize([]models.Account{{Name: month, Transactions: all}}, nil)
+
+		var expenses []models.Transaction
+		for _, tx := range all {
+			if tx.Amount < 0 {
+				expenses = append(expenses, tx)
+			}
+		}
+		sort.SliceStable(expenses, func(i, j int) bool {
+			if expenses[i].Amount != expenses[j].Amount {
+				return expenses[i].Amount < expenses[j].Amount
+			}
+			return expenses[i].Timestamp.Before(expenses[j].Timestamp)
+		})
+		if len(expenses) > largestCount {
+			expenses = expenses[:largestCount]
+		}
+
+		months = append(months, Month{
+			Month:    month,
+			Totals:   totals.Accounts[0].Currencies,
+			Accounts: Summarize(monthAccounts, nil).Accounts,
+			Largest:  expenses,
+		})
+	}
+
+	sort.Slice(months, func(i, j int) bool {
+		return months[i].Month > months[j].Month
+	})
+	return months
+}
+
+// DocumentFormat returns the report format for a file name: "html" for
+// .html/.htm and "markdown" for .md/.markdown
+func DocumentFormat(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return "html", nil
+	case ".md", ".markdown":
+		return "markdown", nil
+	default:
+		return "", fmt.Errorf("unsupported report file %q: use a .html or .md extension", path)
+	}
+}
+
+// WriteDocument writes the monthly report to path, as HTML or Markdown
+// depending on its extension
+func WriteDocument(path string, accounts []models.Account) error {
+	docFormat, err := DocumentFormat(path)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", path, err)
+	}
+	defer file.Close()
+
+	months := Months(accounts)
+	if docFormat == "html" {
+		err = WriteHTML(file, months)
+	} else {
+		err = WriteMarkdown(file, months)
+	}
+	if err != nil {
+		return fmt.Errorf("error writing %s: %w", path, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing %s: %w", path, err)
+	}
+	return nil
+}
+
+// templateFuncs are shared by the HTML and Markdown templates
+var templateFuncs = map[string]any{
+	"amount": utils.FormatAmount,
+	"abs": func(amount float64) float64 {
+		if amount < 0 {
+			return -amount
+		}
+		return amount
+	},
+	// cell escapes a value for a Markdown table cell
+	"cell": func(value string) string {
+		value = strings.Join(strings.Fields(value), " ")
+		return strings.ReplaceAll(value, "|", `\|`)
+	},
+}
+
+// markdownTemplate renders the months as Markdown tables
+var markdownTemplate = texttemplate.Must(texttemplate.New("markdown").Funcs(templateFuncs).Parse(`# Transaction Report
+{{range .}}
+## {{.Month}}
+
+### Totals
+
+| Currency | Income | Expense | Net |
+| --- | ---: | ---: | ---: |
+{{range .Totals}}| {{.Currency}} | {{amount .Income .Currency}} | {{amount .Expense .Currency}} | {{amount .Net .Currency}} |
+{{end}}
+### Expenses by Category
+
+| Category | Currency | Amount |
+| --- | --- | ---: |
+{{range $total := .Totals}}{{range .Categories}}| {{cell .Category}} | {{$total.Currency}} | {{amount .Amount $total.Currency}} |
+{{end}}{{end}}
+### Accounts
+
+| Account | Currency | Income | Expense | Net |
+| --- | --- | ---: | ---: | ---: |
+{{range $account := .Accounts}}{{range .Currencies}}| {{cell $account.Name}} | {{.Currency}} | {{amount .Income .Currency}} | {{amount .Expense .Currency}} | {{amount .Net .Currency}} |
+{{end}}{{end}}
+### Largest Expenses
+
+| Date | Account | Payee | Category | Amount |
+| --- | --- | --- | --- | ---: |
+{{range .Largest}}| {{.Timestamp.Format "2006-01-02"}} | {{cell .TargetGroup}} | {{cell .Payee}} | {{cell .Category}} | {{amount (abs .Amount) .Currency}} {{.Currency}} |
+{{end}}{{end}}`))
+
+// htmlTemplate renders the months as a standalone HTML page
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(templateFuncs).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Transaction Report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; }
+td.num { text-align: right; font-variant-numeric: tabular-nums; }
+th { background: #f3f3f3; }
+</style>
+</head>
+<body>
+<h1>Transaction Report</h1>
+{{range .}}
+<h2>{{.Month}}</h2>
+
+<h3>Totals</h3>
+<table>
+<tr><th>Currency</th><th>Income</th><th>Expense</th><th>Net</th></tr>
+{{range .Totals}}<tr><td>{{.Currency}}</td><td class="num">{{amount .Income .Currency}}</td><td class="num">{{amount .Expense .Currency}}</td><td class="num">{{amount .Net .Currency}}</td></tr>
+{{end}}</table>
+
+<h3>Expenses by Category</h3>
+<table>
+<tr><th>Category</th><th>Currency</th><th>Amount</th></tr>
+{{range $total := .Totals}}{{range .Categories}}<tr><td>{{.Category}}</td><td>{{$total.Currency}}</td><td class="num">{{amount .Amount $total.Currency}}</td></tr>
+{{end}}{{end}}</table>
+
+<h3>Accounts</h3>
+<table>
+<tr><th>Account</th><th>Currency</th><th>Income</th><th>Expense</th><th>Net</th></tr>
+{{range $account := .Accounts}}{{range .Currencies}}<tr><td>{{$account.Name}}</td><td>{{.Currency}}</td><td class="num">{{amount .Income .Currency}}</td><td class="num">{{amount .Expense .Currency}}</td><td class="num">{{amount .Net .Currency}}</td></tr>
+{{end}}{{end}}</table>
+
+<h3>Largest Expenses</h3>
+<table>
+<tr><th>Date</th><th>Account</th><th>Payee</th><th>Category</th><th>Amount</th></tr>
+{{range .Largest}}<tr><td>{{.Timestamp.Format "2006-01-02"}}</td><td>{{.TargetGroup}}</td><td>{{.Payee}}</td><td>{{.Category}}</td><td class="num">{{amount (abs .Amount) .Currency}} {{.Currency}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
+
+// WriteMarkdown renders the months as a Markdown document
+func WriteMarkdown(w io.Writer, months []Month) error {
+	return markdownTemplate.Execute(w, months)
+}
+
+// WriteHTML renders the months as a standalone HTML page
+func WriteHTML(w io.Writer, months []Month) error {
+	return htmlTemplate.Execute(w, months)
+}
@@ -0,0 +1,134 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"sms-parser/internal/models"
+)
+
+// Report holds per-account totals, sub-totalled per currency, and the
+// number of messages that produced no transaction
+type Report struct {
+	Accounts []AccountSummary
+	// Unparsed counts the messages from known senders that produced no
+	// transaction; Skipped of them were left out on purpose (OTPs, notices)
+	Unparsed int
+	Skipped  int
+}
+
+// AccountSummary holds the totals of a single account group
+type AccountSummary struct {
+	Name       string
+	Currencies []CurrencySummary
+}
+
+// CurrencySummary holds the totals of an account in a single currency.
+// Expense is a positive magnitude, so Net = Income - Expense.
+type CurrencySummary struct {
+	Currency   string
+	Count      int
+	Income     float64
+	Expense    float64
+	Net        float64
+	Categories []CategoryTotal
+}
+
+// CategoryTotal is the total expense amount of a single category
+type CategoryTotal struct {
+	Category string
+	Amount   float64
+}
+
+// Summarize computes income, expense, and net totals and transaction counts
+// per account and currency, with a per-category breakdown of expenses, and
+// counts the unparsed messages
+func Summarize(accounts []models.Account, unparsed []models.UnparsedMessage) Report {
+	report := Report{Unparsed: len(unparsed)}
+	for _, msg := range unparsed {
+		if msg.Skipped {
+			report.Skipped++
+		}
+	}
+
+	for _, group := range accounts {
+		transactions := group.Transactions
+		if len(transactions) == 0 {
+			continue
+		}
+
+		byCurrency := make(map[string]*CurrencySummary)
+		byCategory := make(map[string]map[string]float64)
+		for _, tx := range transactions {
+			summary, ok := byCurrency[tx.Currency]
+			if !ok {
+				summary = &CurrencySummary{Currency: tx.Currency}
+				byCurrency[tx.Currency] = summary
+				byCategory[tx.Currency] = make(map[string]float64)
+			}
+			summary.Count++
+
+			if tx.Amount > 0 {
+				summary.Income += tx.Amount
+			} else {
+				summary.Expense += -tx.Amount
+				byCategory[tx.Currency][tx.Category] += -tx.Amount
+			}
+		}
+
+		account := AccountSummary{Name: group.Name}
+		for currency, summary := range byCurrency {
+			summary.Net = summary.Income - summary.Expense
+			for category, amount := range byCategory[currency] {
+				summary.Categories = append(summary.Categories, CategoryTotal{Category: category, Amount: amount})
+			}
+
+			// Largest expense categories first
+			sort.Slice(summary.Categories, func(i, j int) bool {
+				if summary.Categories[i].Amount != summary.Categories[j].Amount {
+					return summary.Categories[i].Amount > summary.Categories[j].Amount
+				}
+				return summary.Categories[i].Category < summary.Categories[j].Category
+			})
+
+			account.Currencies = append(account.Currencies, *summary)
+		}
+
+		sort.Slice(account.Currencies, func(i, j int) bool {
+			return account.Currencies[i].Currency < account.Currencies[j].Currency
+		})
+
+		report.Accounts = append(report.Accounts, account)
+	}
+
+	sort.Slice(report.Accounts, func(i, j int) bool {
+		return report.Accounts[i].Name < report.Accounts[j].Name
+	})
+
+	return report
+}
+
+// String renders the report as an aligned plain-text table
+func (r Report) String() string {
+	var sb strings.Builder
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "Account\tCurrency\tCount\tIncome\tExpense\tNet")
+	for _, account := range r.Accounts {
+		for _, summary := range account.Currencies {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%.2f\t%.2f\t%.2f\n",
+				account.Name, summary.Currency, summary.Count, summary.Income, summary.Expense, summary.Net)
+			for _, category := range summary.Categories {
+				fmt.Fprintf(tw, "  %s\t\t\t\t%.2f\t\n", category.Category, category.Amount)
+			}
+		}
+	}
+
+	tw.Flush()
+	if r.Unparsed > 0 {
+		fmt.Fprintf(&sb, "\n%d messages produced no transaction (%d skipped as OTPs or notices).\n", r.Unparsed, r.Skipped)
+	}
+	return sb.String()
+}
@@ -0,0 +1,108 @@
+package report
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"sms-parser/internal/models"
+)
+
+func TestSummarize(t *testing.T) {
+	tx := func(amount float64, currency, category string) models.Transaction {
+		return models.Transaction{Amount: amount, Currency: currency, Category: category}
+	}
+
+	tests := []struct {
+		name     string
+		accounts []models.Account
+		unparsed []models.UnparsedMessage
+		want     Report
+	}{
+		{
+			name: "totals and counts per account",
+			accounts: []models.Account{
+				{Name: "NBE_Card_5678", Transactions: []models.Transaction{
+					tx(5000, "EGP", models.CatIncome),
+				}},
+				{Name: "CIB_Credit_Card_4321", Transactions: []models.Transaction{
+					tx(-250, "EGP", models.CatFood),
+					tx(-100, "EGP", models.CatTransport),
+					tx(-150, "EGP", models.CatFood),
+					tx(400, "EGP", models.CatGeneral),
+				}},
+			},
+			want: Report{Accounts: []AccountSummary{
+				{Name: "CIB_Credit_Card_4321", Currencies: []CurrencySummary{{
+					Currency: "EGP", Count: 4, Income: 400, Expense: 500, Net: -100,
+					Categories: []CategoryTotal{{models.CatFood, 400}, {models.CatTransport, 100}},
+				}}},
+				{Name: "NBE_Card_5678", Currencies: []CurrencySummary{{
+					Currency: "EGP", Count: 1, Income: 5000, Net: 5000,
+				}}},
+			}},
+		},
+		{
+			name: "currencies are sub-totalled separately",
+			accounts: []models.Account{
+				{Name: "CIB_Credit_Card_4321", Transactions: []models.Transaction{
+					tx(-20, "USD", models.CatLife),
+					tx(-250, "EGP", models.CatFood),
+					tx(-5, "USD", models.CatLife),
+				}},
+			},
+			want: Report{Accounts: []AccountSummary{
+				{Name: "CIB_Credit_Card_4321", Currencies: []CurrencySummary{
+					{
+						Currency: "EGP", Count: 1, Expense: 250, Net: -250,
+						Categories: []CategoryTotal{{models.CatFood, 250}},
+					},
+					{
+						Currency: "USD", Count: 2, Expense: 25, Net: -25,
+						Categories: []CategoryTotal{{models.CatLife, 25}},
+					},
+				}},
+			}},
+		},
+		{
+			name: "unparsed and skipped messages",
+			accounts: []models.Account{
+				{Name: "Empty"},
+			},
+			unparsed: []models.UnparsedMessage{
+				{Reason: "no amount matched"},
+				{Reason: "OTP or login message", Skipped: true},
+				{Reason: "statement notice", Skipped: true},
+			},
+			want: Report{Unparsed: 3, Skipped: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summarize(tt.accounts, tt.unparsed)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Summarize() = %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReportString(t *testing.T) {
+	report := Summarize([]models.Account{
+		{Name: "CIB_Credit_Card_4321", Transactions: []models.Transaction{
+			{Amount: -250, Currency: "EGP", Category: models.CatFood},
+		}},
+	}, []models.UnparsedMessage{{Reason: "no amount matched"}})
+
+	got := report.String()
+	for _, want := range []string{
+		"Account               Currency  Count  Income  Expense  Net",
+		"CIB_Credit_Card_4321  EGP       1      0.00    250.00   -250.00",
+		"1 messages produced no transaction (0 skipped as OTPs or notices).",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("String() = %q, want it to contain %q", got, want)
+		}
+	}
+}
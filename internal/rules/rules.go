@@ -0,0 +1,169 @@
+package rules
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// RuleSet is a declarative set of bank definitions loaded from a rules file
+type RuleSet struct {
+	Banks []Bank `json:"banks" yaml:"banks"`
+}
+
+// Bank describes how to parse messages from one bank, which may send from
+// several sender addresses
+type Bank struct {
+	// Sender and Senders are the sender addresses; after loading, Senders
+	// holds both
+	Sender  string   `json:"sender" yaml:"sender"`
+	Senders []string `json:"senders" yaml:"senders"`
+	// SkipWords mark messages that are not transactions (OTPs, promotions);
+	// a message containing any of them, case-insensitively, is skipped
+	SkipWords []string `json:"skipWords" yaml:"skipWords"`
+	// IncomeWords decide the direction of "auto" patterns without a sign
+	// group: a message containing any of them is income
+	IncomeWords []string  `json:"incomeWords" yaml:"incomeWords"`
+	Patterns    []Pattern `json:"patterns" yaml:"patterns"`
+}
+
+// Pattern type values beyond models.TypeExpense and models.TypeIncome
+const (
+	// TypeAuto takes the direction from the sign group or the bank's IncomeWords
+	TypeAuto = "auto"
+)
+
+// Pattern is a regex with named capture groups (amount, currency, payee,
+// sign) that turns a matching message into a transaction
+type Pattern struct {
+	Name        string `json:"name" yaml:"name"`
+	Regex       string `json:"regex" yaml:"regex"`
+	Type        string `json:"type" yaml:"type"`
+	TargetGroup string `json:"targetGroup" yaml:"targetGroup"`
+	Payee       string `json:"payee" yaml:"payee"`
+
+	compiled *regexp.Regexp
+}
+
+// Load reads a JSON or YAML rules file and validates every pattern in it
+func Load(path string) (*RuleSet, error) {
+	var ruleSet RuleSet
+	if err := utils.DecodeFile(path, &ruleSet); err != nil {
+		return nil, err
+	}
+
+	if err := ruleSet.compile(); err != nil {
+		return nil, fmt.Errorf("invalid rules file %s: %w", path, err)
+	}
+
+	return &ruleSet, nil
+}
+
+// compile validates the rule set and pre-compiles all regex patterns
+func (rs *RuleSet) compile() error {
+	for i := range rs.Banks {
+		bank := &rs.Banks[i]
+		if bank.Sender != "" {
+			bank.Senders = append([]string{bank.Sender}, bank.Senders...)
+		} else if len(bank.Senders) > 0 {
+			bank.Sender = bank.Senders[0]
+		}
+		if len(bank.Senders) == 0 {
+			return fmt.Errorf("bank #%d has no sender", i+1)
+		}
+		if len(bank.Patterns) == 0 {
+			return fmt.Errorf("sender %q has no patterns", bank.Sender)
+		}
+		for j, word := range bank.SkipWords {
+			bank.SkipWords[j] = strings.ToLower(word)
+		}
+		for j, word := range bank.IncomeWords {
+			bank.IncomeWords[j] = strings.ToLower(word)
+		}
+
+		for j := range bank.Patterns {
+			pattern := &bank.Patterns[j]
+			if pattern.Name == "" {
+				pattern.Name = fmt.Sprintf("pattern #%d", j+1)
+			}
+
+			compiled, err := regexp.Compile(pattern.Regex)
+			if err != nil {
+				return fmt.Errorf("sender %q, pattern %q: invalid regex: %w", bank.Sender, pattern.Name, err)
+			}
+			if compiled.SubexpIndex("amount") < 0 {
+				return fmt.Errorf("sender %q, pattern %q: regex has no named group (?P<amount>...)", bank.Sender, pattern.Name)
+			}
+
+			switch strings.ToLower(pattern.Type) {
+			case "", "expense":
+				pattern.Type = models.TypeExpense
+			case "income":
+				pattern.Type = models.TypeIncome
+			case TypeAuto:
+				pattern.Type = TypeAuto
+				if compiled.SubexpIndex("sign") < 0 && len(bank.IncomeWords) == 0 {
+					return fmt.Errorf("sender %q, pattern %q: type auto needs a (?P<sign>...) group or incomeWords", bank.Sender, pattern.Name)
+				}
+			default:
+				return fmt.Errorf("sender %q, pattern %q: unknown type %q (use expense, income, or auto)", bank.Sender, pattern.Name, pattern.Type)
+			}
+
+			if pattern.TargetGroup == "" {
+				return fmt.Errorf("sender %q, pattern %q: targetGroup is required", bank.Sender, pattern.Name)
+			}
+
+			pattern.compiled = compiled
+		}
+	}
+
+	return nil
+}
+
+// Match applies the pattern to a message body and returns its named captures
+func (p *Pattern) Match(body string) (map[string]string, bool) {
+	match := p.compiled.FindStringSubmatch(body)
+	if match == nil {
+		return nil, false
+	}
+
+	captures := make(map[string]string)
+	for i, name := range p.compiled.SubexpNames() {
+		if name != "" {
+			captures[name] = match[i]
+		}
+	}
+	return captures, true
+}
+
+// Skips reports whether body contains one of the bank's skip words
+func (b *Bank) Skips(body string) bool {
+	return utils.Contains(strings.ToLower(body), b.SkipWords...)
+}
+
+// TypeOf resolves the transaction type of a message matched by pattern. For
+// auto patterns, a captured sign of "+", "cr", or "credit" means income and
+// any other captured sign expense; without a sign the bank's IncomeWords
+// decide.
+func (b *Bank) TypeOf(pattern *Pattern, body string, captures map[string]string) string {
+	if pattern.Type != TypeAuto {
+		return pattern.Type
+	}
+
+	if sign := strings.ToLower(strings.TrimSpace(captures["sign"])); sign != "" {
+		switch sign {
+		case "+", "cr", "credit":
+			return models.TypeIncome
+		default:
+			return models.TypeExpense
+		}
+	}
+
+	if utils.Contains(strings.ToLower(body), b.IncomeWords...) {
+		return models.TypeIncome
+	}
+	return models.TypeExpense
+}
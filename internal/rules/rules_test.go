@@ -0,0 +1,184 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"sms-parser/internal/models"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		data string
+		// wantErr lists substrings of the expected error; none means success
+		wantErr []string
+	}{
+		{
+			name: "valid YAML",
+			file: "rules.yaml",
+			data: `banks:
+  - senders: [MyBank, MYBANK-EG]
+    skipWords: [OTP]
+    patterns:
+      - name: purchase
+        regex: 'spent (?P<currency>[A-Z]{3}) (?P<amount>[\d,.]+) at (?P<payee>.+?)\.'
+        targetGroup: MyBank
+`,
+		},
+		{
+			name: "valid JSON",
+			file: "rules.json",
+			data: `{"banks": [{"sender": "MyBank", "incomeWords": ["credited"], "patterns": [
+  {"regex": "(?P<amount>[\\d,.]+)", "type": "auto", "targetGroup": "MyBank"}
+]}]}`,
+		},
+		{
+			name:    "invalid regex",
+			file:    "rules.yaml",
+			data:    "banks:\n  - sender: MyBank\n    patterns:\n      - name: broken\n        regex: 'spent (?P<amount>[\\d.]+'\n        targetGroup: MyBank\n",
+			wantErr: []string{`"MyBank"`, `"broken"`, `spent (?P<amount>[\d.]+`},
+		},
+		{
+			name:    "no amount group",
+			file:    "rules.yaml",
+			data:    "banks:\n  - sender: MyBank\n    patterns:\n      - regex: 'spent ([\\d.]+)'\n        targetGroup: MyBank\n",
+			wantErr: []string{`"pattern #1"`, "(?P<amount>...)"},
+		},
+		{
+			name:    "auto without sign or income words",
+			file:    "rules.yaml",
+			data:    "banks:\n  - sender: MyBank\n    patterns:\n      - regex: '(?P<amount>[\\d.]+)'\n        type: auto\n        targetGroup: MyBank\n",
+			wantErr: []string{"type auto needs"},
+		},
+		{
+			name:    "unknown type",
+			file:    "rules.yaml",
+			data:    "banks:\n  - sender: MyBank\n    patterns:\n      - regex: '(?P<amount>[\\d.]+)'\n        type: refund\n        targetGroup: MyBank\n",
+			wantErr: []string{`unknown type "refund"`},
+		},
+		{
+			name:    "no sender",
+			file:    "rules.yaml",
+			data:    "banks:\n  - patterns:\n      - regex: '(?P<amount>[\\d.]+)'\n        targetGroup: MyBank\n",
+			wantErr: []string{"bank #1 has no sender"},
+		},
+		{
+			name:    "no target group",
+			file:    "rules.yaml",
+			data:    "banks:\n  - sender: MyBank\n    patterns:\n      - regex: '(?P<amount>[\\d.]+)'\n",
+			wantErr: []string{"targetGroup is required"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tt.file)
+			if err := os.WriteFile(path, []byte(tt.data), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			ruleSet, err := Load(path)
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Load() error = %v", err)
+				}
+				if len(ruleSet.Banks) != 1 || ruleSet.Banks[0].Sender != "MyBank" {
+					t.Errorf("Load() banks = %+v, want one bank with sender MyBank", ruleSet.Banks)
+				}
+				return
+			}
+
+			if err == nil {
+				t.Fatal("Load() succeeded, want an error")
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Load() error = %q, want it to mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPatternMatch(t *testing.T) {
+	ruleSet := &RuleSet{Banks: []Bank{{
+		Senders:     []string{"MyBank"},
+		SkipWords:   []string{"OTP"},
+		IncomeWords: []string{"Credited"},
+		Patterns: []Pattern{{
+			Name:        "movement",
+			Regex:       `(?P<sign>Cr|Dr)?\s*(?P<currency>[A-Z]{3}) (?P<amount>[\d,.]+)(?: at (?P<payee>.+?)\.)?`,
+			Type:        "AUTO",
+			TargetGroup: "MyBank",
+		}},
+	}}}
+	if err := ruleSet.compile(); err != nil {
+		t.Fatalf("compile() error = %v", err)
+	}
+	bank := &ruleSet.Banks[0]
+	pattern := &bank.Patterns[0]
+
+	tests := []struct {
+		name      string
+		body      string
+		wantMatch bool
+		wantSkip  bool
+		amount    string
+		payee     string
+		wantType  string
+	}{
+		{
+			name:      "purchase",
+			body:      "Spent EGP 1,250.50 at CARREFOUR. Thank you",
+			wantMatch: true,
+			amount:    "1,250.50",
+			payee:     "CARREFOUR",
+			wantType:  models.TypeExpense,
+		},
+		{
+			name:      "credit sign",
+			body:      "Cr EGP 500.00 to your account",
+			wantMatch: true,
+			amount:    "500.00",
+			wantType:  models.TypeIncome,
+		},
+		{
+			name:      "income word without a sign",
+			body:      "Your account was credited with EGP 300",
+			wantMatch: true,
+			amount:    "300",
+			wantType:  models.TypeIncome,
+		},
+		{
+			name:     "OTP",
+			body:     "Your OTP is 1234",
+			wantSkip: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := bank.Skips(tt.body); got != tt.wantSkip {
+				t.Errorf("Skips() = %v, want %v", got, tt.wantSkip)
+			}
+
+			captures, ok := pattern.Match(tt.body)
+			if ok != tt.wantMatch {
+				t.Fatalf("Match() matched = %v, want %v", ok, tt.wantMatch)
+			}
+			if !ok {
+				return
+			}
+			if captures["amount"] != tt.amount || captures["payee"] != tt.payee {
+				t.Errorf("Match() amount, payee = %q, %q, want %q, %q", captures["amount"], captures["payee"], tt.amount, tt.payee)
+			}
+			if got := bank.TypeOf(pattern, tt.body, captures); got != tt.wantType {
+				t.Errorf("TypeOf() = %q, want %q", got, tt.wantType)
+			}
+		})
+	}
+}
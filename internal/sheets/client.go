@@ -0,0 +1,245 @@
+package sheets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"sms-parser/internal/models"
+)
+
+// DefaultBaseURL is the Google Sheets API endpoint
+const DefaultBaseURL = "https://sheets.googleapis.com/v4"
+
+// Header is the first row of every tab; the ID column holds each
+// transaction's stable ID, by which rows already in the tab are recognized
+var Header = []any{"Date", "Payee", "Amount", "Currency", "Type", "Category", "Note", "Reference", "Card", "ID"}
+
+// idColumn is the column letter of the ID in Header
+const idColumn = "J"
+
+// Result summarizes a push: transactions whose ID is already in their tab
+// are skipped as duplicates
+type Result struct {
+	Created    int
+	Duplicates int
+}
+
+// Client calls the Google Sheets API as a service account
+type Client struct {
+	account    *ServiceAccount
+	baseURL    string
+	httpClient *http.Client
+
+	accessToken string
+	// tabs holds the tab names of each spreadsheet, once fetched
+	tabs map[string]map[string]bool
+}
+
+// New creates a Client for the Sheets API at baseURL (DefaultBaseURL when
+// empty) authenticating as the service account
+func New(account *ServiceAccount, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Client{
+		account:    account,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		tabs:       make(map[string]map[string]bool),
+	}
+}
+
+// NewRow converts a parsed transaction to a row in Header order. Amounts are
+// numbers; every other cell is stored as text.
+func NewRow(tx models.Transaction) []any {
+	return []any{
+		tx.Timestamp.Format("2006-01-02 15:04:05"),
+		tx.Payee,
+		tx.Amount,
+		tx.Currency,
+		tx.Type,
+		tx.Category,
+		strings.Join(strings.Fields(tx.Note), " "),
+		tx.Reference,
+		tx.CardLast4,
+		tx.ID,
+	}
+}
+
+// AppendTransactions appends the transactions missing from the tab, creating
+// the tab with a header row if it does not exist
+func (c *Client) AppendTransactions(ctx context.Context, spreadsheetID, tab string, transactions []models.Transaction) (Result, error) {
+	var result Result
+
+	created, err := c.ensureTab(ctx, spreadsheetID, tab)
+	if err != nil {
+		return result, err
+	}
+
+	existing := make(map[string]bool)
+	empty := created
+	if !created {
+		if existing, empty, err = c.existingIDs(ctx, spreadsheetID, tab); err != nil {
+			return result, err
+		}
+	}
+
+	var rows [][]any
+	if empty {
+		rows = append(rows, Header)
+	}
+	for _, tx := range transactions {
+		if existing[tx.ID] {
+			result.Duplicates++
+			continue
+		}
+		existing[tx.ID] = true
+		rows = append(rows, NewRow(tx))
+		result.Created++
+	}
+	if result.Created == 0 {
+		return result, nil
+	}
+
+	// RAW keeps notes that start with "=" from being read as formulas
+	query := url.Values{"valueInputOption": {"RAW"}, "insertDataOption": {"INSERT_ROWS"}}
+	path := fmt.Sprintf("/spreadsheets/%s/values/%s:append?%s", url.PathEscape(spreadsheetID), url.PathEscape(a1Range(tab, "A:"+idColumn)), query.Encode())
+	if err := c.do(ctx, http.MethodPost, path, map[string]any{"values": rows}, nil); err != nil {
+		return Result{Duplicates: result.Duplicates}, fmt.Errorf("error appending to tab %q: %w", tab, err)
+	}
+
+	return result, nil
+}
+
+// ensureTab creates the tab unless the spreadsheet already has it, reporting
+// whether it was created
+func (c *Client) ensureTab(ctx context.Context, spreadsheetID, tab string) (bool, error) {
+	tabs, ok := c.tabs[spreadsheetID]
+	if !ok {
+		var spreadsheet struct {
+			Sheets []struct {
+				Properties struct {
+					Title string `json:"title"`
+				} `json:"properties"`
+			} `json:"sheets"`
+		}
+		path := fmt.Sprintf("/spreadsheets/%s?fields=sheets.properties.title", url.PathEscape(spreadsheetID))
+		if err := c.do(ctx, http.MethodGet, path, nil, &spreadsheet); err != nil {
+			return false, fmt.Errorf("error reading spreadsheet %s: %w", spreadsheetID, err)
+		}
+
+		tabs = make(map[string]bool)
+		for _, sheet := range spreadsheet.Sheets {
+			tabs[sheet.Properties.Title] = true
+		}
+		c.tabs[spreadsheetID] = tabs
+	}
+	if tabs[tab] {
+		return false, nil
+	}
+
+	body := map[string]any{
+		"requests": []any{
+			map[string]any{"addSheet": map[string]any{"properties": map[string]any{"title": tab}}},
+		},
+	}
+	path := fmt.Sprintf("/spreadsheets/%s:batchUpdate", url.PathEscape(spreadsheetID))
+	if err := c.do(ctx, http.MethodPost, path, body, nil); err != nil {
+		return false, fmt.Errorf("error creating tab %q: %w", tab, err)
+	}
+	tabs[tab] = true
+	return true, nil
+}
+
+// existingIDs returns the IDs in the tab's ID column, and whether the column
+// is empty (so the tab still needs its header)
+func (c *Client) existingIDs(ctx context.Context, spreadsheetID, tab string) (map[string]bool, bool, error) {
+	var values struct {
+		Values [][]string `json:"values"`
+	}
+	path := fmt.Sprintf("/spreadsheets/%s/values/%s", url.PathEscape(spreadsheetID), url.PathEscape(a1Range(tab, idColumn+":"+idColumn)))
+	if err := c.do(ctx, http.MethodGet, path, nil, &values); err != nil {
+		return nil, false, fmt.Errorf("error reading tab %q: %w", tab, err)
+	}
+
+	ids := make(map[string]bool, len(values.Values))
+	for _, row := range values.Values {
+		if len(row) > 0 && row[0] != "" {
+			ids[row[0]] = true
+		}
+	}
+	return ids, len(values.Values) == 0, nil
+}
+
+// a1Range returns an A1 range on the tab, quoting the tab name
+func a1Range(tab, cells string) string {
+	return "'" + strings.ReplaceAll(tab, "'", "''") + "'!" + cells
+}
+
+// do sends a JSON request and decodes the JSON response into out, if given,
+// requesting an access token first if there is none yet
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	if c.accessToken == "" {
+		token, err := c.account.token(ctx, c.httpClient)
+		if err != nil {
+			return err
+		}
+		c.accessToken = token
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("error encoding request: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error calling Google Sheets: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading Google Sheets response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return fmt.Errorf("Google Sheets returned %s: %s", resp.Status, apiErr.Error.Message)
+		}
+		return fmt.Errorf("Google Sheets returned %s", resp.Status)
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("error decoding Google Sheets response: %w", err)
+		}
+	}
+	return nil
+}
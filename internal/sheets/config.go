@@ -0,0 +1,45 @@
+package sheets
+
+import (
+	"fmt"
+	"strings"
+
+	"sms-parser/internal/utils"
+)
+
+// Config names the spreadsheet transactions are appended to and, optionally,
+// the tab each group goes into
+type Config struct {
+	// SpreadsheetID is the ID in the spreadsheet's address, between /d/ and /edit
+	SpreadsheetID string `json:"spreadsheet_id" yaml:"spreadsheet_id"`
+	// Tabs maps a group (e.g. "CIB_Current_Debit") to a tab name; groups
+	// without an entry go into a tab named after the group
+	Tabs map[string]string `json:"tabs" yaml:"tabs"`
+}
+
+// LoadConfig reads a Config from a YAML or JSON file
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	if err := utils.DecodeFile(path, &cfg); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.SpreadsheetID == "" {
+		return Config{}, fmt.Errorf("missing spreadsheet_id in %s", path)
+	}
+	for group, tab := range cfg.Tabs {
+		if strings.TrimSpace(tab) == "" {
+			return Config{}, fmt.Errorf("empty tab name for %q in %s", group, path)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Tab returns the tab name for a group
+func (c Config) Tab(group string) string {
+	if tab, ok := c.Tabs[group]; ok {
+		return tab
+	}
+	return group
+}
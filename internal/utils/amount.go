@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseAmount parses a number as written in bank messages. Eastern Arabic
+// digits and separators are accepted, spaces are treated as digit grouping,
+// and when both "," and "." appear the last one is the decimal separator.
+// A lone "," is a thousands separator when followed by exactly three digits
+// ("1,000") and a decimal separator otherwise ("345,67"). A lone "." is
+// always a decimal separator, so "1.250" is 1.25, as in dinar amounts.
+func ParseAmount(s string) (float64, error) {
+	clean := strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, NormalizeDigits(s))
+	if clean == "" {
+		return 0, errors.New("empty amount")
+	}
+
+	// Decide which separator, if any, is the decimal one
+	lastComma := strings.LastIndex(clean, ",")
+	lastDot := strings.LastIndex(clean, ".")
+	decimal, group := ".", ","
+	switch {
+	case lastComma > lastDot && lastDot >= 0:
+		decimal, group = ",", "."
+	case lastComma >= 0 && lastDot < 0:
+		if strings.Count(clean, ",") == 1 && len(clean)-lastComma-1 != 3 {
+			decimal, group = ",", "."
+		} else {
+			decimal = ""
+		}
+	case lastDot >= 0 && lastComma < 0 && strings.Count(clean, ".") > 1:
+		// "1.234.567" uses dots for grouping
+		decimal, group = "", "."
+	}
+
+	integer, fraction := clean, ""
+	if decimal != "" {
+		if i := strings.LastIndex(clean, decimal); i >= 0 {
+			integer, fraction = clean[:i], clean[i+1:]
+		}
+	}
+
+	// Grouped digits must come in threes after the first group
+	groups := strings.Split(integer, group)
+	for i, digits := range groups {
+		if digits == "" || len(digits) > 3 && len(groups) > 1 || i > 0 && len(digits) != 3 {
+			return 0, fmt.Errorf("invalid amount %q", s)
+		}
+	}
+
+	clean = strings.Join(groups, "")
+	if fraction != "" {
+		clean += "." + fraction
+	}
+
+	amount, err := strconv.ParseFloat(clean, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	return amount, nil
+}
+
+// threeDecimalCurrencies are the currencies whose minor unit is a thousandth
+var threeDecimalCurrencies = map[string]bool{
+	"KWD": true, "JOD": true, "BHD": true, "OMR": true, "TND": true, "IQD": true, "LYD": true,
+}
+
+// AmountDecimals returns the number of decimals a currency uses: three for
+// the dinars and rials in threeDecimalCurrencies, two otherwise
+func AmountDecimals(currency string) int {
+	if threeDecimalCurrencies[currency] {
+		return 3
+	}
+	return 2
+}
+
+// FormatAmount formats an amount with the number of decimals its currency uses
+func FormatAmount(amount float64, currency string) string {
+	return fmt.Sprintf("%.*f", AmountDecimals(currency), amount)
+}
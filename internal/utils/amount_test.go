@@ -0,0 +1,46 @@
+package utils
+
+import "testing"
+
+func TestParseAmount(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    float64
+		wantErr bool
+	}{
+		{name: "plain", input: "250", want: 250},
+		{name: "decimal", input: "250.50", want: 250.5},
+		{name: "comma grouping", input: "1,250.50", want: 1250.5},
+		{name: "comma decimal", input: "1.250,50", want: 1250.5},
+		{name: "space grouping", input: "1 250.50", want: 1250.5},
+		{name: "no-break space grouping", input: "1\u00a0250,50", want: 1250.5},
+		{name: "dot grouping", input: "1.234.567", want: 1234567},
+		{name: "lone comma before three digits is grouping", input: "1,250", want: 1250},
+		{name: "lone comma before two digits is decimal", input: "345,67", want: 345.67},
+		{name: "lone dot is always decimal", input: "1.250", want: 1.25},
+		{name: "three-decimal dinar", input: "12.500", want: 12.5},
+		{name: "Eastern Arabic digits", input: "١٬٢٥٠٫٥٠", want: 1250.5},
+		{name: "empty", input: " ", wantErr: true},
+		{name: "misplaced grouping", input: "12,50,000", wantErr: true},
+		{name: "not a number", input: "abc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAmount(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ParseAmount(%q) = %v, want an error", tt.input, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseAmount(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAmount(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeFile reads a JSON or YAML configuration file into v, choosing the
+// decoder by file extension (.json for JSON, anything else for YAML)
+func DecodeFile(path string, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		if err := json.Unmarshal(data, v); err != nil {
+			return fmt.Errorf("error parsing JSON in %s: %w", path, err)
+		}
+		return nil
+	}
+
+	if err := yaml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("error parsing YAML in %s: %w", path, err)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -13,18 +14,30 @@ func NormalizeCurrency(currStr string) string {
 
 	cleanCurr := strings.ToUpper(strings.TrimSpace(currStr))
 	mapping := map[string]string{
-		"LE":   "EGP",
-		"L.E":  "EGP",
-		"L.E.": "EGP",
-		"EGP":  "EGP",
-		"ج.م":  "EGP",
-		"جم":   "EGP",
-		"جنيه": "EGP",
-		"USD":  "USD",
-		"EUR":  "EUR",
-		"GBP":  "GBP",
-		"TRY":  "TRY",
-		"JPY":  "JPY",
+		"LE":         "EGP",
+		"L.E":        "EGP",
+		"L.E.":       "EGP",
+		"EGP":        "EGP",
+		"ج.م":        "EGP",
+		"جم":         "EGP",
+		"جنيه":       "EGP",
+		"SAR":        "SAR",
+		"SR":         "SAR",
+		"ر.س":        "SAR",
+		"ريال":       "SAR",
+		"ريال سعودي": "SAR",
+		"AED":        "AED",
+		"د.إ":        "AED",
+		"درهم":       "AED",
+		"KSH":        "KES",
+		"د.ك":        "KWD",
+		"د.أ":        "JOD",
+		"د.ا":        "JOD",
+		"USD":        "USD",
+		"EUR":        "EUR",
+		"GBP":        "GBP",
+		"TRY":        "TRY",
+		"JPY":        "JPY",
 	}
 
 	if normalized, ok := mapping[cleanCurr]; ok {
@@ -33,6 +46,24 @@ func NormalizeCurrency(currStr string) string {
 	return cleanCurr
 }
 
+// NormalizeDigits converts Arabic-Indic (٠-٩) and extended Arabic-Indic (۰-۹)
+// digits to ASCII, along with the Arabic decimal and thousands separators
+func NormalizeDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '٠' && r <= '٩':
+			return '0' + (r - '٠')
+		case r >= '۰' && r <= '۹':
+			return '0' + (r - '۰')
+		case r == '٫':
+			return '.'
+		case r == '٬':
+			return ','
+		}
+		return r
+	}, s)
+}
+
 // CleanPayeeName removes payment processor prefixes and trailing digits
 func CleanPayeeName(payeeRaw string) string {
 	if payeeRaw == "" {
@@ -60,6 +91,41 @@ func CleanPayeeName(payeeRaw string) string {
 	return strings.TrimSpace(clean)
 }
 
+// NormalizePayee maps a cleaned payee name to its canonical form using
+// aliases, a map of substrings to canonical names. Keys match
+// case-insensitively anywhere in the name, longer keys first, with runs of
+// whitespace compared as a single space; a name that matches no key is
+// returned unchanged.
+func NormalizePayee(name string, aliases map[string]string) string {
+	if name == "" || len(aliases) == 0 {
+		return name
+	}
+
+	keys := make([]string, 0, len(aliases))
+	for key := range aliases {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) > len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+
+	upper := collapseSpaces(strings.ToUpper(name))
+	for _, key := range keys {
+		if k := collapseSpaces(strings.ToUpper(key)); k != "" && strings.Contains(upper, k) {
+			return aliases[key]
+		}
+	}
+	return name
+}
+
+// collapseSpaces trims s and replaces each run of whitespace with one space
+func collapseSpaces(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
 // Contains checks if text contains any of the given keywords
 func Contains(text string, keywords ...string) bool {
 	for _, keyword := range keywords {
@@ -0,0 +1,42 @@
+package utils
+
+import "testing"
+
+func TestNormalizePayee(t *testing.T) {
+	aliases := map[string]string{
+		"UBER":      "Uber",
+		"UBER EATS": "Uber Eats",
+		"carrefour": "Carrefour",
+		"Vodafone":  "Vodafone",
+	}
+
+	tests := []struct {
+		name    string
+		payee   string
+		aliases map[string]string
+		want    string
+	}{
+		{name: "exact key", payee: "UBER", aliases: aliases, want: "Uber"},
+		{name: "lower-case payee", payee: "uber *trip", aliases: aliases, want: "Uber"},
+		{name: "lower-case key", payee: "CARREFOUR", aliases: aliases, want: "Carrefour"},
+		{name: "mixed case", payee: "VodaFone Cash", aliases: aliases, want: "Vodafone"},
+		{name: "extra spaces in payee", payee: "UBER   BV", aliases: aliases, want: "Uber"},
+		{name: "extra spaces inside longer key", payee: "UBER  \tEATS", aliases: aliases, want: "Uber Eats"},
+		{name: "leading and trailing spaces", payee: "  carrefour  ", aliases: aliases, want: "Carrefour"},
+		{name: "branch suffix", payee: "CARREFOUR MAADI BRANCH", aliases: aliases, want: "Carrefour"},
+		{name: "location suffix", payee: "CARREFOUR CAIRO EG", aliases: aliases, want: "Carrefour"},
+		{name: "longest key wins", payee: "UBER EATS CAIRO", aliases: aliases, want: "Uber Eats"},
+		{name: "no match unchanged", payee: "Spinneys Zayed", aliases: aliases, want: "Spinneys Zayed"},
+		{name: "no aliases unchanged", payee: "uber   trip", aliases: nil, want: "uber   trip"},
+		{name: "empty payee", payee: "", aliases: aliases, want: ""},
+		{name: "empty key ignored", payee: "Spinneys", aliases: map[string]string{"": "Everything"}, want: "Spinneys"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizePayee(tt.payee, tt.aliases); got != tt.want {
+				t.Errorf("NormalizePayee(%q) = %q, want %q", tt.payee, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"regexp"
+	"strings"
+)
+
+// redactPattern matches, in order of preference, text that must be kept
+// (amounts, dates, times, and numbers next to a currency) and runs of four or
+// more digits, which are card, account, phone, or reference numbers. Western
+// and Arabic-Indic digits are both recognized.
+var redactPattern = func() *regexp.Regexp {
+	d := `[0-9\x{0660}-\x{0669}\x{06F0}-\x{06F9}]`
+	currency := `(?:[A-Z]{3}|L\.E\.?|ج\.م|جنيه|جم)`
+	keep := strings.Join([]string{
+		d + `{1,3}(?:[,٬]` + d + `{3})+(?:[.٫]` + d + `+)?`,
+		d + `+[.٫]` + d + `+`,
+		d + `{1,4}[-/]` + d + `{1,2}(?:[-/]` + d + `{1,4})?`,
+		d + `{1,2}:` + d + `{2}(?::` + d + `{2})?`,
+		currency + `\s*` + d + `+`,
+		d + `+\s*(?:[A-Z]{3}\b|L\.E|ج\.م|جنيه|جم)`,
+	}, "|")
+	return regexp.MustCompile(`(` + keep + `)|(` + d + `{4,})`)
+}()
+
+// RedactSensitive masks card, account, phone, and reference numbers (runs of
+// four or more digits) with asterisks, keeping amounts, dates, and currencies
+// intact: "card 2373 charged EGP 2373.00" becomes "card **** charged EGP 2373.00"
+func RedactSensitive(body string) string {
+	return redactPattern.ReplaceAllStringFunc(body, func(match string) string {
+		if parts := redactPattern.FindStringSubmatch(match); parts[1] != "" {
+			return match
+		}
+		return strings.Repeat("*", len([]rune(match)))
+	})
+}
@@ -0,0 +1,50 @@
+package utils
+
+import "testing"
+
+func TestRedactSensitive(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "amount with the card digits",
+			body: "Your card ending 2373 was charged for 2373.00",
+			want: "Your card ending **** was charged for 2373.00",
+		},
+		{
+			name: "grouped amount",
+			body: "Your card ending 2373 was charged for 1,234.50 at CARREFOUR",
+			want: "Your card ending **** was charged for 1,234.50 at CARREFOUR",
+		},
+		{
+			name: "amount after a currency",
+			body: "Your card ending 2373 was charged for EGP 2373 on 15/01 at 12:30",
+			want: "Your card ending **** was charged for EGP 2373 on 15/01 at 12:30",
+		},
+		{
+			name: "amount before a currency",
+			body: "تم خصم 2373 جنيه من حساب 2373",
+			want: "تم خصم 2373 جنيه من حساب ****",
+		},
+		{
+			name: "dates",
+			body: "Reference 123456789 on 2024-01-15",
+			want: "Reference ********* on 2024-01-15",
+		},
+		{
+			name: "Eastern Arabic digits",
+			body: "تم خصم ١٬٢٣٤٫٥٠ جنيه من بطاقة ٢٣٧٣",
+			want: "تم خصم ١٬٢٣٤٫٥٠ جنيه من بطاقة ****",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedactSensitive(tt.body); got != tt.want {
+				t.Errorf("RedactSensitive(%q) = %q, want %q", tt.body, got, tt.want)
+			}
+		})
+	}
+}
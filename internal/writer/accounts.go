@@ -0,0 +1,126 @@
+package writer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// transferAccount is the clearing account for detected internal transfers;
+// both legs post to it, so it nets to zero once both sides are parsed
+const transferAccount = "Assets:Transfers"
+
+// AccountMap maps groups and categories to double-entry account names for the
+// journal formats. Unmapped names fall back to a derived account.
+type AccountMap struct {
+	// Groups maps a group (e.g. "CIB_Current_Debit") to its account
+	// (e.g. "Assets:CIB:Current")
+	Groups map[string]string `json:"groups" yaml:"groups"`
+	// Categories maps a category (e.g. "Food & Drink") to the account on the
+	// other side of its transactions (e.g. "Expenses:Food")
+	Categories map[string]string `json:"categories" yaml:"categories"`
+}
+
+// LoadAccountMap reads an AccountMap from a YAML or JSON file, rejecting
+// account names that are not colon-separated paths under a top-level account
+func LoadAccountMap(path string) (AccountMap, error) {
+	var accounts AccountMap
+	if err := utils.DecodeFile(path, &accounts); err != nil {
+		return AccountMap{}, err
+	}
+
+	for _, mapping := range []map[string]string{accounts.Groups, accounts.Categories} {
+		for name, account := range mapping {
+			if err := validateAccount(account); err != nil {
+				return AccountMap{}, fmt.Errorf("invalid account for %q in %s: %w", name, path, err)
+			}
+		}
+	}
+
+	return accounts, nil
+}
+
+// validateAccount checks that an account name starts with one of the five
+// top-level accounts and that each component starts with a capital letter or
+// digit, as Beancount requires
+func validateAccount(account string) error {
+	root, _, _ := strings.Cut(account, ":")
+	switch root {
+	case "Assets", "Liabilities", "Equity", "Income", "Expenses":
+	default:
+		return fmt.Errorf("%q must start with Assets, Liabilities, Equity, Income, or Expenses", account)
+	}
+
+	for _, component := range strings.Split(account, ":") {
+		if component == "" {
+			return fmt.Errorf("%q has an empty component", account)
+		}
+		if first := []rune(component)[0]; !unicode.IsUpper(first) && !unicode.IsDigit(first) {
+			return fmt.Errorf("component %q of %q must start with a capital letter or digit", component, account)
+		}
+	}
+	return nil
+}
+
+// GroupAccount returns the account of a group: the mapped one, or the group
+// under Liabilities for credit cards and under Assets otherwise
+// (CIB_Current_Debit becomes Assets:CIB-Current-Debit)
+func (m AccountMap) GroupAccount(group string) string {
+	if account, ok := m.Groups[group]; ok {
+		return account
+	}
+
+	root := "Assets"
+	if strings.Contains(group, "Credit_Card") {
+		root = "Liabilities"
+	}
+
+	return root + ":" + accountComponent(group)
+}
+
+// CounterAccount returns the account on the other side of a transaction: the
+// transfer clearing account for internal transfers, the mapped category, or
+// the category under Income for money in and Expenses for money out
+func (m AccountMap) CounterAccount(tx models.Transaction) string {
+	if tx.Type == models.TypeTransfer {
+		return transferAccount
+	}
+	if account, ok := m.Categories[tx.Category]; ok {
+		return account
+	}
+
+	if tx.Amount > 0 {
+		if tx.Category == models.CatIncome {
+			return "Income:Other"
+		}
+		return "Income:" + accountComponent(tx.Category)
+	}
+	return "Expenses:" + accountComponent(tx.Category)
+}
+
+// accountComponent turns a name into an account component that Beancount and
+// Ledger both accept: letters and digits joined by dashes, starting with a
+// capital letter or digit ("Food & Drink" becomes "Food-Drink")
+func accountComponent(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return "Other"
+	}
+
+	component := strings.Join(words, "-")
+	first := []rune(component)[0]
+	switch {
+	case unicode.IsDigit(first), unicode.IsUpper(first):
+		return component
+	case unicode.IsLower(first):
+		return string(unicode.ToUpper(first)) + component[len(string(first)):]
+	default:
+		// Scripts without case, such as Arabic, need a leading capital
+		return "X-" + component
+	}
+}
@@ -0,0 +1,66 @@
+package writer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// beancountFormatter writes a Beancount journal: options, an open directive
+// per account, then one balanced transaction per SMS
+type beancountFormatter struct {
+	accounts AccountMap
+}
+
+// Extension returns the Beancount file extension
+func (f *beancountFormatter) Extension() string {
+	return "beancount"
+}
+
+// Format writes transactions, which must be sorted by date, as a journal that
+// passes bean-check. The second posting of each transaction is left without
+// an amount for Beancount to balance.
+func (f *beancountFormatter) Format(w io.Writer, transactions []models.Transaction) error {
+	buf := bufio.NewWriter(w)
+
+	fmt.Fprintln(buf, `option "title" "SMS transactions"`)
+	if currency := mainCurrency(transactions); currency != "" {
+		fmt.Fprintf(buf, "option \"operating_currency\" \"%s\"\n", currency)
+	}
+	fmt.Fprintln(buf)
+
+	order, opened := journalAccounts(f.accounts, transactions)
+	for _, account := range order {
+		fmt.Fprintf(buf, "%s open %s\n", opened[account], account)
+	}
+
+	for _, tx := range transactions {
+		fmt.Fprintln(buf)
+		fmt.Fprintf(buf, "%s * %s %s\n", tx.Timestamp.Format("2006-01-02"), beancountString(tx.Payee), beancountString(tx.Note))
+		if tx.ID != "" {
+			fmt.Fprintf(buf, "  id: %s\n", beancountString(tx.ID))
+		}
+		if tx.Reference != "" {
+			fmt.Fprintf(buf, "  reference: %s\n", beancountString(tx.Reference))
+		}
+		fmt.Fprintf(buf, "  %s  %s %s\n", f.accounts.GroupAccount(tx.TargetGroup), utils.FormatAmount(tx.Amount, tx.Currency), tx.Currency)
+		fmt.Fprintf(buf, "  %s\n", f.accounts.CounterAccount(tx))
+	}
+
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("error writing Beancount: %w", err)
+	}
+
+	return nil
+}
+
+// beancountString quotes a value as a single-line Beancount string
+func beancountString(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(value) + `"`
+}
@@ -3,91 +3,92 @@ package writer
 import (
 	"encoding/csv"
 	"fmt"
-	"os"
-	"path/filepath"
-	"sort"
+	"io"
 
 	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
 )
 
-// Writer handles CSV file writing
-type Writer struct {
-	outputDir string
+// utf8BOM is the byte order mark written at the start of CSV files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// csvFormatter writes delimited CSV, optionally prefixed with a UTF-8 BOM
+type csvFormatter struct {
+	comma rune
+	bom   bool
+	// withAccount adds a leading account column with the group name
+	withAccount bool
+	// fields replace or add columns with templated values
+	fields Fields
 }
 
-// New creates a new Writer instance
-func New(outputDir string) *Writer {
-	return &Writer{
-		outputDir: outputDir,
-	}
+// Extension returns the CSV file extension
+func (f *csvFormatter) Extension() string {
+	return "csv"
 }
 
-// Write writes transactions to CSV files grouped by account
-func (w *Writer) Write(groupedData map[string][]models.Transaction) error {
-	fieldnames := []string{"date", "payee", "amount", "currency", "type", "category", "note"}
-
-	for groupName, transactions := range groupedData {
-		if len(transactions) == 0 {
-			continue
-		}
-
-		// Sort by date
-		sort.Slice(transactions, func(i, j int) bool {
-			return transactions[i].Date < transactions[j].Date
-		})
+// Format writes transactions as CSV rows
+func (f *csvFormatter) Format(w io.Writer, transactions []models.Transaction) error {
+	fieldnames := []string{"date", "payee", "amount", "currency", "type", "category", "note", "balance", "original_amount", "original_currency", "reference", "card"}
 
-		// Create CSV file
-		filename := filepath.Join(w.outputDir, groupName+".csv")
-		if err := w.writeCSVFile(filename, fieldnames, transactions); err != nil {
-			return err
+	// Write BOM for UTF-8
+	if f.bom {
+		if _, err := w.Write(utf8BOM); err != nil {
+			return fmt.Errorf("error writing BOM: %w", err)
 		}
-
-		fmt.Printf("Created %s with %d transactions.\n", filename, len(transactions))
 	}
 
-	return nil
-}
-
-// writeCSVFile writes a single CSV file
-func (w *Writer) writeCSVFile(filename string, headers []string, transactions []models.Transaction) error {
-	file, err := os.Create(filename)
-	if err != nil {
-		return fmt.Errorf("error creating %s: %w", filename, err)
-	}
-	defer file.Close()
+	writer := csv.NewWriter(w)
+	writer.Comma = f.comma
 
-	// Write BOM for UTF-8
-	if _, err := file.Write([]byte{0xEF, 0xBB, 0xBF}); err != nil {
-		return fmt.Errorf("error writing BOM to %s: %w", filename, err)
+	if f.withAccount {
+		fieldnames = append([]string{"account"}, fieldnames...)
 	}
 
-	writer := csv.NewWriter(file)
-	writer.Comma = ';'
-
 	// Write header
-	if err := writer.Write(headers); err != nil {
-		return fmt.Errorf("error writing header to %s: %w", filename, err)
+	if err := writer.Write(f.fields.header(fieldnames)); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
 	}
 
 	// Write transactions
 	for _, tx := range transactions {
+		// Leave the balance empty when unknown, since a zero balance is meaningful
+		balance := ""
+		if tx.HasBalance {
+			balance = utils.FormatAmount(tx.Balance, tx.Currency)
+		}
+
 		record := []string{
 			tx.Date,
 			tx.Payee,
-			fmt.Sprintf("%.2f", tx.Amount),
+			utils.FormatAmount(tx.Amount, tx.Currency),
 			tx.Currency,
 			tx.Type,
 			tx.Category,
 			tx.Note,
+			balance,
+			utils.FormatAmount(tx.OriginalAmount, tx.OriginalCurrency),
+			tx.OriginalCurrency,
+			tx.Reference,
+			tx.CardLast4,
+		}
+		if f.withAccount {
+			record = append([]string{tx.TargetGroup}, record...)
+		}
+		if len(f.fields) > 0 {
+			var err error
+			if record, err = f.fields.render(fieldnames, record, tx); err != nil {
+				return err
+			}
 		}
 		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("error writing transaction to %s: %w", filename, err)
+			return fmt.Errorf("error writing transaction: %w", err)
 		}
 	}
 
 	writer.Flush()
 	if err := writer.Error(); err != nil {
-		return fmt.Errorf("error flushing writer for %s: %w", filename, err)
+		return fmt.Errorf("error flushing writer: %w", err)
 	}
 
 	return nil
@@ -0,0 +1,169 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fieldFuncs are available in field templates, in addition to Go's
+// built-in template functions
+var fieldFuncs = template.FuncMap{
+	"amount": utils.FormatAmount,
+	"abs": func(amount float64) float64 {
+		if amount < 0 {
+			return -amount
+		}
+		return amount
+	},
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"oneline": func(s string) string { return strings.Join(strings.Fields(s), " ") },
+}
+
+// Field is a CSV column whose value is rendered from a Go template over a
+// models.Transaction, such as "{{.Category}} | {{.Payee}}"
+type Field struct {
+	Name     string
+	Template string
+
+	tmpl *template.Template
+}
+
+// Fields are custom CSV columns in the order they were defined. A field
+// named like a standard column replaces that column's value; other fields
+// are added after the standard columns.
+type Fields []Field
+
+// LoadFields reads Fields from a YAML or JSON file mapping column names to
+// templates, and checks every template against an empty transaction so
+// unknown field names fail before any work is done
+func LoadFields(path string) (Fields, error) {
+	var fields Fields
+	if err := utils.DecodeFile(path, &fields); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields in %s", path)
+	}
+
+	for i, field := range fields {
+		tmpl, err := template.New(field.Name).Funcs(fieldFuncs).Parse(field.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for %q in %s: %w", field.Name, path, err)
+		}
+		if err := tmpl.Execute(&bytes.Buffer{}, models.Transaction{}); err != nil {
+			return nil, fmt.Errorf("invalid template for %q in %s: %w", field.Name, path, err)
+		}
+		fields[i].tmpl = tmpl
+	}
+
+	return fields, nil
+}
+
+// UnmarshalYAML decodes a mapping of column names to templates, keeping the
+// order of the file
+func (f *Fields) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping of column names to templates", node.Line)
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if value.Kind != yaml.ScalarNode {
+			return fmt.Errorf("line %d: the template for %q must be a string", value.Line, key.Value)
+		}
+		if err := f.add(key.Value, value.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UnmarshalJSON decodes an object of column names to templates, keeping the
+// order of the file
+func (f *Fields) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return fmt.Errorf("expected an object of column names to templates")
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		var value string
+		if err := decoder.Decode(&value); err != nil {
+			return fmt.Errorf("the template for %q must be a string", token)
+		}
+		if err := f.add(token.(string), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add appends a field, rejecting empty and repeated column names
+func (f *Fields) add(name, tmpl string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("empty column name")
+	}
+	for _, field := range *f {
+		if field.Name == name {
+			return fmt.Errorf("column %q is defined twice", name)
+		}
+	}
+	*f = append(*f, Field{Name: name, Template: tmpl})
+	return nil
+}
+
+// render returns the record with the fields rendered for tx: columns of
+// header named by a field take its value, and the other fields follow in
+// order, matching Fields.header
+func (f Fields) render(header, record []string, tx models.Transaction) ([]string, error) {
+	record = append([]string(nil), record...)
+
+	var buf bytes.Buffer
+	for _, field := range f {
+		buf.Reset()
+		if err := field.tmpl.Execute(&buf, tx); err != nil {
+			return nil, fmt.Errorf("error rendering %q: %w", field.Name, err)
+		}
+
+		if i := indexOf(header, field.Name); i >= 0 {
+			record[i] = buf.String()
+		} else {
+			record = append(record, buf.String())
+		}
+	}
+
+	return record, nil
+}
+
+// header returns the column names with the fields applied
+func (f Fields) header(header []string) []string {
+	header = append([]string(nil), header...)
+	for _, field := range f {
+		if indexOf(header, field.Name) < 0 {
+			header = append(header, field.Name)
+		}
+	}
+	return header
+}
+
+// indexOf returns the index of name in names, or -1
+func indexOf(names []string, name string) int {
+	for i, existing := range names {
+		if existing == name {
+			return i
+		}
+	}
+	return -1
+}
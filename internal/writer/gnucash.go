@@ -0,0 +1,89 @@
+package writer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// gnucashColumns are the columns of GnuCash's own transaction export, which
+// its CSV importer recognizes with the built-in "GnuCash Export Format" preset
+var gnucashColumns = []string{
+	"Date", "Transaction ID", "Number", "Description", "Notes", "Commodity/Currency",
+	"Void Reason", "Action", "Memo", "Full Account Name", "Account Name",
+	"Amount With Sym", "Amount Num.", "Value With Sym", "Value Num.",
+	"Reconcile", "Reconcile Date", "Rate/Price",
+}
+
+// gnucashFormatter writes a multi-split CSV in GnuCash's export layout: one
+// row per split, with the transaction fields only on the first
+type gnucashFormatter struct {
+	accounts AccountMap
+}
+
+// Extension returns a double extension, so the file is recognizable as a
+// GnuCash import while still opening as CSV
+func (f *gnucashFormatter) Extension() string {
+	return "gnucash.csv"
+}
+
+// Format writes each transaction as two splits: the amount on the group's
+// account and its negation on the counter account
+func (f *gnucashFormatter) Format(w io.Writer, transactions []models.Transaction) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(gnucashColumns); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	for _, tx := range transactions {
+		splits := []struct {
+			account string
+			amount  float64
+		}{
+			{f.accounts.GroupAccount(tx.TargetGroup), tx.Amount},
+			{f.accounts.CounterAccount(tx), -tx.Amount},
+		}
+
+		for i, split := range splits {
+			amount := utils.FormatAmount(split.amount, tx.Currency)
+			record := make([]string, len(gnucashColumns))
+			if i == 0 {
+				record[0] = tx.Timestamp.Format("2006-01-02")
+				record[1] = tx.ID
+				record[2] = tx.Reference
+				record[3] = tx.Payee
+				record[4] = tx.Note
+				record[5] = "CURRENCY::" + tx.Currency
+			}
+			record[9] = split.account
+			record[10] = leafAccount(split.account)
+			record[11] = amount + " " + tx.Currency
+			record[12] = amount
+			record[13] = amount + " " + tx.Currency
+			record[14] = amount
+			record[15] = "n"
+			record[17] = "1"
+
+			if err := writer.Write(record); err != nil {
+				return fmt.Errorf("error writing transaction: %w", err)
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error flushing writer: %w", err)
+	}
+
+	return nil
+}
+
+// leafAccount returns the last component of a colon-separated account name
+func leafAccount(account string) string {
+	return account[strings.LastIndex(account, ":")+1:]
+}
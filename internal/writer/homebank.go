@@ -0,0 +1,86 @@
+package writer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// homebankColumns is the header line of HomeBank's transaction CSV
+var homebankColumns = []string{"date", "paymode", "info", "payee", "memo", "amount", "category", "tags"}
+
+// HomeBank payment modes used for the paymode column
+const (
+	homebankPaymodeNone         = "0"
+	homebankPaymodeCreditCard   = "1"
+	homebankPaymodeBankTransfer = "4"
+	homebankPaymodeDebitCard    = "6"
+)
+
+// homebankFormatter writes semicolon-separated CSV in the layout HomeBank's
+// import assistant expects for transactions
+type homebankFormatter struct{}
+
+// Extension returns the CSV file extension
+func (f *homebankFormatter) Extension() string {
+	return "csv"
+}
+
+// Format writes transactions as HomeBank rows with YYYY-MM-DD dates
+func (f *homebankFormatter) Format(w io.Writer, transactions []models.Transaction) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+
+	if err := writer.Write(homebankColumns); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	for _, tx := range transactions {
+		// HomeBank separates tags with spaces
+		tags := make([]string, 0, len(tx.Tags))
+		for _, tag := range tx.Tags {
+			tags = append(tags, strings.ReplaceAll(tag, " ", "-"))
+		}
+
+		record := []string{
+			tx.Timestamp.Format("2006-01-02"),
+			homebankPaymode(tx),
+			tx.Reference,
+			tx.Payee,
+			strings.Join(strings.Fields(tx.Note), " "),
+			utils.FormatAmount(tx.Amount, tx.Currency),
+			tx.Category,
+			strings.Join(tags, " "),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing transaction: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error flushing writer: %w", err)
+	}
+
+	return nil
+}
+
+// homebankPaymode returns the HomeBank payment mode for a transaction:
+// credit card for credit card groups, debit card when a card is named,
+// bank transfer for transfers, and none otherwise
+func homebankPaymode(tx models.Transaction) string {
+	switch {
+	case strings.Contains(tx.TargetGroup, "Credit_Card"):
+		return homebankPaymodeCreditCard
+	case tx.CardLast4 != "":
+		return homebankPaymodeDebitCard
+	case tx.Type == models.TypeTransfer:
+		return homebankPaymodeBankTransfer
+	default:
+		return homebankPaymodeNone
+	}
+}
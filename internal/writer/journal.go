@@ -0,0 +1,118 @@
+package writer
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sms-parser/internal/models"
+)
+
+// JournalWriter writes every account into a single double-entry journal,
+// since accounts in a journal must be opened once for the whole ledger
+type JournalWriter struct {
+	path      string
+	formatter Formatter
+}
+
+// NewJournalWriter creates a JournalWriter for the journal at path in the
+// requested format ("beancount", "ledger", or "gnucash"), naming accounts
+// with accounts
+func NewJournalWriter(path, format string, accounts AccountMap) (*JournalWriter, error) {
+	formatter, err := newJournalFormatter(format, accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &JournalWriter{path: path, formatter: formatter}, nil
+}
+
+// DefaultJournalName returns the file name used for a journal format when
+// none is given, such as "transactions.beancount"
+func DefaultJournalName(format string) (string, error) {
+	formatter, err := newJournalFormatter(format, AccountMap{})
+	if err != nil {
+		return "", err
+	}
+	return "transactions." + formatter.Extension(), nil
+}
+
+// newJournalFormatter returns the Formatter for a journal format
+func newJournalFormatter(format string, accounts AccountMap) (Formatter, error) {
+	switch format {
+	case "beancount":
+		return &beancountFormatter{accounts: accounts}, nil
+	case "ledger":
+		return &ledgerFormatter{accounts: accounts}, nil
+	case "gnucash":
+		return &gnucashFormatter{accounts: accounts}, nil
+	default:
+		return nil, fmt.Errorf("unsupported journal format %q (use beancount, ledger, or gnucash)", format)
+	}
+}
+
+// Path returns the journal file path
+func (w *JournalWriter) Path() string {
+	return w.path
+}
+
+// Write writes the transactions of all accounts to the journal in date order
+func (w *JournalWriter) Write(accounts []models.Account) error {
+	var transactions []models.Transaction
+	for _, account := range accounts {
+		transactions = append(transactions, account.Transactions...)
+	}
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	// Keep each account's transactions together on ties
+	slices.SortStableFunc(transactions, func(a, b models.Transaction) int {
+		if c := a.Timestamp.Compare(b.Timestamp); c != 0 {
+			return c
+		}
+		return strings.Compare(a.TargetGroup, b.TargetGroup)
+	})
+
+	if err := writeFile(w.path, w.formatter, transactions); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created %s with %d transactions.\n", w.path, len(transactions))
+	return nil
+}
+
+// journalAccounts returns the accounts a journal posts to, each with the date
+// of its first transaction, in order of first use. transactions must be
+// sorted by date.
+func journalAccounts(accounts AccountMap, transactions []models.Transaction) ([]string, map[string]string) {
+	opened := map[string]string{}
+	var order []string
+	for _, tx := range transactions {
+		for _, account := range []string{accounts.GroupAccount(tx.TargetGroup), accounts.CounterAccount(tx)} {
+			if _, ok := opened[account]; !ok {
+				opened[account] = tx.Timestamp.Format("2006-01-02")
+				order = append(order, account)
+			}
+		}
+	}
+	return order, opened
+}
+
+// mainCurrency returns the currency used by most transactions, preferring
+// the alphabetically first on ties
+func mainCurrency(transactions []models.Transaction) string {
+	counts := map[string]int{}
+	for _, tx := range transactions {
+		counts[tx.Currency]++
+	}
+
+	best := ""
+	for currency, count := range counts {
+		if best == "" || count > counts[best] || count == counts[best] && cmp.Less(currency, best) {
+			best = currency
+		}
+	}
+	return best
+}
@@ -0,0 +1,104 @@
+package writer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"sms-parser/internal/models"
+)
+
+// jsonTransaction is the JSON representation of a transaction
+type jsonTransaction struct {
+	ID       string   `json:"id"`
+	Account  string   `json:"account"`
+	Date     string   `json:"date"`
+	Payee    string   `json:"payee"`
+	Amount   float64  `json:"amount"`
+	Currency string   `json:"currency"`
+	Type     string   `json:"type"`
+	Category string   `json:"category"`
+	Note     string   `json:"note"`
+	Balance  *float64 `json:"balance"`
+
+	OriginalAmount   float64 `json:"original_amount"`
+	OriginalCurrency string  `json:"original_currency"`
+	Reference        string  `json:"reference"`
+	Card             string  `json:"card,omitempty"`
+
+	Tags []string `json:"tags,omitempty"`
+
+	Source string `json:"source"`
+	Body   string `json:"body"`
+}
+
+// jsonFormatter writes each group as a JSON array of transactions, or as JSON
+// Lines with one transaction object per line
+type jsonFormatter struct {
+	// lines writes JSON Lines instead of an indented array
+	lines bool
+}
+
+// Extension returns the JSON or JSON Lines file extension
+func (f *jsonFormatter) Extension() string {
+	if f.lines {
+		return "jsonl"
+	}
+	return "json"
+}
+
+// Format writes transactions as an indented JSON array or as JSON Lines.
+// Every record carries its group, so per-account and combined files share
+// one shape.
+func (f *jsonFormatter) Format(w io.Writer, transactions []models.Transaction) error {
+	records := make([]jsonTransaction, 0, len(transactions))
+	for _, tx := range transactions {
+		// A missing balance is encoded as null, since a zero balance is meaningful
+		var balance *float64
+		if tx.HasBalance {
+			balance = &tx.Balance
+		}
+
+		records = append(records, jsonTransaction{
+			ID:       tx.ID,
+			Account:  tx.TargetGroup,
+			Date:     tx.Timestamp.Format(time.RFC3339),
+			Payee:    tx.Payee,
+			Amount:   tx.Amount,
+			Currency: tx.Currency,
+			Type:     tx.Type,
+			Category: tx.Category,
+			Note:     tx.Note,
+			Balance:  balance,
+
+			OriginalAmount:   tx.OriginalAmount,
+			OriginalCurrency: tx.OriginalCurrency,
+			Reference:        tx.Reference,
+			Card:             tx.CardLast4,
+
+			Tags: tx.Tags,
+
+			Source: tx.Source,
+			Body:   tx.Body,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	if f.lines {
+		for _, record := range records {
+			if err := encoder.Encode(record); err != nil {
+				return fmt.Errorf("error encoding JSON Lines: %w", err)
+			}
+		}
+		return nil
+	}
+
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(records); err != nil {
+		return fmt.Errorf("error encoding JSON: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package writer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// ledgerFormatter writes a Ledger/hledger journal: an account declaration
+// per account, then one two-posting entry per SMS
+type ledgerFormatter struct {
+	accounts AccountMap
+}
+
+// Extension returns the Ledger file extension
+func (f *ledgerFormatter) Extension() string {
+	return "ledger"
+}
+
+// Format writes transactions, which must be sorted by date, as entries with
+// the payee on the first line, the note and IDs as comments, and the second
+// posting left without an amount for Ledger to balance
+func (f *ledgerFormatter) Format(w io.Writer, transactions []models.Transaction) error {
+	buf := bufio.NewWriter(w)
+
+	order, _ := journalAccounts(f.accounts, transactions)
+	for _, account := range order {
+		fmt.Fprintf(buf, "account %s\n", account)
+	}
+
+	for _, tx := range transactions {
+		payee := ledgerValue(tx.Payee)
+		if payee == "" {
+			payee = ledgerValue(tx.Category)
+		}
+
+		fmt.Fprintln(buf)
+		fmt.Fprintf(buf, "%s * %s\n", tx.Timestamp.Format("2006/01/02"), payee)
+		if note := ledgerValue(tx.Note); note != "" {
+			fmt.Fprintf(buf, "    ; %s\n", note)
+		}
+		if tx.ID != "" {
+			fmt.Fprintf(buf, "    ; id: %s\n", tx.ID)
+		}
+		if tx.Reference != "" {
+			fmt.Fprintf(buf, "    ; reference: %s\n", ledgerValue(tx.Reference))
+		}
+		fmt.Fprintf(buf, "    %s  %s %s\n", f.accounts.GroupAccount(tx.TargetGroup), utils.FormatAmount(tx.Amount, tx.Currency), tx.Currency)
+		fmt.Fprintf(buf, "    %s\n", f.accounts.CounterAccount(tx))
+	}
+
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("error writing Ledger: %w", err)
+	}
+
+	return nil
+}
+
+// ledgerValue flattens a value to a single line and replaces semicolons,
+// which would otherwise start a comment
+func ledgerValue(value string) string {
+	return strings.ReplaceAll(strings.Join(strings.Fields(value), " "), ";", ",")
+}
@@ -0,0 +1,135 @@
+package writer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+	"unicode"
+
+	"sms-parser/internal/models"
+)
+
+// mergeCSV merges transactions into the rows of an existing CSV file, for
+// --append. Rows already in the file win over new transactions with the same
+// mergeKey, so manual edits survive. The file's dates are read in loc. The
+// returned formatter
+// keeps the existing file's delimiter and BOM. A missing file is not an error:
+// the transactions and formatter are returned unchanged.
+func mergeCSV(filename string, transactions []models.Transaction, f *csvFormatter, loc *time.Location) ([]models.Transaction, *csvFormatter, int, error) {
+	data, err := os.ReadFile(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return transactions, f, len(transactions), nil
+	}
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("error reading %s: %w", filename, err)
+	}
+
+	existing, comma, bom, err := readCSV(data, loc)
+	if err != nil {
+		return nil, nil, 0, fmt.Errorf("error reading %s: %w", filename, err)
+	}
+
+	seen := make(map[string]bool, len(existing))
+	for _, tx := range existing {
+		seen[mergeKey(tx)] = true
+	}
+
+	merged := existing
+	added := 0
+	for _, tx := range transactions {
+		if key := mergeKey(tx); !seen[key] {
+			seen[key] = true
+			merged = append(merged, tx)
+			added++
+		}
+	}
+
+	return merged, &csvFormatter{comma: comma, bom: bom, withAccount: f.withAccount}, added, nil
+}
+
+// mergeKey identifies a transaction across runs for --append, like the
+// parser's deduplication: the counterparty (the reference when there is one,
+// else the payee), the amount, and the time to the second in UTC
+func mergeKey(tx models.Transaction) string {
+	counterparty := tx.Reference
+	if counterparty == "" {
+		counterparty = tx.Payee
+	}
+	return fmt.Sprintf("%s|%s|%.2f|%s", tx.Timestamp.UTC().Format(time.RFC3339), counterparty, tx.Amount, tx.Currency)
+}
+
+// readCSV parses a file written by csvFormatter back into transactions,
+// detecting its delimiter from the header and whether it starts with a BOM.
+// Columns are matched by header name, so files with or without the account
+// column can be read. Dates are read in loc, the timezone they were written in.
+func readCSV(data []byte, loc *time.Location) ([]models.Transaction, rune, bool, error) {
+	bom := bytes.HasPrefix(data, utf8BOM)
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	// The delimiter is the first character after the first column name
+	comma := ';'
+	for _, r := range string(data) {
+		if !unicode.IsLetter(r) && r != '_' {
+			if r != '\n' && r != '\r' {
+				comma = r
+			}
+			break
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, 0, false, err
+	}
+	if len(records) == 0 {
+		return nil, comma, bom, nil
+	}
+
+	columns := make(map[string]int, len(records[0]))
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+	number := func(record []string, name string) float64 {
+		value, _ := strconv.ParseFloat(field(record, name), 64)
+		return value
+	}
+
+	transactions := make([]models.Transaction, 0, len(records)-1)
+	for _, record := range records[1:] {
+		tx := models.Transaction{
+			TargetGroup:      field(record, "account"),
+			Date:             field(record, "date"),
+			Payee:            field(record, "payee"),
+			Amount:           number(record, "amount"),
+			Currency:         field(record, "currency"),
+			Type:             field(record, "type"),
+			Category:         field(record, "category"),
+			Note:             field(record, "note"),
+			OriginalAmount:   number(record, "original_amount"),
+			OriginalCurrency: field(record, "original_currency"),
+			Reference:        field(record, "reference"),
+			CardLast4:        field(record, "card"),
+		}
+		tx.Timestamp, _ = time.ParseInLocation("2006-01-02 15:04:05", tx.Date, loc)
+		if balance := field(record, "balance"); balance != "" {
+			tx.Balance, _ = strconv.ParseFloat(balance, 64)
+			tx.HasBalance = true
+		}
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, comma, bom, nil
+}
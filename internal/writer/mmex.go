@@ -0,0 +1,82 @@
+package writer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// mmexColumns are the columns of the Money Manager EX import file, in the
+// order to select them in MMEX's CSV import dialog
+var mmexColumns = []string{"Date", "Payee", "Amount", "Category", "Number", "Notes"}
+
+// mmexCategories maps categories to MMEX's default category tree, as
+// "Category:Subcategory" or a top-level category; others are imported
+// under their own name, which MMEX creates as needed
+var mmexCategories = map[string]string{
+	models.CatFood:      "Food",
+	models.CatShopping:  "Homeneeds",
+	models.CatHousing:   "Bills:Rent",
+	models.CatVehicle:   "Automobile",
+	models.CatLife:      "Leisure",
+	models.CatComms:     "Bills:Telephone",
+	models.CatFinancial: "Other Expenses",
+	models.CatIncome:    "Income",
+	models.CatGeneral:   "Miscellaneous",
+}
+
+// mmexFormatter writes comma-separated CSV for Money Manager EX's CSV
+// importer, with signed amounts and MMEX category paths
+type mmexFormatter struct{}
+
+// Extension returns the CSV file extension
+func (f *mmexFormatter) Extension() string {
+	return "csv"
+}
+
+// Format writes transactions as MMEX rows with YYYY-MM-DD dates
+func (f *mmexFormatter) Format(w io.Writer, transactions []models.Transaction) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(mmexColumns); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	for _, tx := range transactions {
+		record := []string{
+			tx.Timestamp.Format("2006-01-02"),
+			tx.Payee,
+			utils.FormatAmount(tx.Amount, tx.Currency),
+			mmexCategory(tx),
+			tx.Reference,
+			strings.Join(strings.Fields(tx.Note), " "),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing transaction: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error flushing writer: %w", err)
+	}
+
+	return nil
+}
+
+// mmexCategory returns the MMEX category path of a transaction. MMEX splits
+// category and subcategory at ":", so a colon in a custom category name is
+// replaced to keep it one category.
+func mmexCategory(tx models.Transaction) string {
+	if tx.Type == models.TypeTransfer {
+		return "Transfer"
+	}
+	if category, ok := mmexCategories[tx.Category]; ok {
+		return category
+	}
+	return strings.ReplaceAll(tx.Category, ":", "-")
+}
@@ -0,0 +1,265 @@
+package writer
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// ofxHeader is the OFX 1.02 SGML header that precedes the <OFX> document
+const ofxHeader = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:UTF-8
+CHARSET:NONE
+COMPRESSION:NONE
+OLDFILEUID:NONE
+NEWFILEUID:NONE
+
+`
+
+// ofxXMLHeader is the OFX 2.2 XML declaration and processing instruction
+const ofxXMLHeader = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+`
+
+// ofxDateFormat is the OFX date-time layout (YYYYMMDDHHMMSS)
+const ofxDateFormat = "20060102150405"
+
+// ofxFormatter writes each group as an OFX bank or credit card statement
+type ofxFormatter struct {
+	// withAccount writes one statement per group instead of treating all
+	// transactions as a single statement
+	withAccount bool
+	// xml writes OFX 2.2 XML, where every element is closed, instead of
+	// OFX 1.02 SGML
+	xml bool
+}
+
+// Extension returns the OFX file extension
+func (f *ofxFormatter) Extension() string {
+	return "ofx"
+}
+
+// Format writes transactions as <STMTRS> bank statements, or <CCSTMTRS> for
+// credit card groups, whose account ID is the group name, in OFX 2.2 XML or
+// OFX 1.02 SGML
+func (f *ofxFormatter) Format(w io.Writer, transactions []models.Transaction) error {
+	buf := bufio.NewWriter(w)
+
+	statements := [][]models.Transaction{transactions}
+	if f.withAccount {
+		statements = splitByGroup(transactions)
+	}
+	_, end := ofxDateRange(transactions)
+
+	if f.xml {
+		fmt.Fprint(buf, ofxXMLHeader)
+	} else {
+		fmt.Fprint(buf, ofxHeader)
+	}
+	fmt.Fprintln(buf, "<OFX>")
+	fmt.Fprintln(buf, "<SIGNONMSGSRSV1><SONRS>")
+	fmt.Fprintln(buf, f.status())
+	fmt.Fprintln(buf, f.element("DTSERVER", end))
+	fmt.Fprintln(buf, f.element("LANGUAGE", "ENG"))
+	fmt.Fprintln(buf, "</SONRS></SIGNONMSGSRSV1>")
+
+	// Bank and credit card statements belong to separate message sets
+	var bankStatements, cardStatements [][]models.Transaction
+	for _, statement := range statements {
+		if isCreditCardStatement(statement) {
+			cardStatements = append(cardStatements, statement)
+		} else {
+			bankStatements = append(bankStatements, statement)
+		}
+	}
+	trnUID := 0
+	if len(bankStatements) > 0 || len(cardStatements) == 0 {
+		fmt.Fprintln(buf, "<BANKMSGSRSV1>")
+		for _, statement := range bankStatements {
+			f.writeStatement(buf, trnUID, statement, false)
+			trnUID++
+		}
+		fmt.Fprintln(buf, "</BANKMSGSRSV1>")
+	}
+	if len(cardStatements) > 0 {
+		fmt.Fprintln(buf, "<CREDITCARDMSGSRSV1>")
+		for _, statement := range cardStatements {
+			f.writeStatement(buf, trnUID, statement, true)
+			trnUID++
+		}
+		fmt.Fprintln(buf, "</CREDITCARDMSGSRSV1>")
+	}
+	fmt.Fprintln(buf, "</OFX>")
+
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("error writing OFX: %w", err)
+	}
+
+	return nil
+}
+
+// writeStatement writes one <STMTTRNRS> statement, or <CCSTMTTRNRS> for a
+// credit card, for the transactions of a single account
+func (f *ofxFormatter) writeStatement(buf *bufio.Writer, trnUID int, transactions []models.Transaction, creditCard bool) {
+	// Statement-level fields come from the account's transactions
+	account, currency := "", "EGP"
+	var ledgerBalance float64
+	var hasBalance bool
+	if len(transactions) > 0 {
+		account = transactions[0].TargetGroup
+		currency = transactions[0].Currency
+	}
+	for _, tx := range transactions {
+		if tx.HasBalance {
+			ledgerBalance, hasBalance = tx.Balance, true
+		}
+	}
+	start, end := ofxDateRange(transactions)
+
+	trnRs, stmtRs := "STMTTRNRS", "STMTRS"
+	if creditCard {
+		trnRs, stmtRs = "CCSTMTTRNRS", "CCSTMTRS"
+	}
+
+	fmt.Fprintf(buf, "<%s>\n", trnRs)
+	fmt.Fprintln(buf, f.element("TRNUID", fmt.Sprint(trnUID)))
+	fmt.Fprintln(buf, f.status())
+	fmt.Fprintf(buf, "<%s>\n", stmtRs)
+	fmt.Fprintln(buf, f.element("CURDEF", ofxValue(currency, 3)))
+	if creditCard {
+		fmt.Fprintf(buf, "<CCACCTFROM>%s</CCACCTFROM>\n", f.element("ACCTID", ofxValue(account, 22)))
+	} else {
+		fmt.Fprintf(buf, "<BANKACCTFROM>%s%s%s</BANKACCTFROM>\n", f.element("BANKID", ofxValue(bankID(account), 9)), f.element("ACCTID", ofxValue(account, 22)), f.element("ACCTTYPE", "CHECKING"))
+	}
+	fmt.Fprintf(buf, "<BANKTRANLIST>%s%s\n", f.element("DTSTART", start), f.element("DTEND", end))
+
+	// A reference shared by several transactions (e.g. a purchase and its
+	// fee) cannot serve as their FITID
+	referenceCounts := make(map[string]int, len(transactions))
+	for _, tx := range transactions {
+		referenceCounts[tx.Reference]++
+	}
+
+	for _, tx := range transactions {
+		trnType := "DEBIT"
+		if tx.Amount > 0 {
+			trnType = "CREDIT"
+		}
+
+		fmt.Fprintln(buf, "<STMTTRN>")
+		fmt.Fprintln(buf, f.element("TRNTYPE", trnType))
+		fmt.Fprintln(buf, f.element("DTPOSTED", tx.Timestamp.Format(ofxDateFormat)))
+		fmt.Fprintln(buf, f.element("TRNAMT", utils.FormatAmount(tx.Amount, tx.Currency)))
+		fmt.Fprintln(buf, f.element("FITID", ofxValue(fitID(tx, referenceCounts[tx.Reference] == 1), 255)))
+		fmt.Fprintln(buf, f.element("NAME", ofxValue(tx.Payee, 32)))
+		fmt.Fprintln(buf, f.element("MEMO", ofxValue(tx.Note, 255)))
+		fmt.Fprintln(buf, "</STMTTRN>")
+	}
+
+	fmt.Fprintln(buf, "</BANKTRANLIST>")
+	// Without a balance from the SMS, leave the ledger balance out rather
+	// than report zero
+	if hasBalance {
+		fmt.Fprintf(buf, "<LEDGERBAL>%s%s</LEDGERBAL>\n", f.element("BALAMT", utils.FormatAmount(ledgerBalance, currency)), f.element("DTASOF", end))
+	}
+	fmt.Fprintf(buf, "</%s>\n", stmtRs)
+	fmt.Fprintf(buf, "</%s>\n", trnRs)
+}
+
+// isCreditCardStatement reports whether a statement belongs to a credit card
+// group, which OFX reports in the credit card message set
+func isCreditCardStatement(transactions []models.Transaction) bool {
+	return len(transactions) > 0 && strings.Contains(transactions[0].TargetGroup, "Credit_Card")
+}
+
+// element formats a leaf element, closing it in OFX 2.x XML where SGML leaves
+// it open
+func (f *ofxFormatter) element(tag, value string) string {
+	if f.xml {
+		return fmt.Sprintf("<%s>%s</%s>", tag, value, tag)
+	}
+	return fmt.Sprintf("<%s>%s", tag, value)
+}
+
+// status formats the success <STATUS> aggregate
+func (f *ofxFormatter) status() string {
+	return "<STATUS>" + f.element("CODE", "0") + f.element("SEVERITY", "INFO") + "</STATUS>"
+}
+
+// splitByGroup splits transactions into one slice per group, in order of each
+// group's first appearance and keeping the order within each group
+func splitByGroup(transactions []models.Transaction) [][]models.Transaction {
+	index := map[string]int{}
+	var groups [][]models.Transaction
+	for _, tx := range transactions {
+		i, ok := index[tx.TargetGroup]
+		if !ok {
+			i = len(groups)
+			index[tx.TargetGroup] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], tx)
+	}
+	return groups
+}
+
+// ofxDateRange returns the first and last posting dates of the statement
+func ofxDateRange(transactions []models.Transaction) (string, string) {
+	if len(transactions) == 0 {
+		return "", ""
+	}
+
+	first, last := transactions[0].Timestamp, transactions[0].Timestamp
+	for _, tx := range transactions[1:] {
+		if tx.Timestamp.Before(first) {
+			first = tx.Timestamp
+		}
+		if tx.Timestamp.After(last) {
+			last = tx.Timestamp
+		}
+	}
+	return first.Format(ofxDateFormat), last.Format(ofxDateFormat)
+}
+
+// fitID returns the transaction's FITID: the bank reference when it is
+// unique within the statement, otherwise the transaction's stable hash
+func fitID(tx models.Transaction, uniqueReference bool) string {
+	switch {
+	case tx.Reference != "" && uniqueReference:
+		return tx.Reference
+	case tx.ID != "":
+		return tx.ID
+	}
+
+	// Transactions built outside the parser have no ID
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%.2f|%s|%s", tx.Date, tx.Amount, tx.Payee, tx.Note)))
+	return hex.EncodeToString(sum[:16])
+}
+
+// bankID derives the bank identifier from a group name such as
+// "CIB_Credit_Card_1234"
+func bankID(group string) string {
+	bank, _, _ := strings.Cut(group, "_")
+	return bank
+}
+
+// ofxValue flattens a field to a single line, escapes SGML markup
+// characters, and truncates it to the OFX field length limit
+func ofxValue(value string, maxLen int) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if runes := []rune(value); len(runes) > maxLen {
+		value = string(runes[:maxLen])
+	}
+
+	replacer := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+	return replacer.Replace(value)
+}
@@ -0,0 +1,190 @@
+package writer
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/report"
+	"sms-parser/internal/utils"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// pdfColumns are the columns of the statement's transaction table, with
+// their widths in millimetres on an A4 page with 15 mm margins
+var pdfColumns = []struct {
+	title string
+	width float64
+	align string
+}{
+	{"Date", 28, "L"},
+	{"Payee", 52, "L"},
+	{"Category", 38, "L"},
+	{"Amount", 32, "R"},
+	{"Balance", 30, "R"},
+}
+
+// pdfPalette colours the slices of the category pie chart
+var pdfPalette = [][3]int{
+	{66, 133, 244}, {219, 68, 55}, {244, 180, 0}, {15, 157, 88}, {171, 71, 188},
+	{0, 172, 193}, {255, 112, 67}, {158, 157, 36}, {92, 107, 192}, {120, 144, 156},
+}
+
+// pdfFormatter writes an account statement: totals, a pie chart of expenses
+// per category for each currency, and the transactions
+type pdfFormatter struct{}
+
+// Extension returns the PDF file extension
+func (f *pdfFormatter) Extension() string {
+	return "pdf"
+}
+
+// Format writes transactions as a PDF statement titled with their group.
+// The built-in PDF fonts cover Latin-1 only, so other characters (such as
+// Arabic payees) are shown as dots.
+func (f *pdfFormatter) Format(w io.Writer, transactions []models.Transaction) error {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AliasNbPages("")
+	text := pdf.UnicodeTranslatorFromDescriptor("")
+
+	account := ""
+	if len(transactions) > 0 {
+		account = transactions[0].TargetGroup
+	}
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(0, 5, text(fmt.Sprintf("%s - page %d of {nb}", account, pdf.PageNo())), "", 0, "C", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(0, 9, text(account), "", 1, "L", false, 0, "")
+	if len(transactions) > 0 {
+		pdf.SetFont("Helvetica", "", 10)
+		period := fmt.Sprintf("Statement for %s to %s, %d transactions",
+			transactions[0].Timestamp.Format("2006-01-02"), transactions[len(transactions)-1].Timestamp.Format("2006-01-02"), len(transactions))
+		pdf.CellFormat(0, 6, period, "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	summary := report.Summarize([]models.Account{{Name: account, Transactions: transactions}}, nil)
+	for _, acc := range summary.Accounts {
+		for _, totals := range acc.Currencies {
+			pdfTotals(pdf, text, totals)
+		}
+	}
+
+	pdfTransactions(pdf, text, transactions)
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("error writing PDF: %w", err)
+	}
+	return nil
+}
+
+// pdfTotals writes the income, expense, and net totals of one currency,
+// followed by the pie chart and legend of its expense categories
+func pdfTotals(pdf *fpdf.Fpdf, text func(string) string, totals report.CurrencySummary) {
+	const radius = 22.0
+
+	// Keep the totals, chart, and legend on one page
+	height := 8 + 6.0 + math.Max(2*radius, 5*float64(len(totals.Categories))) + 6
+	if pdf.GetY()+height > 282 {
+		pdf.AddPage()
+	}
+
+	pdf.SetFont("Helvetica", "B", 11)
+	pdf.CellFormat(0, 7, totals.Currency, "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 10)
+	line := fmt.Sprintf("Income %s    Expense %s    Net %s",
+		utils.FormatAmount(totals.Income, totals.Currency),
+		utils.FormatAmount(totals.Expense, totals.Currency),
+		utils.FormatAmount(totals.Net, totals.Currency))
+	pdf.CellFormat(0, 6, line, "", 1, "L", false, 0, "")
+
+	if totals.Expense == 0 {
+		pdf.Ln(3)
+		return
+	}
+
+	top := pdf.GetY() + 2
+	cx, cy := 15+radius, top+radius
+	start := 0.0
+	for i, category := range totals.Categories {
+		sweep := 360 * category.Amount / totals.Expense
+		color := pdfPalette[i%len(pdfPalette)]
+		pdf.SetFillColor(color[0], color[1], color[2])
+		pdf.MoveTo(cx, cy)
+		pdf.ArcTo(cx, cy, radius, radius, 0, start, start+sweep)
+		pdf.ClosePath()
+		pdf.DrawPath("F")
+		start += sweep
+
+		// Legend entry: swatch, category, amount, and share
+		y := top + 5*float64(i)
+		pdf.Rect(15+2*radius+10, y+1, 3, 3, "F")
+		pdf.SetXY(15+2*radius+15, y)
+		pdf.CellFormat(60, 5, text(category.Category), "", 0, "L", false, 0, "")
+		pdf.CellFormat(30, 5, utils.FormatAmount(category.Amount, totals.Currency), "", 0, "R", false, 0, "")
+		pdf.CellFormat(20, 5, fmt.Sprintf("%.0f%%", 100*category.Amount/totals.Expense), "", 0, "R", false, 0, "")
+	}
+
+	pdf.SetXY(15, top+math.Max(2*radius, 5*float64(len(totals.Categories)))+6)
+}
+
+// pdfTransactions writes the transaction table, repeating its header on
+// every page
+func pdfTransactions(pdf *fpdf.Fpdf, text func(string) string, transactions []models.Transaction) {
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		pdf.SetFillColor(235, 235, 235)
+		for _, column := range pdfColumns {
+			pdf.CellFormat(column.width, 7, column.title, "1", 0, column.align, true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+
+	pdf.Ln(2)
+	header()
+	for _, tx := range transactions {
+		if pdf.GetY()+6 > 282 {
+			pdf.AddPage()
+			header()
+		}
+
+		balance := ""
+		if tx.HasBalance {
+			balance = utils.FormatAmount(tx.Balance, tx.Currency)
+		}
+		cells := []string{
+			tx.Timestamp.Format("2006-01-02 15:04"),
+			tx.Payee,
+			tx.Category,
+			utils.FormatAmount(tx.Amount, tx.Currency) + " " + tx.Currency,
+			balance,
+		}
+		for i, column := range pdfColumns {
+			pdf.CellFormat(column.width, 6, fitCell(pdf, text(cells[i]), column.width-2), "1", 0, column.align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+}
+
+// fitCell shortens s with an ellipsis until it fits in width millimetres
+func fitCell(pdf *fpdf.Fpdf, s string, width float64) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if pdf.GetStringWidth(s) <= width {
+		return s
+	}
+	for len(s) > 0 && pdf.GetStringWidth(s+"...") > width {
+		s = s[:len(s)-1]
+	}
+	return s + "..."
+}
@@ -0,0 +1,130 @@
+package writer
+
+import (
+	"database/sql"
+	"fmt"
+
+	"sms-parser/internal/models"
+
+	// Register the pure-Go "postgres" database/sql driver
+	_ "github.com/lib/pq"
+)
+
+// postgresSchema creates the transactions table and its lookup index. The
+// columns match the SQLite table, with date as a timestamptz and amounts as
+// numeric so dashboards can group and sum them directly.
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS transactions (
+	id                TEXT PRIMARY KEY,
+	account           TEXT NOT NULL,
+	date              TIMESTAMPTZ NOT NULL,
+	payee             TEXT NOT NULL,
+	amount            NUMERIC NOT NULL,
+	currency          TEXT NOT NULL,
+	type              TEXT NOT NULL,
+	category          TEXT NOT NULL,
+	note              TEXT NOT NULL,
+	balance           NUMERIC,
+	original_amount   NUMERIC NOT NULL,
+	original_currency TEXT NOT NULL,
+	reference         TEXT NOT NULL,
+	card              TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS transactions_account_date ON transactions (account, date);
+`
+
+// postgresUpsert inserts a transaction or refreshes the row with the same
+// id, returning whether the row is new
+const postgresUpsert = `
+INSERT INTO transactions (id, account, date, payee, amount, currency, type, category, note, balance, original_amount, original_currency, reference, card)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+ON CONFLICT (id) DO UPDATE SET
+	account = excluded.account,
+	date = excluded.date,
+	payee = excluded.payee,
+	amount = excluded.amount,
+	currency = excluded.currency,
+	type = excluded.type,
+	category = excluded.category,
+	note = excluded.note,
+	balance = excluded.balance,
+	original_amount = excluded.original_amount,
+	original_currency = excluded.original_currency,
+	reference = excluded.reference,
+	card = excluded.card
+RETURNING (xmax = 0)
+`
+
+// PostgresWriter upserts transactions into a "transactions" table keyed on
+// models.Transaction.ID
+type PostgresWriter struct {
+	db *sql.DB
+}
+
+// OpenPostgres connects to the PostgreSQL database at dsn (a postgres:// URL
+// or key=value connection string; empty uses the PG* environment variables)
+// and creates the table and index if they don't exist
+func OpenPostgres(dsn string) (*PostgresWriter, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error opening PostgreSQL connection: %w", err)
+	}
+
+	if _, err := db.Exec(postgresSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating transactions table in PostgreSQL: %w", err)
+	}
+
+	return &PostgresWriter{db: db}, nil
+}
+
+// Write upserts all transactions in a single database transaction and
+// reports how many of them were not in the database yet
+func (w *PostgresWriter) Write(accounts []models.Account) error {
+	dbTx, err := w.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting database transaction: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	stmt, err := dbTx.Prepare(postgresUpsert)
+	if err != nil {
+		return fmt.Errorf("error preparing upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	count, added := 0, 0
+	for _, account := range accounts {
+		for _, tx := range account.Transactions {
+			// A missing balance is stored as NULL, since a zero balance is meaningful
+			var balance sql.NullFloat64
+			if tx.HasBalance {
+				balance = sql.NullFloat64{Float64: tx.Balance, Valid: true}
+			}
+
+			var inserted bool
+			if err := stmt.QueryRow(
+				tx.ID, account.Name, tx.Timestamp, tx.Payee, tx.Amount, tx.Currency, tx.Type,
+				tx.Category, tx.Note, balance, tx.OriginalAmount, tx.OriginalCurrency, tx.Reference, tx.CardLast4,
+			).Scan(&inserted); err != nil {
+				return fmt.Errorf("error writing transaction %s: %w", tx.ID, err)
+			}
+			count++
+			if inserted {
+				added++
+			}
+		}
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("error committing transactions: %w", err)
+	}
+
+	fmt.Printf("Upserted %d transactions: %d new, %d already stored.\n", count, added, count-added)
+	return nil
+}
+
+// Close closes the underlying database
+func (w *PostgresWriter) Close() error {
+	return w.db.Close()
+}
@@ -0,0 +1,73 @@
+package writer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// qifFormatter writes Quicken Interchange Format bank registers
+type qifFormatter struct {
+	// withAccount starts an !Account section whenever the group changes
+	withAccount bool
+}
+
+// Extension returns the QIF file extension
+func (f *qifFormatter) Extension() string {
+	return "qif"
+}
+
+// Format writes transactions as QIF records under a !Type header matching the group
+func (f *qifFormatter) Format(w io.Writer, transactions []models.Transaction) error {
+	buf := bufio.NewWriter(w)
+
+	account := ""
+	if !f.withAccount {
+		group := ""
+		if len(transactions) > 0 {
+			group = transactions[0].TargetGroup
+		}
+		fmt.Fprintf(buf, "!Type:%s\n", qifAccountType(group))
+	}
+	for _, tx := range transactions {
+		if f.withAccount && tx.TargetGroup != account {
+			account = tx.TargetGroup
+			accountType := qifAccountType(account)
+			fmt.Fprintf(buf, "!Account\nN%s\nT%s\n^\n!Type:%s\n", qifValue(account), accountType, accountType)
+		}
+
+		fmt.Fprintf(buf, "D%s\n", tx.Timestamp.Format("01/02/2006"))
+		fmt.Fprintf(buf, "T%s\n", utils.FormatAmount(tx.Amount, tx.Currency))
+		if tx.Reference != "" {
+			fmt.Fprintf(buf, "N%s\n", qifValue(tx.Reference))
+		}
+		fmt.Fprintf(buf, "P%s\n", qifValue(tx.Payee))
+		fmt.Fprintf(buf, "L%s\n", qifValue(tx.Category))
+		fmt.Fprintf(buf, "M%s\n", qifValue(tx.Note))
+		fmt.Fprintln(buf, "^")
+	}
+
+	if err := buf.Flush(); err != nil {
+		return fmt.Errorf("error writing QIF: %w", err)
+	}
+
+	return nil
+}
+
+// qifAccountType returns the QIF register type for a group: CCard for credit
+// card groups so Quicken tracks them as liabilities, Bank otherwise
+func qifAccountType(group string) string {
+	if strings.Contains(group, "Credit_Card") {
+		return "CCard"
+	}
+	return "Bank"
+}
+
+// qifValue flattens a field to a single line, since QIF records are line-based
+func qifValue(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
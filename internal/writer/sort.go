@@ -0,0 +1,64 @@
+package writer
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"sms-parser/internal/models"
+)
+
+// SortFields lists the fields transactions can be sorted by
+var SortFields = []string{"date", "amount", "payee", "category"}
+
+// sortOrder orders transactions by a single field
+type sortOrder struct {
+	field string
+	desc  bool
+}
+
+// parseSort parses a "field[:asc|desc]" sort spec; empty means date ascending
+func parseSort(spec string) (sortOrder, error) {
+	if spec == "" {
+		return sortOrder{field: "date"}, nil
+	}
+
+	field, direction, _ := strings.Cut(strings.ToLower(spec), ":")
+	if !slices.Contains(SortFields, field) {
+		return sortOrder{}, fmt.Errorf("invalid sort field %q (valid: %s)", field, strings.Join(SortFields, ", "))
+	}
+
+	switch direction {
+	case "", "asc":
+		return sortOrder{field: field}, nil
+	case "desc":
+		return sortOrder{field: field, desc: true}, nil
+	default:
+		return sortOrder{}, fmt.Errorf("invalid sort direction %q (use asc or desc)", direction)
+	}
+}
+
+// compare orders a before b by the sort field, breaking ties by date.
+// Amounts compare numerically, so expenses (negative) come first ascending.
+func (o sortOrder) compare(a, b models.Transaction) int {
+	var c int
+	switch o.field {
+	case "date":
+		c = strings.Compare(a.Date, b.Date)
+	case "amount":
+		c = cmp.Compare(a.Amount, b.Amount)
+	case "payee":
+		c = strings.Compare(strings.ToLower(a.Payee), strings.ToLower(b.Payee))
+	case "category":
+		c = strings.Compare(a.Category, b.Category)
+	}
+	if o.desc {
+		c = -c
+	}
+
+	if c == 0 {
+		c = strings.Compare(a.Date, b.Date)
+	}
+	return c
+}
@@ -0,0 +1,157 @@
+package writer
+
+import (
+	"database/sql"
+	"fmt"
+
+	"sms-parser/internal/models"
+
+	// Register the pure-Go "sqlite" database/sql driver
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema creates the transactions table and its lookup index. Columns
+// mirror the CSV output, plus the stable id and the account (group) name.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS transactions (
+	id                TEXT PRIMARY KEY,
+	account           TEXT NOT NULL,
+	date              TEXT NOT NULL,
+	payee             TEXT NOT NULL,
+	amount            REAL NOT NULL,
+	currency          TEXT NOT NULL,
+	type              TEXT NOT NULL,
+	category          TEXT NOT NULL,
+	note              TEXT NOT NULL,
+	balance           REAL,
+	original_amount   REAL NOT NULL,
+	original_currency TEXT NOT NULL,
+	reference         TEXT NOT NULL,
+	card              TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS transactions_account_date ON transactions (account, date);
+`
+
+// sqliteUpsert inserts a transaction or refreshes the row with the same id,
+// so re-running on overlapping backups never duplicates rows
+const sqliteUpsert = `
+INSERT INTO transactions (id, account, date, payee, amount, currency, type, category, note, balance, original_amount, original_currency, reference, card)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (id) DO UPDATE SET
+	account = excluded.account,
+	date = excluded.date,
+	payee = excluded.payee,
+	amount = excluded.amount,
+	currency = excluded.currency,
+	type = excluded.type,
+	category = excluded.category,
+	note = excluded.note,
+	balance = excluded.balance,
+	original_amount = excluded.original_amount,
+	original_currency = excluded.original_currency,
+	reference = excluded.reference,
+	card = excluded.card
+`
+
+// sqliteAddCard adds the card column to databases created before it existed
+const sqliteAddCard = `ALTER TABLE transactions ADD COLUMN card TEXT NOT NULL DEFAULT ''`
+
+// SQLiteWriter upserts transactions into a "transactions" table keyed on
+// models.Transaction.ID
+type SQLiteWriter struct {
+	db *sql.DB
+}
+
+// NewSQLiteWriter creates a SQLiteWriter on an open database, creating the
+// table and index if they don't exist and adding columns introduced since.
+// Any database/sql driver that accepts SQLite syntax can be used.
+func NewSQLiteWriter(db *sql.DB) (*SQLiteWriter, error) {
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		return nil, fmt.Errorf("error creating transactions table: %w", err)
+	}
+
+	var hasCard int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info('transactions') WHERE name = 'card'`).Scan(&hasCard); err != nil {
+		return nil, fmt.Errorf("error inspecting transactions table: %w", err)
+	}
+	if hasCard == 0 {
+		if _, err := db.Exec(sqliteAddCard); err != nil {
+			return nil, fmt.Errorf("error adding card column: %w", err)
+		}
+	}
+
+	return &SQLiteWriter{db: db}, nil
+}
+
+// OpenSQLite opens (or creates) the SQLite database file at path
+func OpenSQLite(path string) (*SQLiteWriter, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s: %w", path, err)
+	}
+
+	w, err := NewSQLiteWriter(db)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error preparing %s: %w", path, err)
+	}
+
+	return w, nil
+}
+
+// Write upserts all transactions in a single database transaction and
+// reports how many of them were not in the database yet
+func (w *SQLiteWriter) Write(accounts []models.Account) error {
+	dbTx, err := w.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error starting database transaction: %w", err)
+	}
+	defer dbTx.Rollback()
+
+	var before int
+	if err := dbTx.QueryRow(`SELECT COUNT(*) FROM transactions`).Scan(&before); err != nil {
+		return fmt.Errorf("error counting transactions: %w", err)
+	}
+
+	stmt, err := dbTx.Prepare(sqliteUpsert)
+	if err != nil {
+		return fmt.Errorf("error preparing upsert: %w", err)
+	}
+	defer stmt.Close()
+
+	count := 0
+	for _, account := range accounts {
+		for _, tx := range account.Transactions {
+			// A missing balance is stored as NULL, since a zero balance is meaningful
+			var balance sql.NullFloat64
+			if tx.HasBalance {
+				balance = sql.NullFloat64{Float64: tx.Balance, Valid: true}
+			}
+
+			if _, err := stmt.Exec(
+				tx.ID, account.Name, tx.Date, tx.Payee, tx.Amount, tx.Currency, tx.Type,
+				tx.Category, tx.Note, balance, tx.OriginalAmount, tx.OriginalCurrency, tx.Reference, tx.CardLast4,
+			); err != nil {
+				return fmt.Errorf("error writing transaction %s: %w", tx.ID, err)
+			}
+			count++
+		}
+	}
+
+	var after int
+	if err := dbTx.QueryRow(`SELECT COUNT(*) FROM transactions`).Scan(&after); err != nil {
+		return fmt.Errorf("error counting transactions: %w", err)
+	}
+
+	if err := dbTx.Commit(); err != nil {
+		return fmt.Errorf("error committing transactions: %w", err)
+	}
+
+	fmt.Printf("Upserted %d transactions: %d new, %d already stored (%d in total).\n", count, after-before, count-(after-before), after)
+	return nil
+}
+
+// Close closes the underlying database
+func (w *SQLiteWriter) Close() error {
+	return w.db.Close()
+}
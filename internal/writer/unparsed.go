@@ -0,0 +1,60 @@
+package writer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"time"
+
+	"sms-parser/internal/models"
+)
+
+// WriteUnparsed writes messages that produced no transaction to a CSV report,
+// so missed formats can be reported or turned into new patterns. Dates are
+// shown in loc; nil means the local timezone.
+func WriteUnparsed(filename string, messages []models.UnparsedMessage, loc *time.Location) error {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	// Write BOM for UTF-8
+	if _, err := file.Write(utf8BOM); err != nil {
+		return fmt.Errorf("error writing BOM to %s: %w", filename, err)
+	}
+
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+
+	if err := writer.Write([]string{"date", "sender", "status", "reason", "body"}); err != nil {
+		return fmt.Errorf("error writing header to %s: %w", filename, err)
+	}
+
+	for _, msg := range messages {
+		date := msg.SMS.Date
+		if t, err := msg.SMS.Time(loc); err == nil {
+			date = t.Format("2006-01-02 15:04:05")
+		}
+
+		status := "unparsed"
+		if msg.Skipped {
+			status = "intentionally skipped"
+		}
+
+		if err := writer.Write([]string{date, msg.SMS.Address, status, msg.Reason, msg.SMS.Body}); err != nil {
+			return fmt.Errorf("error writing message to %s: %w", filename, err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error flushing writer for %s: %w", filename, err)
+	}
+
+	return file.Close()
+}
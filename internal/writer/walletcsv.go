@@ -0,0 +1,107 @@
+package writer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// walletColumns are named as in Wallet's own CSV export, which its importer
+// maps without asking
+var walletColumns = []string{"date", "payee", "note", "amount", "currency", "category", "type", "payment_type", "transfer", "labels"}
+
+// walletCategories renames the categories whose Wallet name differs
+var walletCategories = map[string]string{
+	models.CatGeneral: "Others",
+}
+
+// Wallet record types and payment types
+const (
+	walletExpense = "Expenses"
+	walletIncome  = "Income"
+
+	walletPaymentCreditCard = "CREDIT_CARD"
+	walletPaymentDebitCard  = "DEBIT_CARD"
+	walletPaymentTransfer   = "TRANSFER"
+)
+
+// walletFormatter writes CSV in the layout of Wallet by BudgetBakers' record
+// export and import, semicolon-separated with a BOM unless configured otherwise
+type walletFormatter struct {
+	comma rune
+	bom   bool
+}
+
+// Extension returns the CSV file extension
+func (f *walletFormatter) Extension() string {
+	return "csv"
+}
+
+// Format writes transactions as Wallet records with YYYY-MM-DD HH:MM:SS dates
+func (f *walletFormatter) Format(w io.Writer, transactions []models.Transaction) error {
+	if f.bom {
+		if _, err := w.Write(utf8BOM); err != nil {
+			return fmt.Errorf("error writing BOM: %w", err)
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = f.comma
+
+	if err := writer.Write(walletColumns); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	for _, tx := range transactions {
+		recordType := walletIncome
+		if tx.Amount < 0 {
+			recordType = walletExpense
+		}
+		category := tx.Category
+		if name, ok := walletCategories[category]; ok {
+			category = name
+		}
+
+		record := []string{
+			tx.Timestamp.Format("2006-01-02 15:04:05"),
+			tx.Payee,
+			strings.Join(strings.Fields(tx.Note), " "),
+			utils.FormatAmount(tx.Amount, tx.Currency),
+			tx.Currency,
+			category,
+			recordType,
+			walletPaymentType(tx),
+			strconv.FormatBool(tx.Type == models.TypeTransfer),
+			strings.Join(tx.Tags, ","),
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing transaction: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error flushing writer: %w", err)
+	}
+
+	return nil
+}
+
+// walletPaymentType returns Wallet's payment type for a transaction: credit
+// card for credit card groups, debit card when a card is named, and
+// transfer otherwise
+func walletPaymentType(tx models.Transaction) string {
+	switch {
+	case strings.Contains(tx.TargetGroup, "Credit_Card"):
+		return walletPaymentCreditCard
+	case tx.CardLast4 != "":
+		return walletPaymentDebitCard
+	default:
+		return walletPaymentTransfer
+	}
+}
@@ -0,0 +1,312 @@
+package writer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"sms-parser/internal/models"
+)
+
+// Formatter encodes a group of transactions into a specific file format
+type Formatter interface {
+	// Extension returns the file extension (without the dot) for this format
+	Extension() string
+	// Format writes the transactions to w
+	Format(w io.Writer, transactions []models.Transaction) error
+}
+
+// Sink receives the accounts produced by a run; Writer writes them to
+// per-account files, SQLiteWriter and PostgresWriter to a database, and the
+// FileSinks to a single file
+type Sink interface {
+	Write(accounts []models.Account) error
+}
+
+// FileSink is a Sink that writes every account into one file, such as the
+// XLSX workbook or a journal
+type FileSink interface {
+	Sink
+	// Path returns the file the accounts are written to
+	Path() string
+}
+
+// Options configures how transactions are written
+type Options struct {
+	// Format selects the output format ("csv", "json", "jsonl", "wallet",
+	// "ynab", "homebank", "mmex", "qif", "ofx", or "pdf"); empty means csv
+	Format string
+	// Delimiter is the CSV and wallet field separator; empty means ";"
+	Delimiter string
+	// NoBOM omits the UTF-8 byte order mark from CSV and wallet files
+	NoBOM bool
+	// Append merges new transactions into existing CSV files instead of
+	// overwriting them; only supported for CSV
+	Append bool
+	// OFXVersion selects OFX 2.2 XML ("2") or OFX 1.02 SGML ("1") for ofx
+	// output; empty means 2
+	OFXVersion string
+	// Fields replace or add CSV columns with values rendered from templates;
+	// only supported for CSV
+	Fields Fields
+	// Sort orders the transactions of each file, as "field[:asc|desc]" with
+	// a field from SortFields; empty means date ascending
+	Sort string
+	// Location is the timezone dates are written in, used to read back the
+	// dates of existing files in append mode; nil means the local timezone
+	Location *time.Location
+}
+
+// Writer handles writing transaction files, one per group
+type Writer struct {
+	outputDir string
+	opts      Options
+	formatter Formatter
+	order     sortOrder
+}
+
+// New creates a new Writer instance for the requested output format
+func New(outputDir string, opts Options) (*Writer, error) {
+	formatter, err := newFormatter(opts, false)
+	if err != nil {
+		return nil, err
+	}
+	_, isCSV := formatter.(*csvFormatter)
+	if opts.Append && !isCSV {
+		return nil, fmt.Errorf("appending is only supported for CSV output, not %q", opts.Format)
+	}
+	if len(opts.Fields) > 0 && !isCSV {
+		return nil, fmt.Errorf("custom fields are only supported for CSV output, not %q", opts.Format)
+	}
+	if len(opts.Fields) > 0 && opts.Append {
+		return nil, fmt.Errorf("appending is not supported with custom fields, which may change the columns it matches on")
+	}
+
+	order, err := parseSort(opts.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		outputDir: outputDir,
+		opts:      opts,
+		formatter: formatter,
+		order:     order,
+	}, nil
+}
+
+// newFormatter returns the Formatter for the requested format name. With
+// withAccount, the formatter identifies each transaction's group, for files
+// that mix several accounts.
+func newFormatter(opts Options, withAccount bool) (Formatter, error) {
+	switch opts.Format {
+	case "", "csv":
+		comma, err := parseDelimiter(opts.Delimiter)
+		if err != nil {
+			return nil, err
+		}
+		return &csvFormatter{comma: comma, bom: !opts.NoBOM, withAccount: withAccount, fields: opts.Fields}, nil
+	case "json":
+		return &jsonFormatter{}, nil
+	case "jsonl":
+		return &jsonFormatter{lines: true}, nil
+	case "wallet":
+		if withAccount {
+			return nil, fmt.Errorf("a combined file is not supported for Wallet output, which is imported one account at a time")
+		}
+		comma, err := parseDelimiter(opts.Delimiter)
+		if err != nil {
+			return nil, err
+		}
+		return &walletFormatter{comma: comma, bom: !opts.NoBOM}, nil
+	case "ynab":
+		if withAccount {
+			return nil, fmt.Errorf("a combined file is not supported for YNAB output, which is imported one account at a time")
+		}
+		return &ynabFormatter{}, nil
+	case "homebank":
+		if withAccount {
+			return nil, fmt.Errorf("a combined file is not supported for HomeBank output, which is imported one account at a time")
+		}
+		return &homebankFormatter{}, nil
+	case "mmex":
+		if withAccount {
+			return nil, fmt.Errorf("a combined file is not supported for MMEX output, which is imported one account at a time")
+		}
+		return &mmexFormatter{}, nil
+	case "qif":
+		return &qifFormatter{withAccount: withAccount}, nil
+	case "ofx":
+		switch opts.OFXVersion {
+		case "", "2":
+			return &ofxFormatter{withAccount: withAccount, xml: true}, nil
+		case "1":
+			return &ofxFormatter{withAccount: withAccount}, nil
+		default:
+			return nil, fmt.Errorf("unsupported OFX version %q (use 1 or 2)", opts.OFXVersion)
+		}
+	case "pdf":
+		if withAccount {
+			return nil, fmt.Errorf("a combined file is not supported for PDF output, which has one statement per account")
+		}
+		return &pdfFormatter{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported output format %q (use csv, json, jsonl, wallet, ynab, homebank, mmex, qif, ofx, pdf, xlsx, beancount, ledger, gnucash, sqlite, or postgres)", opts.Format)
+	}
+}
+
+// parseDelimiter validates a CSV delimiter, defaulting to ";"
+func parseDelimiter(delimiter string) (rune, error) {
+	if delimiter == "" {
+		return ';', nil
+	}
+
+	runes := []rune(delimiter)
+	if len(runes) != 1 {
+		return 0, fmt.Errorf("invalid delimiter %q: must be exactly one character", delimiter)
+	}
+
+	switch runes[0] {
+	case '\n', '\r', '"', utf8.RuneError:
+		return 0, fmt.Errorf("invalid delimiter %q: newlines, quotes, and invalid UTF-8 are not allowed", delimiter)
+	}
+
+	return runes[0], nil
+}
+
+// Write writes transactions to files grouped by account, each sorted in the
+// configured order
+func (w *Writer) Write(accounts []models.Account) error {
+	for _, account := range accounts {
+		transactions := account.Transactions
+		if len(transactions) == 0 {
+			continue
+		}
+
+		if err := w.writeOrMerge(w.Filename(account.Name), w.formatter, transactions, w.order.compare); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeOrMerge writes transactions to filename ordered by compare, merging
+// them into the existing file first in append mode
+func (w *Writer) writeOrMerge(filename string, formatter Formatter, transactions []models.Transaction, compare func(a, b models.Transaction) int) error {
+	if !w.opts.Append {
+		slices.SortStableFunc(transactions, compare)
+		if err := writeFile(filename, formatter, transactions); err != nil {
+			return err
+		}
+		fmt.Printf("Created %s with %d transactions.\n", filename, len(transactions))
+		return nil
+	}
+
+	loc := w.opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	merged, mergedFormatter, added, err := mergeCSV(filename, transactions, formatter.(*csvFormatter), loc)
+	if err != nil {
+		return err
+	}
+	slices.SortStableFunc(merged, compare)
+	if err := writeFile(filename, mergedFormatter, merged); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s with %d new transactions (%d in total).\n", filename, added, len(merged))
+	return nil
+}
+
+// WriteCombined writes the transactions of every group into a single file,
+// sorted across groups in the configured order, in the configured format
+// with each transaction's account (group) identified
+func (w *Writer) WriteCombined(name string, accounts []models.Account) error {
+	formatter, err := newFormatter(w.opts, true)
+	if err != nil {
+		return err
+	}
+
+	combined, compare := w.combine(accounts)
+	if len(combined) == 0 {
+		return nil
+	}
+
+	return w.writeOrMerge(w.CombinedFilename(name), formatter, combined, compare)
+}
+
+// WriteStream writes the transactions of every group to out, such as
+// standard output, in the same form as the combined file
+func (w *Writer) WriteStream(out io.Writer, accounts []models.Account) error {
+	formatter, err := newFormatter(w.opts, true)
+	if err != nil {
+		return err
+	}
+
+	combined, compare := w.combine(accounts)
+	slices.SortStableFunc(combined, compare)
+	return formatter.Format(out, combined)
+}
+
+// combine collects the transactions of every group, with the order that
+// sorts them across groups
+func (w *Writer) combine(accounts []models.Account) ([]models.Transaction, func(a, b models.Transaction) int) {
+	var combined []models.Transaction
+	for _, account := range accounts {
+		combined = append(combined, account.Transactions...)
+	}
+
+	// Keep each account's transactions together on ties
+	compare := func(a, b models.Transaction) int {
+		if c := w.order.compare(a, b); c != 0 {
+			return c
+		}
+		return strings.Compare(a.TargetGroup, b.TargetGroup)
+	}
+
+	return combined, compare
+}
+
+// CombinedFilename returns the output path of the combined file; relative
+// names are placed in the output directory
+func (w *Writer) CombinedFilename(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(w.outputDir, name)
+}
+
+// Extension returns the file extension (without the dot) of the format
+func (w *Writer) Extension() string {
+	return w.formatter.Extension()
+}
+
+// Filename returns the output path used for a group
+func (w *Writer) Filename(groupName string) string {
+	return filepath.Join(w.outputDir, groupName+"."+w.formatter.Extension())
+}
+
+// writeFile creates a single output file using formatter
+func writeFile(filename string, formatter Formatter, transactions []models.Transaction) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %w", filename, err)
+	}
+	defer file.Close()
+
+	if err := formatter.Format(file, transactions); err != nil {
+		return fmt.Errorf("error writing %s: %w", filename, err)
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing %s: %w", filename, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+package writer
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// xlsxColumns are the sheet columns, matching the CSV output
+var xlsxColumns = []string{"date", "payee", "amount", "currency", "type", "category", "note", "balance", "original_amount", "original_currency", "reference", "card"}
+
+// xlsxColumnWidths are the column widths in characters, in xlsxColumns order
+var xlsxColumnWidths = []float64{18, 28, 14, 10, 10, 20, 60, 14, 16, 18, 16, 8}
+
+// xlsxAmountColumns maps the amount columns (1-based) to the column holding
+// their currency, so each amount is shown with its currency's decimals
+var xlsxAmountColumns = map[int]int{3: 4, 8: 4, 9: 10}
+
+// maxSheetName is Excel's limit on the length of a sheet name
+const maxSheetName = 31
+
+// XLSXWriter writes all accounts into one Excel workbook with a sheet per
+// group
+type XLSXWriter struct {
+	path  string
+	order sortOrder
+}
+
+// NewXLSXWriter creates an XLSXWriter for the workbook at path; sort orders
+// the rows of each sheet like Options.Sort
+func NewXLSXWriter(path, sort string) (*XLSXWriter, error) {
+	order, err := parseSort(sort)
+	if err != nil {
+		return nil, err
+	}
+
+	return &XLSXWriter{path: path, order: order}, nil
+}
+
+// Path returns the workbook file path
+func (w *XLSXWriter) Path() string {
+	return w.path
+}
+
+// Write creates the workbook with one sheet per non-empty account, each with
+// a bold, frozen, auto-filtered header row and numeric amount columns
+func (w *XLSXWriter) Write(accounts []models.Account) error {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	styles, err := newXLSXStyles(file)
+	if err != nil {
+		return err
+	}
+
+	used := map[string]bool{}
+	count := 0
+	for _, account := range accounts {
+		if len(account.Transactions) == 0 {
+			continue
+		}
+
+		transactions := slices.Clone(account.Transactions)
+		slices.SortStableFunc(transactions, w.order.compare)
+
+		sheet := sheetName(account.Name, used)
+		if count == 0 {
+			// Reuse the default sheet so the workbook has no empty first sheet
+			if err := file.SetSheetName(file.GetSheetName(0), sheet); err != nil {
+				return fmt.Errorf("error naming sheet %s: %w", sheet, err)
+			}
+		} else if _, err := file.NewSheet(sheet); err != nil {
+			return fmt.Errorf("error adding sheet %s: %w", sheet, err)
+		}
+
+		if err := writeSheet(file, sheet, styles, transactions); err != nil {
+			return fmt.Errorf("error writing sheet %s: %w", sheet, err)
+		}
+		count++
+	}
+	if count == 0 {
+		return nil
+	}
+
+	file.SetActiveSheet(0)
+	if err := file.SaveAs(w.path); err != nil {
+		return fmt.Errorf("error writing %s: %w", w.path, err)
+	}
+
+	fmt.Printf("Created %s with %d sheets.\n", w.path, count)
+	return nil
+}
+
+// xlsxStyles holds the style IDs registered in the workbook
+type xlsxStyles struct {
+	header  int
+	date    int
+	amount2 int
+	amount3 int
+}
+
+// newXLSXStyles registers the header, date, and amount styles
+func newXLSXStyles(file *excelize.File) (xlsxStyles, error) {
+	var styles xlsxStyles
+	var err error
+
+	if styles.header, err = file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}}); err != nil {
+		return styles, fmt.Errorf("error creating header style: %w", err)
+	}
+
+	dateFormat := "yyyy-mm-dd hh:mm:ss"
+	if styles.date, err = file.NewStyle(&excelize.Style{CustomNumFmt: &dateFormat}); err != nil {
+		return styles, fmt.Errorf("error creating date style: %w", err)
+	}
+
+	// #,##0.00 with a thousands separator (built-in format 4)
+	if styles.amount2, err = file.NewStyle(&excelize.Style{NumFmt: 4}); err != nil {
+		return styles, fmt.Errorf("error creating amount style: %w", err)
+	}
+
+	amount3Format := "#,##0.000"
+	if styles.amount3, err = file.NewStyle(&excelize.Style{CustomNumFmt: &amount3Format}); err != nil {
+		return styles, fmt.Errorf("error creating amount style: %w", err)
+	}
+
+	return styles, nil
+}
+
+// writeSheet fills a sheet with the header and one row per transaction
+func writeSheet(file *excelize.File, sheet string, styles xlsxStyles, transactions []models.Transaction) error {
+	header := make([]any, len(xlsxColumns))
+	for i, column := range xlsxColumns {
+		header[i] = column
+	}
+	if err := file.SetSheetRow(sheet, "A1", &header); err != nil {
+		return err
+	}
+
+	lastColumn, err := excelize.ColumnNumberToName(len(xlsxColumns))
+	if err != nil {
+		return err
+	}
+	if err := file.SetCellStyle(sheet, "A1", lastColumn+"1", styles.header); err != nil {
+		return err
+	}
+
+	for i, tx := range transactions {
+		row := i + 2
+
+		// A missing balance is left empty, since a zero balance is meaningful
+		var balance any
+		if tx.HasBalance {
+			balance = tx.Balance
+		}
+
+		values := []any{
+			excelTime(tx.Timestamp),
+			tx.Payee,
+			tx.Amount,
+			tx.Currency,
+			tx.Type,
+			tx.Category,
+			tx.Note,
+			balance,
+			tx.OriginalAmount,
+			tx.OriginalCurrency,
+			tx.Reference,
+			tx.CardLast4,
+		}
+		cell, err := excelize.CoordinatesToCellName(1, row)
+		if err != nil {
+			return err
+		}
+		if err := file.SetSheetRow(sheet, cell, &values); err != nil {
+			return err
+		}
+
+		if err := file.SetCellStyle(sheet, cell, cell, styles.date); err != nil {
+			return err
+		}
+		for column, currencyColumn := range xlsxAmountColumns {
+			style := styles.amount2
+			if currency, _ := values[currencyColumn-1].(string); utils.AmountDecimals(currency) == 3 {
+				style = styles.amount3
+			}
+
+			cell, err := excelize.CoordinatesToCellName(column, row)
+			if err != nil {
+				return err
+			}
+			if err := file.SetCellStyle(sheet, cell, cell, style); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, width := range xlsxColumnWidths {
+		column, err := excelize.ColumnNumberToName(i + 1)
+		if err != nil {
+			return err
+		}
+		if err := file.SetColWidth(sheet, column, column, width); err != nil {
+			return err
+		}
+	}
+
+	lastCell := fmt.Sprintf("%s%d", lastColumn, len(transactions)+1)
+	if err := file.AutoFilter(sheet, "A1:"+lastCell, nil); err != nil {
+		return err
+	}
+
+	return file.SetPanes(sheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"})
+}
+
+// excelTime keeps the wall-clock time of t, since Excel dates have no time
+// zone and excelize would otherwise convert them to UTC
+func excelTime(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+}
+
+// sheetName turns a group name into a valid, unique Excel sheet name:
+// characters Excel forbids are replaced and the name is cut to 31 characters
+func sheetName(group string, used map[string]bool) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`:\/?*[]`, r) {
+			return '_'
+		}
+		return r
+	}, group)
+	name = strings.Trim(name, "'")
+	if name == "" {
+		name = "Sheet"
+	}
+
+	base := truncateRunes(name, maxSheetName)
+	name = base
+	for i := 2; used[strings.ToLower(name)]; i++ {
+		suffix := fmt.Sprintf("_%d", i)
+		name = truncateRunes(base, maxSheetName-len(suffix)) + suffix
+	}
+	used[strings.ToLower(name)] = true
+
+	return name
+}
+
+// truncateRunes cuts s to at most n runes
+func truncateRunes(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
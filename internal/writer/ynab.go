@@ -0,0 +1,61 @@
+package writer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"sms-parser/internal/models"
+	"sms-parser/internal/utils"
+)
+
+// ynabColumns are the columns YNAB's file importer maps without asking
+var ynabColumns = []string{"Date", "Payee", "Memo", "Outflow", "Inflow"}
+
+// ynabFormatter writes comma-separated CSV in YNAB's import layout, with the
+// amount split into Outflow and Inflow columns
+type ynabFormatter struct{}
+
+// Extension returns the CSV file extension
+func (f *ynabFormatter) Extension() string {
+	return "csv"
+}
+
+// Format writes transactions as YNAB rows with MM/DD/YYYY dates
+func (f *ynabFormatter) Format(w io.Writer, transactions []models.Transaction) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(ynabColumns); err != nil {
+		return fmt.Errorf("error writing header: %w", err)
+	}
+
+	for _, tx := range transactions {
+		outflow, inflow := "", ""
+		amount := utils.FormatAmount(math.Abs(tx.Amount), tx.Currency)
+		if tx.Amount < 0 {
+			outflow = amount
+		} else {
+			inflow = amount
+		}
+
+		record := []string{
+			tx.Timestamp.Format("01/02/2006"),
+			tx.Payee,
+			strings.Join(strings.Fields(tx.Note), " "),
+			outflow,
+			inflow,
+		}
+		if err := writer.Write(record); err != nil {
+			return fmt.Errorf("error writing transaction: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("error flushing writer: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package ynab
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"sms-parser/internal/models"
+)
+
+// DefaultBaseURL is the YNAB API endpoint
+const DefaultBaseURL = "https://api.ynab.com/v1"
+
+// importIDPrefix starts every import ID, keeping them apart from the
+// "YNAB:" IDs of YNAB's own file and bank imports
+const importIDPrefix = "SMS:"
+
+// maxMemo and maxPayee are YNAB's field length limits
+const (
+	maxMemo  = 200
+	maxPayee = 200
+)
+
+// Transaction is a transaction in the shape of the YNAB API's SaveTransaction
+type Transaction struct {
+	AccountID string `json:"account_id"`
+	Date      string `json:"date"`
+	// Amount is in milliunits: 1000 for 1.00
+	Amount    int64  `json:"amount"`
+	PayeeName string `json:"payee_name,omitempty"`
+	Memo      string `json:"memo,omitempty"`
+	Cleared   string `json:"cleared"`
+	Approved  bool   `json:"approved"`
+	ImportID  string `json:"import_id"`
+}
+
+// Result summarizes a push: YNAB skips transactions whose import ID it has
+// already seen in the account and reports them as duplicates
+type Result struct {
+	Created    int
+	Duplicates int
+}
+
+// Client calls the YNAB API with a personal access token
+type Client struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// New creates a Client for the YNAB API at baseURL (DefaultBaseURL when empty)
+func New(token, baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	return &Client{
+		token:      token,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// NewTransaction converts a parsed transaction for the YNAB account
+// accountID. The import ID is derived from the transaction's stable ID, so
+// pushing the same messages again creates no duplicates.
+func NewTransaction(tx models.Transaction, accountID string) Transaction {
+	return Transaction{
+		AccountID: accountID,
+		Date:      tx.Timestamp.Format("2006-01-02"),
+		Amount:    int64(math.Round(tx.Amount * 1000)),
+		PayeeName: truncate(tx.Payee, maxPayee),
+		Memo:      truncate(strings.Join(strings.Fields(tx.Note), " "), maxMemo),
+		Cleared:   "cleared",
+		ImportID:  importIDPrefix + tx.ID,
+	}
+}
+
+// CreateTransactions creates transactions in the budget in one request
+func (c *Client) CreateTransactions(ctx context.Context, budgetID string, transactions []Transaction) (Result, error) {
+	body, err := json.Marshal(map[string]any{"transactions": transactions})
+	if err != nil {
+		return Result{}, fmt.Errorf("error encoding transactions: %w", err)
+	}
+
+	endpoint := fmt.Sprintf("%s/budgets/%s/transactions", c.baseURL, url.PathEscape(budgetID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Result{}, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return Result{}, fmt.Errorf("error calling YNAB: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return Result{}, fmt.Errorf("error reading YNAB response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error struct {
+				Name   string `json:"name"`
+				Detail string `json:"detail"`
+			} `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Name != "" {
+			return Result{}, fmt.Errorf("YNAB rejected the transactions (%d %s): %s", resp.StatusCode, apiErr.Error.Name, apiErr.Error.Detail)
+		}
+		return Result{}, fmt.Errorf("YNAB rejected the transactions: %s", resp.Status)
+	}
+
+	var created struct {
+		Data struct {
+			TransactionIDs     []string `json:"transaction_ids"`
+			DuplicateImportIDs []string `json:"duplicate_import_ids"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &created); err != nil {
+		return Result{}, fmt.Errorf("error decoding YNAB response: %w", err)
+	}
+
+	return Result{
+		Created:    len(created.Data.TransactionIDs),
+		Duplicates: len(created.Data.DuplicateImportIDs),
+	}, nil
+}
+
+// truncate cuts s to at most n runes
+func truncate(s string, n int) string {
+	if runes := []rune(s); len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
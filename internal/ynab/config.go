@@ -0,0 +1,39 @@
+package ynab
+
+import (
+	"fmt"
+
+	"sms-parser/internal/utils"
+)
+
+// Config names the budget transactions are pushed to and the YNAB account
+// each group goes into
+type Config struct {
+	// BudgetID is the budget's ID, as in the budget's web address, or "last-used"
+	BudgetID string `json:"budget_id" yaml:"budget_id"`
+	// Accounts maps a group (e.g. "CIB_Current_Debit") to a YNAB account ID;
+	// groups without an entry are not pushed
+	Accounts map[string]string `json:"accounts" yaml:"accounts"`
+}
+
+// LoadConfig reads a Config from a YAML or JSON file
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	if err := utils.DecodeFile(path, &cfg); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.BudgetID == "" {
+		return Config{}, fmt.Errorf("missing budget_id in %s", path)
+	}
+	if len(cfg.Accounts) == 0 {
+		return Config{}, fmt.Errorf("no accounts in %s; map each group to push to a YNAB account ID", path)
+	}
+	for group, accountID := range cfg.Accounts {
+		if accountID == "" {
+			return Config{}, fmt.Errorf("empty account ID for %q in %s", group, path)
+		}
+	}
+
+	return cfg, nil
+}
@@ -1,15 +1,23 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
+	// Embed the timezone database so --timezone works without system tzdata
+	_ "time/tzdata"
+
 	"sms-parser/cmd"
 )
 
 func main() {
 	if err := cmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		// Exit code 2 tells "nothing found" apart from hard errors
+		if errors.Is(err, cmd.ErrNoTransactions) {
+			os.Exit(2)
+		}
 		os.Exit(1)
 	}
 }
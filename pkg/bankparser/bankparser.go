@@ -0,0 +1,33 @@
+// Package bankparser is the public interface for bank parsers built outside
+// this repository and loaded at runtime with --plugin.
+package bankparser
+
+import (
+	"errors"
+
+	"sms-parser/internal/models"
+)
+
+// Transaction is the transaction a parser fills in. It arrives pre-filled with
+// the message date, EGP currency, and expense type, like for built-in parsers.
+type Transaction = models.Transaction
+
+// Transaction types, for parsers that set Transaction.Type
+const (
+	TypeExpense = models.TypeExpense
+	TypeIncome  = models.TypeIncome
+)
+
+// ErrSkipped is returned for messages that are intentionally not
+// transactions, such as OTPs; they are reported as skipped, not unparsed
+var ErrSkipped = errors.New("skipped by plugin")
+
+// BankParser parses the messages of one bank
+type BankParser interface {
+	// Senders lists the SMS sender addresses the parser handles
+	Senders() []string
+	// Parse turns a message body into transactions. tx must get a
+	// TargetGroup and a signed Amount (negative for expenses); returning
+	// it unchanged reports the message as unparsed.
+	Parse(tx Transaction, body string) ([]Transaction, error)
+}
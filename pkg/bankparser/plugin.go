@@ -0,0 +1,43 @@
+package bankparser
+
+import (
+	"fmt"
+	"path/filepath"
+	"plugin"
+	"strings"
+)
+
+// Symbol is the name of the variable a plugin exports its parser as:
+//
+//	var Parser bankparser.BankParser = myBank{}
+const Symbol = "Parser"
+
+// Open loads a Go plugin (built with -buildmode=plugin against the same
+// version of this module) and returns the BankParser it exports as Symbol
+func Open(path string) (BankParser, error) {
+	if strings.EqualFold(filepath.Ext(path), ".wasm") {
+		return nil, fmt.Errorf("plugin %s: WASM modules are not supported; build a Go plugin (.so) instead", path)
+	}
+
+	p, err := plugin.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", path, err)
+	}
+	symbol, err := p.Lookup(Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", path, err)
+	}
+
+	// Lookup returns a pointer to an exported variable
+	switch parser := symbol.(type) {
+	case *BankParser:
+		if *parser == nil {
+			return nil, fmt.Errorf("plugin %s: %s is nil", path, Symbol)
+		}
+		return *parser, nil
+	case BankParser:
+		return parser, nil
+	default:
+		return nil, fmt.Errorf("plugin %s: %s is a %T, not a bankparser.BankParser", path, Symbol, symbol)
+	}
+}